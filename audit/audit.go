@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Phases of an action. Every action is recorded as an intent before its API
+// call is made and as an outcome once it returns, so an intent without an
+// outcome is a call that may or may not have happened, e.g. because ecstui
+// was killed in the middle of it.
+const (
+	Intent  = "intent"
+	Outcome = "outcome"
+)
+
+// Entry is one phase of a write action.
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	Phase     string          `json:"phase"`
+	Cluster   string          `json:"cluster"`
+	Service   string          `json:"service"`
+	Operation string          `json:"operation"`
+	Input     json.RawMessage `json:"input"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Input encodes the input of an API call for an entry, leaving out the
+// fields that are not set.
+func Input(input interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(withoutNulls(value))
+}
+
+func withoutNulls(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if v == nil {
+				delete(value, k)
+				continue
+			}
+			value[k] = withoutNulls(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = withoutNulls(v)
+		}
+	}
+	return value
+}
+
+// Log appends entries as JSON lines to a local file.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns the audit log location next to the config file, e.g.
+// ~/.config/ecstui/audit.log on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ecstui", "audit.log")
+}
+
+// Open creates the audit log at path if it does not exist yet, so an
+// unwritable location is reported before any action runs.
+func Open(path string) (*Log, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no audit log path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{path: path}, f.Close()
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends entry to the log.
+func (l *Log) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
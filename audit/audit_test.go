@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// readEntries parses the JSON lines of the log at path.
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Open did not create the log only readable by the user: %v %v", info, err)
+	}
+
+	at := time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC)
+	input := json.RawMessage(`{"cluster":"demo"}`)
+	entries := []Entry{
+		{Time: at, Phase: Intent, Cluster: "demo", Service: "web", Operation: "ecs:UpdateService", Input: input},
+		{Time: at.Add(time.Second), Phase: Outcome, Cluster: "demo", Service: "web", Operation: "ecs:UpdateService", Input: input, Error: "AccessDeniedException"},
+	}
+	for _, entry := range entries {
+		if err := log.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a reopened log is appended to
+	log, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third := Entry{Time: at.Add(2 * time.Second), Phase: Intent, Cluster: "demo", Service: "web", Operation: "ecs:StopTask", Input: input}
+	if err := log.Record(third); err != nil {
+		t.Fatal(err)
+	}
+	entries = append(entries, third)
+
+	got := readEntries(t, path)
+	if len(got) != len(entries) {
+		t.Fatalf("%d entries in the log, want %d", len(got), len(entries))
+	}
+	for i, want := range entries {
+		gotJSON, _ := json.Marshal(got[i])
+		wantJSON, _ := json.Marshal(want)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("entry %d = %s, want %s", i, gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestRecordLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = log.Record(Entry{
+		Time:      time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC),
+		Phase:     Outcome,
+		Cluster:   "demo",
+		Service:   "web",
+		Operation: "ecs:UpdateService",
+		Input:     json.RawMessage(`{"cluster":"demo"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a successful call has no error field
+	want := `{"time":"2024-03-14T09:00:00Z","phase":"outcome","cluster":"demo","service":"web","operation":"ecs:UpdateService","input":{"cluster":"demo"}}` + "\n"
+	if string(data) != want {
+		t.Errorf("log = %s, want %s", data, want)
+	}
+}
+
+func TestInput(t *testing.T) {
+	input, err := Input(&ecs.UpdateServiceInput{
+		Cluster:      aws.String("demo"),
+		Service:      aws.String("web"),
+		DesiredCount: aws.Int64(3),
+		PlacementConstraints: []*ecs.PlacementConstraint{
+			{Type: aws.String("distinctInstance")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Cluster":"demo","DesiredCount":3,"PlacementConstraints":[{"Type":"distinctInstance"}],"Service":"web"}`
+	if string(input) != want {
+		t.Errorf("Input = %s, want %s", input, want)
+	}
+}
+
+func TestOpenWithoutPath(t *testing.T) {
+	if _, err := Open(""); err == nil {
+		t.Error("Open succeeded without a path")
+	}
+}
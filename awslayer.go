@@ -1,15 +1,32 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	autoscaling "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/record"
+	"github.com/mtyurt/ecstui/tui/actions"
 	"github.com/mtyurt/ecstui/types"
 	"github.com/mtyurt/ecstui/utils"
 )
@@ -18,38 +35,112 @@ type AWSInteractionLayer struct {
 	sess  *session.Session
 	ecs   *ecs.ECS
 	asg   *autoscaling.ApplicationAutoScaling
+	cw    *cloudwatch.CloudWatch
 	elbv2 *elbv2.ELBV2
+	ec2   *ec2.EC2
+	sd    *servicediscovery.ServiceDiscovery
+
+	// task definition revisions are immutable, so they are cached by ARN
+	taskDefMu    sync.Mutex
+	taskDefCache map[string]*ecs.TaskDefinition
+
+	// images can live in registries of other regions, one client per region
+	ecrMu      sync.Mutex
+	ecrClients map[string]*ecr.ECR
+}
+
+// retryer backs off with jitter on throttling errors, which are common when
+// a cluster has many task sets and every one of them triggers a handful of
+// ELB calls on each refresh.
+var retryer = client.DefaultRetryer{
+	NumMaxRetries:    8,
+	MinRetryDelay:    100 * time.Millisecond,
+	MaxRetryDelay:    5 * time.Second,
+	MinThrottleDelay: 500 * time.Millisecond,
+	MaxThrottleDelay: 20 * time.Second,
 }
 
-func NewAWSInteractionLayer() *AWSInteractionLayer {
-	sess := session.Must(session.NewSession())
+// replayRegion is used when a recording does not say which region it was
+// made in, clients refuse to be built without one.
+const replayRegion = "us-east-1"
+
+// NewSession creates the session the layer's clients are built from. If
+// endpoint is set every client calls it instead of AWS, e.g. the mock server.
+// Calls are appended to recorder if it is set. If replayer is set they are
+// served from its recording instead, without credentials or network.
+func NewSession(endpoint string, recorder *record.Recorder, replayer *record.Replayer) (*session.Session, error) {
+	config := request.WithRetryer(aws.NewConfig(), retryer)
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint)
+	}
+	if replayer != nil {
+		region := replayer.Region()
+		if region == "" {
+			region = replayRegion
+		}
+		config = config.WithCredentials(credentials.AnonymousCredentials).WithRegion(region)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	if recorder != nil {
+		recorder.Attach(&sess.Handlers)
+	}
+	if replayer != nil {
+		replayer.Attach(&sess.Handlers)
+	}
+	return sess, nil
+}
 
+func NewAWSInteractionLayer(sess *session.Session) *AWSInteractionLayer {
 	return &AWSInteractionLayer{
-		sess:  sess,
-		ecs:   ecs.New(sess),
-		asg:   autoscaling.New(sess),
-		elbv2: elbv2.New(sess),
+		sess:         sess,
+		ecs:          ecs.New(sess),
+		asg:          autoscaling.New(sess),
+		cw:           cloudwatch.New(sess),
+		ec2:          ec2.New(sess),
+		sd:           servicediscovery.New(sess),
+		elbv2:        elbv2.New(sess),
+		taskDefCache: make(map[string]*ecs.TaskDefinition),
+		ecrClients:   make(map[string]*ecr.ECR),
 	}
 }
 
+// ReloadCredentials expires the session's cached credentials and resolves
+// them again, so a fresh `aws sso login` is picked up without a restart.
+func (a *AWSInteractionLayer) ReloadCredentials() error {
+	a.sess.Config.Credentials.Expire()
+	_, err := a.sess.Config.Credentials.Get()
+	return err
+}
+
 func (a *AWSInteractionLayer) ListClusters() ([]*string, error) {
-	result, err := a.ecs.ListClusters(&ecs.ListClustersInput{})
+	var arns []*string
+	err := a.ecs.ListClustersPages(&ecs.ListClustersInput{}, func(page *ecs.ListClustersOutput, lastPage bool) bool {
+		arns = append(arns, page.ClusterArns...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return result.ClusterArns, nil
+	return arns, nil
 }
 
 func (a *AWSInteractionLayer) ListServices(cluster string) ([]*string, error) {
-	result, err := a.ecs.ListServices(&ecs.ListServicesInput{
+	var arns []*string
+	err := a.ecs.ListServicesPages(&ecs.ListServicesInput{
 		Cluster: aws.String(cluster),
+	}, func(page *ecs.ListServicesOutput, lastPage bool) bool {
+		arns = append(arns, page.ServiceArns...)
+		return true
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return result.ServiceArns, nil
+	return arns, nil
 }
 
 type ECSService struct {
@@ -83,19 +174,67 @@ func (a *AWSInteractionLayer) FetchServiceList() ([]ECSService, error) {
 	return itemList, nil
 }
 
-func (a *AWSInteractionLayer) GetImagesInTaskDefinition(taskDefinitionArn string) ([]string, error) {
-	taskDefinition, err := a.ecs.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+func (a *AWSInteractionLayer) FetchTaskDefinition(taskDefinitionArn string) (*ecs.TaskDefinition, error) {
+	a.taskDefMu.Lock()
+	taskDefinition, ok := a.taskDefCache[taskDefinitionArn]
+	a.taskDefMu.Unlock()
+	if ok {
+		return taskDefinition, nil
+	}
+
+	resp, err := a.ecs.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
 		TaskDefinition: aws.String(taskDefinitionArn),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe task definition: %v", err)
+		return nil, fmt.Errorf("failed to describe task definition: %w", err)
+	}
+
+	a.taskDefMu.Lock()
+	a.taskDefCache[taskDefinitionArn] = resp.TaskDefinition
+	a.taskDefMu.Unlock()
+	return resp.TaskDefinition, nil
+}
+
+// ListTaskDefinitionRevisions returns the ARNs of every ACTIVE and INACTIVE
+// revision of the family, newest first.
+func (a *AWSInteractionLayer) ListTaskDefinitionRevisions(family string) ([]string, error) {
+	var arns []string
+	for _, status := range []string{ecs.TaskDefinitionStatusActive, ecs.TaskDefinitionStatusInactive} {
+		err := a.ecs.ListTaskDefinitionsPages(&ecs.ListTaskDefinitionsInput{
+			FamilyPrefix: aws.String(family),
+			Status:       aws.String(status),
+			Sort:         aws.String(ecs.SortOrderDesc),
+		}, func(page *ecs.ListTaskDefinitionsOutput, lastPage bool) bool {
+			for _, arn := range page.TaskDefinitionArns {
+				// the prefix also matches families like "<family>-worker"
+				if f, _ := utils.SplitTaskDefinitionArn(*arn); f == family {
+					arns = append(arns, *arn)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s task definitions: %w", strings.ToLower(status), err)
+		}
+	}
+
+	slices.SortFunc(arns, func(i, j string) int {
+		_, ri := utils.SplitTaskDefinitionArn(i)
+		_, rj := utils.SplitTaskDefinitionArn(j)
+		return int(rj - ri)
+	})
+	return arns, nil
+}
+
+func (a *AWSInteractionLayer) GetImagesInTaskDefinition(taskDefinitionArn string) ([]string, error) {
+	taskDefinition, err := a.FetchTaskDefinition(taskDefinitionArn)
+	if err != nil {
+		return nil, err
 	}
 
 	var images []string
-	for _, container := range taskDefinition.TaskDefinition.ContainerDefinitions {
-		image := utils.GetLastItemAfterSplit(*container.Image, "amazonaws.com/")
-		image = strings.Replace(image, "public.ecr.aws/", "", 1)
-		if image != "" {
+	for _, container := range taskDefinition.ContainerDefinitions {
+		if image := aws.StringValue(container.Image); image != "" {
 			images = append(images, image)
 		}
 	}
@@ -103,6 +242,267 @@ func (a *AWSInteractionLayer) GetImagesInTaskDefinition(taskDefinitionArn string
 	return images, nil
 }
 
+func (a *AWSInteractionLayer) ecrClient(region string) *ecr.ECR {
+	a.ecrMu.Lock()
+	defer a.ecrMu.Unlock()
+	if client, ok := a.ecrClients[region]; ok {
+		return client
+	}
+	client := ecr.New(a.sess, aws.NewConfig().WithRegion(region))
+	a.ecrClients[region] = client
+	return client
+}
+
+// FetchImageDetails returns the full image reference of every container in
+// the task definition, the digests the given tasks resolved them to and, for
+// ECR images, push time, size and scan findings of those digests.
+func (a *AWSInteractionLayer) FetchImageDetails(taskDefinitionArn string, tasks []*ecs.Task) ([]types.ImageDetails, error) {
+	taskDefinition, err := a.FetchTaskDefinition(taskDefinitionArn)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := utils.DigestsByContainer(tasks)
+	var details []types.ImageDetails
+	for _, container := range taskDefinition.ContainerDefinitions {
+		name := aws.StringValue(container.Name)
+		image := aws.StringValue(container.Image)
+		detail := types.ImageDetails{
+			Container: name,
+			Image:     image,
+			Digests:   digests[name],
+		}
+		if ref, ok := utils.ParseECRImage(image); ok {
+			detail.ECR, detail.ECRErr = a.describeECRImages(ref, detail.Digests)
+			if detail.ECRErr != nil {
+				logger.Printf("failed to describe ecr image %s: %v\n", image, detail.ECRErr)
+			}
+		}
+		details = append(details, detail)
+	}
+	return details, nil
+}
+
+func (a *AWSInteractionLayer) describeECRImages(ref utils.ECRImage, digests map[string][]string) ([]types.ECRImageDetails, error) {
+	client := a.ecrClient(ref.Region)
+	imageIds := []*ecr.ImageIdentifier{}
+	for digest := range digests {
+		imageIds = append(imageIds, &ecr.ImageIdentifier{ImageDigest: aws.String(digest)})
+	}
+	if len(imageIds) == 0 { // no task reported a digest yet, look up what the reference points to
+		if ref.Digest != "" {
+			imageIds = append(imageIds, &ecr.ImageIdentifier{ImageDigest: aws.String(ref.Digest)})
+		} else if ref.Tag != "" {
+			imageIds = append(imageIds, &ecr.ImageIdentifier{ImageTag: aws.String(ref.Tag)})
+		} else {
+			imageIds = append(imageIds, &ecr.ImageIdentifier{ImageTag: aws.String("latest")})
+		}
+	}
+
+	var errs []error
+	var images []types.ECRImageDetails
+	found, err := describeImages(client, ref, imageIds)
+	if isImageNotFound(err) {
+		// one image that is gone, e.g. expired by a lifecycle policy, fails
+		// the whole call: look them up one by one to find out which
+		found, err = nil, nil
+		for _, id := range imageIds {
+			image, err := describeImages(client, ref, []*ecr.ImageIdentifier{id})
+			switch {
+			case isImageNotFound(err) && id.ImageDigest != nil:
+				images = append(images, types.ECRImageDetails{Digest: aws.StringValue(id.ImageDigest), Missing: true})
+			case err != nil:
+				errs = append(errs, err)
+			default:
+				found = append(found, image...)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range found {
+		details := types.ECRImageDetails{
+			Digest:    aws.StringValue(image.ImageDigest),
+			Tags:      aws.StringValueSlice(image.ImageTags),
+			PushedAt:  aws.TimeValue(image.ImagePushedAt),
+			SizeBytes: aws.Int64Value(image.ImageSizeInBytes),
+		}
+		if image.ImageScanStatus != nil {
+			details.ScanStatus = aws.StringValue(image.ImageScanStatus.Status)
+		}
+		if image.ImageScanFindingsSummary != nil {
+			details.Findings = aws.Int64ValueMap(image.ImageScanFindingsSummary.FindingSeverityCounts)
+		} else if err := a.describeImageScanFindings(client, ref, &details); err != nil {
+			errs = append(errs, err)
+		}
+		images = append(images, details)
+	}
+	return images, errors.Join(errs...)
+}
+
+func describeImages(client *ecr.ECR, ref utils.ECRImage, imageIds []*ecr.ImageIdentifier) ([]*ecr.ImageDetail, error) {
+	resp, err := client.DescribeImages(&ecr.DescribeImagesInput{
+		RegistryId:     aws.String(ref.RegistryID),
+		RepositoryName: aws.String(ref.Repository),
+		ImageIds:       imageIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.ImageDetails, nil
+}
+
+func isImageNotFound(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == ecr.ErrCodeImageNotFoundException
+}
+
+// describeImageScanFindings fills in the scan status and severity counts for
+// images whose DescribeImages response carried no findings summary.
+func (a *AWSInteractionLayer) describeImageScanFindings(client *ecr.ECR, ref utils.ECRImage, details *types.ECRImageDetails) error {
+	resp, err := client.DescribeImageScanFindings(&ecr.DescribeImageScanFindingsInput{
+		RegistryId:     aws.String(ref.RegistryID),
+		RepositoryName: aws.String(ref.Repository),
+		ImageId:        &ecr.ImageIdentifier{ImageDigest: aws.String(details.Digest)},
+		MaxResults:     aws.Int64(1),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == ecr.ErrCodeScanNotFoundException {
+		details.ScanStatus = "NOT_SCANNED"
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("scan findings of %s: %w", details.Digest, err)
+	}
+	if resp.ImageScanStatus != nil {
+		details.ScanStatus = aws.StringValue(resp.ImageScanStatus.Status)
+	}
+	if resp.ImageScanFindings != nil {
+		details.Findings = aws.Int64ValueMap(resp.ImageScanFindings.FindingSeverityCounts)
+	}
+	return nil
+}
+
+// scalingActivityLimit is how many of the most recent scaling activities are
+// shown, older ones rarely explain the current desired count.
+const scalingActivityLimit = 20
+
+// FetchScalingDetails describes the scalable target of the service with its
+// policies, scheduled actions, recent activities and the alarms behind the
+// policies. A failing section is recorded in Errors and does not abort the
+// others.
+func (a *AWSInteractionLayer) FetchScalingDetails(cluster, service string) (*types.ScalingDetails, error) {
+	resourceID := fmt.Sprintf("service/%s/%s", cluster, service)
+	details := &types.ScalingDetails{Alarms: make(map[string]*cloudwatch.MetricAlarm)}
+	addErr := func(section string, err error) {
+		logger.Printf("failed to describe %s of %s: %v\n", section, resourceID, err)
+		details.Errors = append(details.Errors, types.SectionError{Section: section, Err: err})
+	}
+
+	targets, err := a.asg.DescribeScalableTargets(&autoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace: aws.String(autoscaling.ServiceNamespaceEcs),
+		ResourceIds:      []*string{&resourceID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(targets.ScalableTargets) == 0 {
+		return details, nil
+	}
+	details.Target = targets.ScalableTargets[0]
+
+	err = a.asg.DescribeScalingPoliciesPages(&autoscaling.DescribeScalingPoliciesInput{
+		ServiceNamespace:  aws.String(autoscaling.ServiceNamespaceEcs),
+		ResourceId:        &resourceID,
+		ScalableDimension: details.Target.ScalableDimension,
+	}, func(page *autoscaling.DescribeScalingPoliciesOutput, lastPage bool) bool {
+		details.Policies = append(details.Policies, page.ScalingPolicies...)
+		return true
+	})
+	if err != nil {
+		addErr(types.SectionPolicies, err)
+	}
+
+	err = a.asg.DescribeScheduledActionsPages(&autoscaling.DescribeScheduledActionsInput{
+		ServiceNamespace:  aws.String(autoscaling.ServiceNamespaceEcs),
+		ResourceId:        &resourceID,
+		ScalableDimension: details.Target.ScalableDimension,
+	}, func(page *autoscaling.DescribeScheduledActionsOutput, lastPage bool) bool {
+		details.ScheduledActions = append(details.ScheduledActions, page.ScheduledActions...)
+		return true
+	})
+	if err != nil {
+		addErr(types.SectionScheduledActions, err)
+	}
+
+	activities, err := a.asg.DescribeScalingActivities(&autoscaling.DescribeScalingActivitiesInput{
+		ServiceNamespace:  aws.String(autoscaling.ServiceNamespaceEcs),
+		ResourceId:        &resourceID,
+		ScalableDimension: details.Target.ScalableDimension,
+		MaxResults:        aws.Int64(scalingActivityLimit),
+	})
+	if err != nil {
+		addErr(types.SectionActivities, err)
+	} else {
+		details.Activities = activities.ScalingActivities
+	}
+
+	alarmNames := []*string{}
+	for _, policy := range details.Policies {
+		for _, alarm := range policy.Alarms {
+			alarmNames = append(alarmNames, alarm.AlarmName)
+		}
+	}
+	// DescribeAlarms accepts at most 100 names per call
+	for start := 0; start < len(alarmNames); start += 100 {
+		input := &cloudwatch.DescribeAlarmsInput{AlarmNames: alarmNames[start:min(start+100, len(alarmNames))]}
+		err = a.cw.DescribeAlarmsPages(input, func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+			for _, alarm := range page.MetricAlarms {
+				details.Alarms[aws.StringValue(alarm.AlarmName)] = alarm
+			}
+			return true
+		})
+		if err != nil {
+			addErr(types.SectionAlarms, err)
+			break
+		}
+	}
+	return details, nil
+}
+
+// FetchPlacementDetails loads the cluster's capacity providers and the
+// running tasks of the service, to compare where tasks run with where the
+// capacity provider strategy places them.
+func (a *AWSInteractionLayer) FetchPlacementDetails(cluster, service string) (*types.PlacementDetails, error) {
+	details := &types.PlacementDetails{}
+	addErr := func(section string, err error) {
+		logger.Printf("failed to describe %s of %s/%s: %v\n", section, cluster, service, err)
+		details.Errors = append(details.Errors, types.SectionError{Section: section, Err: err})
+	}
+
+	clusters, err := a.ecs.DescribeClusters(&ecs.DescribeClustersInput{Clusters: []*string{aws.String(cluster)}})
+	if err != nil {
+		addErr(types.SectionCluster, err)
+	} else if len(clusters.Clusters) > 0 {
+		details.Cluster = clusters.Clusters[0]
+		if len(details.Cluster.CapacityProviders) > 0 {
+			providers, err := a.ecs.DescribeCapacityProviders(&ecs.DescribeCapacityProvidersInput{CapacityProviders: details.Cluster.CapacityProviders})
+			if err != nil {
+				addErr(types.SectionCapacityProviders, err)
+			} else {
+				details.CapacityProviders = providers.CapacityProviders
+			}
+		}
+	}
+
+	details.Tasks, err = a.findTasksForService(cluster, service)
+	if err != nil {
+		addErr(types.SectionTasks, err)
+	}
+	return details, nil
+}
+
 func (a *AWSInteractionLayer) FetchServiceStatus(cluster, service string) (*types.ServiceStatus, error) {
 	input := &ecs.DescribeServicesInput{
 		Cluster:  aws.String(cluster),
@@ -152,160 +552,459 @@ func (a *AWSInteractionLayer) FetchDeploymentsStatus(cluster, service string, de
 	response := &types.DeploymentStatus{}
 	response.DeploymentImages = make(map[string][]string)
 	response.DeploymentTasks = make(map[string][]*ecs.Task)
+	response.InstanceIDs = make(map[string]string)
+	response.Errors = make(types.SectionErrors)
 
-	var err error
 	if len(deployments) > 0 {
 		for _, d := range deployments {
 			if d.TaskDefinition != nil {
-				response.DeploymentImages[*d.Id], err = a.GetImagesInTaskDefinition(*d.TaskDefinition)
+				images, err := a.GetImagesInTaskDefinition(*d.TaskDefinition)
 				if err != nil {
 					logger.Printf("failed to get images in task definition: %v\n", err)
-					return nil, err
+					response.Errors.Add(*d.Id, types.SectionImages, err)
+				} else {
+					response.DeploymentImages[*d.Id] = images
 				}
 			}
 
 			tasks, err := a.findTasksForTaskSet(cluster, service, *d.Id)
 			if err != nil {
 				logger.Printf("failed to find tasks for deployment[%s]: %v\n", *d.Id, err)
-				return nil, err
+				response.Errors.Add(*d.Id, types.SectionTasks, err)
+				continue
 			}
 			response.DeploymentTasks[*d.Id] = tasks
+			if err := a.findInstanceIDs(cluster, tasks, response.InstanceIDs); err != nil {
+				logger.Printf("failed to find container instances for deployment[%s]: %v\n", *d.Id, err)
+				response.Errors.Add(*d.Id, types.SectionInstances, err)
+			}
 		}
 	}
 	if len(loadBalancers) > 0 {
 		lbConfigs := make([]types.ConnectionConfig, 0)
+		var errs []error
 		for _, lb := range loadBalancers {
 			lbConfig, err := a.findLoadBalancersForTargetGroup(*lb.TargetGroupArn)
 			if err != nil {
 				logger.Printf("failed to find load balancers for target group: %v\n", err)
-				return nil, err
+				errs = append(errs, err)
 			}
 			lbConfigs = append(lbConfigs, lbConfig...)
 		}
 		response.DeploymentConnections = lbConfigs
+		response.ConnectionsErr = errors.Join(errs...)
 	}
 	return response, nil
 }
+
+// FetchTaskSetStatus loads images, load balancer connections and tasks of
+// every task set. A failure in one section does not abort the others; it is
+// recorded in the response's Errors so the view can keep showing what loaded.
 func (a *AWSInteractionLayer) FetchTaskSetStatus(cluster, service string, taskSets []*ecs.TaskSet) (*types.TaskSetStatus, error) {
 	response := &types.TaskSetStatus{}
 	response.TaskSetImages = make(map[string][]string)
 	response.TaskSetConnections = make(map[string][]types.ConnectionConfig)
 	response.TaskSetTasks = make(map[string][]*ecs.Task)
-	var err error
+	response.InstanceIDs = make(map[string]string)
+	response.Errors = make(types.SectionErrors)
 	if len(taskSets) > 0 {
 		for _, ts := range taskSets {
 			if ts.LoadBalancers != nil && len(ts.LoadBalancers) > 0 {
 				lbConfigs := make([]types.ConnectionConfig, 0)
+				var errs []error
 				for _, lb := range ts.LoadBalancers {
 					lbConfig, err := a.findLoadBalancersForTargetGroupWithTaskSetID(*ts.Id, *lb.TargetGroupArn)
 					if err != nil {
 						logger.Printf("failed to find load balancers for target group: %v\n", err)
-						return nil, err
+						errs = append(errs, err)
 					}
 					lbConfigs = append(lbConfigs, lbConfig...)
 				}
-				response.TaskSetConnections[*ts.Id] = lbConfigs
+				if len(errs) > 0 {
+					response.Errors.Add(*ts.Id, types.SectionConnections, errors.Join(errs...))
+				}
+				if len(lbConfigs) > 0 {
+					response.TaskSetConnections[*ts.Id] = lbConfigs
+				}
 			}
 			if ts.TaskDefinition != nil {
-				response.TaskSetImages[*ts.Id], err = a.GetImagesInTaskDefinition(*ts.TaskDefinition)
+				images, err := a.GetImagesInTaskDefinition(*ts.TaskDefinition)
 				if err != nil {
 					logger.Printf("failed to get images in task definition: %v\n", err)
-					return nil, err
+					response.Errors.Add(*ts.Id, types.SectionImages, err)
+				} else {
+					response.TaskSetImages[*ts.Id] = images
 				}
 			}
 
 			tasks, err := a.findTasksForTaskSet(cluster, service, *ts.Id)
 			if err != nil {
 				logger.Printf("failed to find tasks for task set[%s]: %v\n", *ts.Id, err)
-				return nil, err
+				response.Errors.Add(*ts.Id, types.SectionTasks, err)
+				continue
 			}
 			response.TaskSetTasks[*ts.Id] = tasks
+			if err := a.findInstanceIDs(cluster, tasks, response.InstanceIDs); err != nil {
+				logger.Printf("failed to find container instances for task set[%s]: %v\n", *ts.Id, err)
+				response.Errors.Add(*ts.Id, types.SectionInstances, err)
+			}
 		}
 
 	}
 	return response, nil
 }
 
+// UpdateService calls ecs:UpdateService, it is only used by write actions.
+func (a *AWSInteractionLayer) UpdateService(input *ecs.UpdateServiceInput) error {
+	_, err := a.ecs.UpdateService(input)
+	return err
+}
+
+// ModifyRule calls elasticloadbalancing:ModifyRule, it is only used by write
+// actions.
+func (a *AWSInteractionLayer) ModifyRule(input *elbv2.ModifyRuleInput) error {
+	_, err := a.elbv2.ModifyRule(input)
+	return err
+}
+
+// ModifyListener calls elasticloadbalancing:ModifyListener, it is only used
+// by write actions.
+func (a *AWSInteractionLayer) ModifyListener(input *elbv2.ModifyListenerInput) error {
+	_, err := a.elbv2.ModifyListener(input)
+	return err
+}
+
+// UpdateServicePrimaryTaskSet calls ecs:UpdateServicePrimaryTaskSet, it is
+// only used by write actions.
+func (a *AWSInteractionLayer) UpdateServicePrimaryTaskSet(input *ecs.UpdateServicePrimaryTaskSetInput) error {
+	_, err := a.ecs.UpdateServicePrimaryTaskSet(input)
+	return err
+}
+
+// DeleteTaskSet calls ecs:DeleteTaskSet, it is only used by write actions.
+func (a *AWSInteractionLayer) DeleteTaskSet(input *ecs.DeleteTaskSetInput) error {
+	_, err := a.ecs.DeleteTaskSet(input)
+	return err
+}
+
+// ExecuteCommand calls ecs:ExecuteCommand, it is only used by write actions.
+func (a *AWSInteractionLayer) ExecuteCommand(input *ecs.ExecuteCommandInput) (*ecs.ExecuteCommandOutput, error) {
+	return a.ecs.ExecuteCommand(input)
+}
+
+// SessionManagerCommand builds the session-manager-plugin invocation that
+// attaches the terminal to an ExecuteCommand session, with the arguments the
+// AWS CLI passes it.
+func (a *AWSInteractionLayer) SessionManagerCommand(output *ecs.ExecuteCommandOutput, target string) (*exec.Cmd, error) {
+	plugin, err := exec.LookPath(actions.SessionManagerPlugin)
+	if err != nil {
+		return nil, err
+	}
+	session, err := json.Marshal(output.Session)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := json.Marshal(map[string]string{"Target": target})
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(plugin, string(session), aws.StringValue(a.sess.Config.Region), "StartSession",
+		os.Getenv("AWS_PROFILE"), string(parameters), a.ecs.Endpoint)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd, nil
+}
+
+// StopTask calls ecs:StopTask, it is only used by write actions.
+func (a *AWSInteractionLayer) StopTask(input *ecs.StopTaskInput) error {
+	_, err := a.ecs.StopTask(input)
+	return err
+}
+
+// findInstanceIDs adds the EC2 instance IDs of the container instances the
+// tasks run on to instanceIDs, skipping the ones already known.
+func (a *AWSInteractionLayer) findInstanceIDs(cluster string, tasks []*ecs.Task, instanceIDs map[string]string) error {
+	arns := []*string{}
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		arn := aws.StringValue(task.ContainerInstanceArn)
+		if _, ok := instanceIDs[arn]; arn == "" || ok || seen[arn] {
+			continue
+		}
+		seen[arn] = true
+		arns = append(arns, task.ContainerInstanceArn)
+	}
+	for start := 0; start < len(arns); start += 100 {
+		resp, err := a.ecs.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+			Cluster:            aws.String(cluster),
+			ContainerInstances: arns[start:min(start+100, len(arns))],
+		})
+		if err != nil {
+			return err
+		}
+		for _, instance := range resp.ContainerInstances {
+			instanceIDs[aws.StringValue(instance.ContainerInstanceArn)] = aws.StringValue(instance.Ec2InstanceId)
+		}
+	}
+	return nil
+}
+
 func (a *AWSInteractionLayer) findTasksForTaskSet(cluster, service, taskSetID string) ([]*ecs.Task, error) {
 	logger.Println("finding tasks for task set", cluster, service, taskSetID)
-	listResp, err := a.ecs.ListTasks(&ecs.ListTasksInput{
+	var arns []*string
+	err := a.ecs.ListTasksPages(&ecs.ListTasksInput{
 		Cluster:   aws.String(cluster),
 		StartedBy: aws.String(taskSetID),
+	}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, page.TaskArns...)
+		return true
 	})
 	if err != nil {
 		return nil, err
 	}
-	if len(listResp.TaskArns) == 0 {
+	if len(arns) == 0 {
 		logger.Println("no tasks found for task set", taskSetID)
 		return []*ecs.Task{}, nil
 	}
-	taskResp, err := a.ecs.DescribeTasks(&ecs.DescribeTasksInput{
+
+	return a.describeTasks(cluster, arns)
+}
+
+// FetchNetworkDetails resolves the awsvpc subnets and security groups of the
+// service and the Cloud Map services it registers into.
+func (a *AWSInteractionLayer) FetchNetworkDetails(service *ecs.Service) (*types.NetworkDetails, error) {
+	details := &types.NetworkDetails{}
+	addErr := func(section string, err error) {
+		logger.Printf("failed to describe %s of %s: %v\n", section, aws.StringValue(service.ServiceName), err)
+		details.Errors = append(details.Errors, types.SectionError{Section: section, Err: err})
+	}
+
+	if nc := service.NetworkConfiguration; nc != nil && nc.AwsvpcConfiguration != nil {
+		vpc := nc.AwsvpcConfiguration
+		if len(vpc.Subnets) > 0 {
+			subnets, err := a.ec2.DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: vpc.Subnets})
+			if err != nil {
+				addErr(types.SectionSubnets, err)
+			} else {
+				details.Subnets = subnets.Subnets
+			}
+		}
+		if len(vpc.SecurityGroups) > 0 {
+			groups, err := a.ec2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{GroupIds: vpc.SecurityGroups})
+			if err != nil {
+				addErr(types.SectionSecurityGroups, err)
+			} else {
+				details.SecurityGroups = groups.SecurityGroups
+			}
+		}
+	}
+
+	for _, registry := range service.ServiceRegistries {
+		registryArn := aws.StringValue(registry.RegistryArn)
+		registryDetails, err := a.describeServiceRegistry(registryArn)
+		if err != nil {
+			addErr(types.SectionServiceDiscovery, fmt.Errorf("%s: %w", utils.GetLastItemAfterSplit(registryArn, "/"), err))
+		}
+		details.Registries = append(details.Registries, registryDetails)
+	}
+	return details, nil
+}
+
+// describeServiceRegistry loads a Cloud Map service, its namespace and the
+// health of its instances. What loaded is returned along with the error so
+// the rest still shows.
+func (a *AWSInteractionLayer) describeServiceRegistry(registryArn string) (types.ServiceRegistryDetails, error) {
+	details := types.ServiceRegistryDetails{RegistryArn: registryArn, Health: make(map[string]string)}
+	serviceID := utils.GetLastItemAfterSplit(registryArn, "/")
+	service, err := a.sd.GetService(&servicediscovery.GetServiceInput{Id: aws.String(serviceID)})
+	if err != nil {
+		return details, err
+	}
+	details.Service = service.Service
+
+	var errs []error
+	namespace, err := a.sd.GetNamespace(&servicediscovery.GetNamespaceInput{Id: service.Service.NamespaceId})
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		details.Namespace = namespace.Namespace
+	}
+	err = a.sd.ListInstancesPages(&servicediscovery.ListInstancesInput{ServiceId: aws.String(serviceID)},
+		func(page *servicediscovery.ListInstancesOutput, lastPage bool) bool {
+			details.Instances = append(details.Instances, page.Instances...)
+			return true
+		})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = a.sd.GetInstancesHealthStatusPages(&servicediscovery.GetInstancesHealthStatusInput{ServiceId: aws.String(serviceID)},
+		func(page *servicediscovery.GetInstancesHealthStatusOutput, lastPage bool) bool {
+			for id, status := range page.Status {
+				details.Health[id] = aws.StringValue(status)
+			}
+			return true
+		})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return details, errors.Join(errs...)
+}
+
+// FetchServiceTasks returns every task of the service.
+func (a *AWSInteractionLayer) FetchServiceTasks(cluster, service string) ([]*ecs.Task, error) {
+	return a.findTasksForService(cluster, service)
+}
+
+// FetchContainerInstances describes every container instance registered to
+// the cluster, for EC2 backed clusters.
+func (a *AWSInteractionLayer) FetchContainerInstances(cluster string) ([]*ecs.ContainerInstance, error) {
+	logger.Println("fetching container instances of", cluster)
+	var arns []*string
+	err := a.ecs.ListContainerInstancesPages(&ecs.ListContainerInstancesInput{
 		Cluster: aws.String(cluster),
-		Tasks:   listResp.TaskArns,
+	}, func(page *ecs.ListContainerInstancesOutput, lastPage bool) bool {
+		arns = append(arns, page.ContainerInstanceArns...)
+		return true
 	})
 	if err != nil {
 		return nil, err
 	}
+	instances := []*ecs.ContainerInstance{}
+	// DescribeContainerInstances accepts at most 100 instances per call
+	for start := 0; start < len(arns); start += 100 {
+		resp, err := a.ecs.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+			Cluster:            aws.String(cluster),
+			ContainerInstances: arns[start:min(start+100, len(arns))],
+		})
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resp.ContainerInstances...)
+	}
+	return instances, nil
+}
 
-	return taskResp.Tasks, nil
+// FetchInstanceTasks returns the tasks placed on a container instance.
+func (a *AWSInteractionLayer) FetchInstanceTasks(cluster, containerInstanceArn string) ([]*ecs.Task, error) {
+	logger.Println("finding tasks for container instance", cluster, containerInstanceArn)
+	var arns []*string
+	err := a.ecs.ListTasksPages(&ecs.ListTasksInput{
+		Cluster:           aws.String(cluster),
+		ContainerInstance: aws.String(containerInstanceArn),
+	}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, page.TaskArns...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.describeTasks(cluster, arns)
 }
-func (a *AWSInteractionLayer) findLoadBalancersForTargetGroupWithTaskSetID(taskSetID, targetGroupArn string) ([]types.ConnectionConfig, error) {
-	conns, err := a.findLoadBalancersForTargetGroup(targetGroupArn)
+
+// findTasksForService returns every running task of the service, across all
+// of its deployments or task sets.
+func (a *AWSInteractionLayer) findTasksForService(cluster, service string) ([]*ecs.Task, error) {
+	logger.Println("finding tasks for service", cluster, service)
+	var arns []*string
+	err := a.ecs.ListTasksPages(&ecs.ListTasksInput{
+		Cluster:     aws.String(cluster),
+		ServiceName: aws.String(service),
+	}, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, page.TaskArns...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
+	return a.describeTasks(cluster, arns)
+}
+
+// describeTasks describes tasks in batches of 100, the most DescribeTasks
+// accepts at once.
+func (a *AWSInteractionLayer) describeTasks(cluster string, arns []*string) ([]*ecs.Task, error) {
+	tasks := []*ecs.Task{}
+	for start := 0; start < len(arns); start += 100 {
+		taskResp, err := a.ecs.DescribeTasks(&ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   arns[start:min(start+100, len(arns))],
+		})
+		if err != nil {
+			return tasks, err
+		}
+		tasks = append(tasks, taskResp.Tasks...)
+	}
+	return tasks, nil
+}
+
+func (a *AWSInteractionLayer) findLoadBalancersForTargetGroupWithTaskSetID(taskSetID, targetGroupArn string) ([]types.ConnectionConfig, error) {
+	conns, err := a.findLoadBalancersForTargetGroup(targetGroupArn)
 
 	for i := range conns {
 		conns[i].TaskSetID = taskSetID
 	}
-	return conns, nil
+	return conns, err
 }
+
+// findLoadBalancersForTargetGroup returns the connections that forward to the
+// target group. Failures on individual listeners, rules or target health calls
+// are collected and returned alongside the connections that could be resolved.
 func (a *AWSInteractionLayer) findLoadBalancersForTargetGroup(targetGroupArn string) ([]types.ConnectionConfig, error) {
 	// Describe the load balancers
-	lbResp, err := a.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancersInput{})
+	var lbs []*elbv2.LoadBalancer
+	err := a.elbv2.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+		lbs = append(lbs, page.LoadBalancers...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	var lbConfigs []types.ConnectionConfig
+	var errs []error
 	found := false
 	shortTgName := utils.GetLastItemAfterSplit(targetGroupArn, "targetgroup/")
 	tgHealthCache := make(map[string][]*elbv2.TargetHealthDescription)
-	for _, lb := range lbResp.LoadBalancers {
+	for _, lb := range lbs {
 		// Describe the listeners to find associated target groups
-		listenerResp, err := a.elbv2.DescribeListeners(&elbv2.DescribeListenersInput{
+		var listeners []*elbv2.Listener
+		err := a.elbv2.DescribeListenersPages(&elbv2.DescribeListenersInput{
 			LoadBalancerArn: lb.LoadBalancerArn,
+		}, func(page *elbv2.DescribeListenersOutput, lastPage bool) bool {
+			listeners = append(listeners, page.Listeners...)
+			return true
 		})
 		if err != nil {
-			return nil, err
+			errs = append(errs, fmt.Errorf("listeners of %s: %w", *lb.LoadBalancerName, err))
+			continue
 		}
 
-		for _, listener := range listenerResp.Listeners {
+		for _, listener := range listeners {
 			if *listener.Port != 443 {
 				continue
 			}
-			ruleResp, err := a.elbv2.DescribeRules(&elbv2.DescribeRulesInput{
-				ListenerArn: listener.ListenerArn,
-			})
-
+			rules, err := a.describeRules(listener.ListenerArn)
 			if err != nil {
-				return nil, err
+				errs = append(errs, fmt.Errorf("rules of %s: %w", *lb.LoadBalancerName, err))
+				continue
 			}
 
-			for _, rule := range ruleResp.Rules {
+			for _, rule := range rules {
 				for _, action := range rule.Actions {
 					if *action.Type == "forward" {
 						if action.TargetGroupArn != nil && *action.TargetGroupArn == targetGroupArn {
 							tgHealth, err := a.getTGHealth(tgHealthCache, targetGroupArn)
 							if err != nil {
-								return nil, err
+								errs = append(errs, fmt.Errorf("target health of %s: %w", shortTgName, err))
 							}
 							lbConfigs = append(lbConfigs, types.ConnectionConfig{
-								LBName:   *lb.LoadBalancerName,
-								TGName:   shortTgName,
-								TGWeigth: *action.ForwardConfig.TargetGroups[0].Weight,
-								Priority: *rule.Priority,
-								TGHealth: tgHealth,
+								LBName:       *lb.LoadBalancerName,
+								TGName:       shortTgName,
+								TGArn:        targetGroupArn,
+								TGWeigth:     *action.ForwardConfig.TargetGroups[0].Weight,
+								Priority:     *rule.Priority,
+								TGHealth:     tgHealth,
+								ListenerPort: aws.Int64Value(listener.Port),
+								ListenerArn:  aws.StringValue(listener.ListenerArn),
+								Rule:         rule,
 							})
 							found = true
 						} else if action.ForwardConfig != nil && action.ForwardConfig.TargetGroups != nil {
@@ -314,14 +1013,18 @@ func (a *AWSInteractionLayer) findLoadBalancersForTargetGroup(targetGroupArn str
 									logger.Println("found target group in forward config", *action.ForwardConfig)
 									tgHealth, err := a.getTGHealth(tgHealthCache, targetGroupArn)
 									if err != nil {
-										return nil, err
+										errs = append(errs, fmt.Errorf("target health of %s: %w", shortTgName, err))
 									}
 									lbConfigs = append(lbConfigs, types.ConnectionConfig{
-										LBName:   *lb.LoadBalancerName,
-										TGName:   shortTgName,
-										TGWeigth: *tg.Weight,
-										Priority: *rule.Priority,
-										TGHealth: tgHealth,
+										LBName:       *lb.LoadBalancerName,
+										TGName:       shortTgName,
+										TGArn:        targetGroupArn,
+										TGWeigth:     *tg.Weight,
+										Priority:     *rule.Priority,
+										TGHealth:     tgHealth,
+										ListenerPort: aws.Int64Value(listener.Port),
+										ListenerArn:  aws.StringValue(listener.ListenerArn),
+										Rule:         rule,
 									})
 									found = true
 								}
@@ -336,15 +1039,55 @@ func (a *AWSInteractionLayer) findLoadBalancersForTargetGroup(targetGroupArn str
 	if !found {
 		tgHealth, err := a.getTGHealth(tgHealthCache, targetGroupArn)
 		if err != nil {
-			return nil, err
+			errs = append(errs, fmt.Errorf("target health of %s: %w", shortTgName, err))
 		}
 		lbConfigs = append(lbConfigs, types.ConnectionConfig{
 			TGName:   shortTgName,
+			TGArn:    targetGroupArn,
 			TGHealth: tgHealth,
 		})
 	}
 
-	return lbConfigs, nil
+	return lbConfigs, errors.Join(errs...)
+}
+
+// FetchTargetGroupDetails describes a target group's health check and the
+// health of each of its targets.
+func (a *AWSInteractionLayer) FetchTargetGroupDetails(targetGroupArn string) (*types.TargetGroupDetails, error) {
+	groups, err := a.elbv2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		TargetGroupArns: []*string{aws.String(targetGroupArn)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups.TargetGroups) == 0 {
+		return nil, fmt.Errorf("target group %s not found", targetGroupArn)
+	}
+	health, err := a.elbv2.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(targetGroupArn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.TargetGroupDetails{TargetGroup: groups.TargetGroups[0], Health: health.TargetHealthDescriptions}, nil
+}
+
+// describeRules returns every rule of the listener, following NextMarker as
+// the SDK has no paginator for DescribeRules.
+func (a *AWSInteractionLayer) describeRules(listenerArn *string) ([]*elbv2.Rule, error) {
+	var rules []*elbv2.Rule
+	input := &elbv2.DescribeRulesInput{ListenerArn: listenerArn}
+	for {
+		resp, err := a.elbv2.DescribeRules(input)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, resp.Rules...)
+		if aws.StringValue(resp.NextMarker) == "" {
+			return rules, nil
+		}
+		input.Marker = resp.NextMarker
+	}
 }
 
 func (a *AWSInteractionLayer) getTGHealth(cache map[string][]*elbv2.TargetHealthDescription, tgArn string) ([]*elbv2.TargetHealthDescription, error) {
@@ -0,0 +1,45 @@
+// Command mockserver serves a scenario file over the ECS, ELBv2 and
+// Application Auto Scaling APIs for running ecstui against with --endpoint.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/mtyurt/ecstui/mockserver"
+)
+
+func main() {
+	scenarioPath := flag.String("scenario", "", "path to the scenario file")
+	addr := flag.String("addr", "localhost:4566", "address to listen on")
+	flag.Parse()
+
+	if *scenarioPath == "" {
+		fmt.Println("--scenario is required")
+		os.Exit(1)
+	}
+	scenario, err := mockserver.Load(*scenarioPath)
+	if err != nil {
+		fmt.Println("Error loading scenario:", err)
+		os.Exit(1)
+	}
+	server, err := mockserver.New(scenario)
+	if err != nil {
+		fmt.Println("Error loading scenario:", err)
+		os.Exit(1)
+	}
+	server.Logf = log.Printf
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Println("Error listening:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("serving %s on http://%s, run ecstui with:\n\n", *scenarioPath, listener.Addr())
+	fmt.Printf("  AWS_ACCESS_KEY_ID=mock AWS_SECRET_ACCESS_KEY=mock AWS_REGION=us-east-1 go run . --endpoint http://%s\n\n", listener.Addr())
+	log.Fatal(http.Serve(listener, server))
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mtyurt/ecstui/tui/errorview"
 	servicetui "github.com/mtyurt/ecstui/tui/service"
 	"github.com/mtyurt/ecstui/types"
 )
@@ -141,7 +142,7 @@ func main() {
 	// 	},
 	// }
 
-	m := servicetui.New("test-cluster", "test-service", "service-arn", nil, nil)
+	m := servicetui.New("test-cluster", "test-service", "service-arn", servicetui.Fetchers{}, nil, errorview.Recovery{})
 
 	m.TestUpdate(&status)
 
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mtyurt/ecstui/audit"
+)
+
+const defaultLoginCommand = "aws sso login"
+
+type Config struct {
+	// LoginCommand is run from the error screen to refresh expired
+	// credentials, e.g. "aws sso login --profile staging".
+	LoginCommand string `json:"loginCommand"`
+	// AuditLog is the file actions taken with --allow-writes are recorded
+	// to.
+	AuditLog string `json:"auditLog"`
+	// Keys overrides key bindings by name, e.g. "service.events": ["e"].
+	// See keys.Names for the names.
+	Keys map[string][]string `json:"keys"`
+	// Theme names the colors to use, see theme.Names. NO_COLOR in the
+	// environment wins over it.
+	Theme string `json:"theme"`
+}
+
+func Default() Config {
+	return Config{
+		LoginCommand: defaultLoginCommand,
+		AuditLog:     audit.DefaultPath(),
+	}
+}
+
+// DefaultPath returns the config file location under the user's config
+// directory, e.g. ~/.config/ecstui/config.json on Linux.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ecstui", "config.json")
+}
+
+// Load reads the config file at path on top of the defaults. A missing file
+// is not an error, the defaults are returned as is.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+	if cfg.LoginCommand == "" {
+		cfg.LoginCommand = defaultLoginCommand
+	}
+	if cfg.AuditLog == "" {
+		cfg.AuditLog = audit.DefaultPath()
+	}
+	return cfg, nil
+}
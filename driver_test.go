@@ -0,0 +1,200 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/internal/golden"
+)
+
+// cmdTimeout is how long the driver waits for the commands of an update.
+// Fetchers are fakes that answer at once, what is still running after it is
+// a timer, such as a spinner tick or the auto refresh, and is dropped.
+const cmdTimeout = 30 * time.Millisecond
+
+// maxMessages stops an update that keeps producing messages.
+const maxMessages = 1000
+
+// driver runs a mainModel the way the Bubble Tea runtime does, without a
+// terminal: messages are fed to Update and the messages its commands produce
+// are fed back, in order, until there are none left.
+type driver struct {
+	t        *testing.T
+	model    mainModel
+	width    int
+	height   int
+	quit     bool
+	messages int
+}
+
+func newDriver(t *testing.T, m mainModel, width, height int) *driver {
+	t.Helper()
+	d := &driver{t: t, model: m, width: width, height: height}
+	d.run(m.Init())
+	d.send(tea.WindowSizeMsg{Width: width, Height: height})
+	return d
+}
+
+// send feeds msgs one by one, each with everything its commands produce.
+func (d *driver) send(msgs ...tea.Msg) {
+	d.t.Helper()
+	for _, msg := range msgs {
+		d.messages = 0
+		d.update(msg)
+	}
+}
+
+// keys sends each key, e.g. "enter", "ctrl+e" or a rune like "/". Longer
+// strings are typed with typeText.
+func (d *driver) keys(keys ...string) {
+	d.t.Helper()
+	for _, k := range keys {
+		d.send(keyMsg(k))
+	}
+}
+
+// typeText types text into whatever has focus.
+func (d *driver) typeText(text string) {
+	d.t.Helper()
+	for _, r := range text {
+		d.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func (d *driver) update(msg tea.Msg) {
+	d.t.Helper()
+	if d.quit {
+		d.t.Fatalf("%T sent after the program quit", msg)
+	}
+	if _, ok := msg.(tea.QuitMsg); ok {
+		d.quit = true
+		return
+	}
+	d.messages++
+	if d.messages > maxMessages {
+		d.t.Fatalf("more than %d messages for one update, last one %T", maxMessages, msg)
+	}
+	model, cmd := d.model.Update(msg)
+	d.model = model.(mainModel)
+	d.run(cmd)
+}
+
+func (d *driver) run(cmd tea.Cmd) {
+	d.t.Helper()
+	for _, msg := range collect(cmd, time.Now().Add(cmdTimeout)) {
+		if d.quit {
+			return
+		}
+		d.update(msg)
+	}
+}
+
+// collect runs cmd and returns the messages it produces before deadline.
+// Batched commands run concurrently, but their messages are returned in the
+// order of the batch so tests are deterministic.
+func collect(cmd tea.Cmd, deadline time.Time) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		batch, ok := msg.(tea.BatchMsg)
+		if !ok {
+			if msg == nil {
+				return nil
+			}
+			return []tea.Msg{msg}
+		}
+		results := make([][]tea.Msg, len(batch))
+		var wg sync.WaitGroup
+		for i, cmd := range batch {
+			wg.Add(1)
+			go func(i int, cmd tea.Cmd) {
+				defer wg.Done()
+				results[i] = collect(cmd, deadline)
+			}(i, cmd)
+		}
+		wg.Wait()
+		var msgs []tea.Msg
+		for _, result := range results {
+			msgs = append(msgs, result...)
+		}
+		return msgs
+	case <-time.After(time.Until(deadline)):
+		return nil
+	}
+}
+
+// view is the rendered screen without colors.
+func (d *driver) view() string {
+	return golden.Normalize(d.model.View())
+}
+
+// find returns the cell text starts at on the screen.
+func (d *driver) find(text string) (x, y int) {
+	d.t.Helper()
+	for y, line := range strings.Split(d.view(), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return lipgloss.Width(line[:i]), y
+		}
+	}
+	d.t.Fatalf("%q is not on the screen:\n%s", text, d.view())
+	return 0, 0
+}
+
+// click presses the left button on text, button is the wheel to turn it
+// instead.
+func (d *driver) click(text string, button ...tea.MouseButton) {
+	d.t.Helper()
+	x, y := d.find(text)
+	msg := tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+	if len(button) > 0 {
+		msg.Button = button[0]
+	}
+	d.send(msg)
+}
+
+func (d *driver) expectState(state sessionState) {
+	d.t.Helper()
+	if d.model.state != state {
+		d.t.Fatalf("state is %d, want %d; screen:\n%s", d.model.state, state, d.view())
+	}
+}
+
+// expectHeight fails if the screen is taller than the window.
+func (d *driver) expectHeight() {
+	d.t.Helper()
+	if h := lipgloss.Height(d.model.View()); h > d.height {
+		d.t.Fatalf("screen is %d lines high in a %d line window:\n%s", h, d.height, d.view())
+	}
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	for t, name := range keyNames {
+		if name == k {
+			return tea.KeyMsg{Type: t}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+var keyNames = map[tea.KeyType]string{
+	tea.KeyEnter:     "enter",
+	tea.KeyEsc:       "esc",
+	tea.KeyTab:       "tab",
+	tea.KeyShiftTab:  "shift+tab",
+	tea.KeyUp:        "up",
+	tea.KeyDown:      "down",
+	tea.KeyBackspace: "backspace",
+	tea.KeyCtrlC:     "ctrl+c",
+	tea.KeyCtrlE:     "ctrl+e",
+	tea.KeyCtrlK:     "ctrl+k",
+	tea.KeyCtrlR:     "ctrl+r",
+	tea.KeyCtrlX:     "ctrl+x",
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+
+	"github.com/mtyurt/ecstui/mockserver"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// serve starts the mock server on scenario and returns a layer calling it,
+// and the number of requests it throttled so far.
+func serve(t *testing.T, scenario *mockserver.Scenario) (*AWSInteractionLayer, func() int) {
+	t.Helper()
+	server, err := mockserver.New(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	throttled := 0
+	server.Logf = func(format string, args ...interface{}) {
+		if strings.HasSuffix(fmt.Sprintf(format, args...), "throttled=true") {
+			mu.Lock()
+			throttled++
+			mu.Unlock()
+		}
+	}
+	return newEndpointLayer(t, server), func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return throttled
+	}
+}
+
+// newEndpointLayer returns a layer calling handler, with the developer's AWS
+// config kept out of it.
+func newEndpointLayer(t *testing.T, handler http.Handler) *AWSInteractionLayer {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	none := filepath.Join(t.TempDir(), "none")
+	t.Setenv("AWS_CONFIG_FILE", none)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", none)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "mock")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "mock")
+	t.Setenv("AWS_REGION", "us-east-1")
+	sess, err := NewSession(ts.URL, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAWSInteractionLayer(sess)
+}
+
+// pagedScenario has five services, the first with five tasks, served two to
+// a page with every fourth request throttled.
+func pagedScenario(t *testing.T) *mockserver.Scenario {
+	t.Helper()
+	services, tasks := []string{}, []string{}
+	for i := 1; i <= 5; i++ {
+		services = append(services, fmt.Sprintf(`{
+			"serviceName": "svc-%d",
+			"serviceArn": "arn:aws:ecs:us-east-1:123456789012:service/demo/svc-%d",
+			"clusterArn": "arn:aws:ecs:us-east-1:123456789012:cluster/demo",
+			"status": "ACTIVE",
+			"createdAt": 1710406800
+		}`, i, i))
+		tasks = append(tasks, fmt.Sprintf(`{
+			"taskArn": "arn:aws:ecs:us-east-1:123456789012:task/demo/%d",
+			"clusterArn": "arn:aws:ecs:us-east-1:123456789012:cluster/demo",
+			"group": "service:svc-1",
+			"lastStatus": "RUNNING",
+			"desiredStatus": "RUNNING",
+			"startedAt": "2024-03-14T09:00:00Z"
+		}`, i))
+	}
+	path := filepath.Join(t.TempDir(), "paged.json")
+	err := os.WriteFile(path, []byte(fmt.Sprintf(`{
+		"pageSize": 2,
+		"throttleEvery": 4,
+		"steps": [{
+			"clusters": [{"clusterArn": "arn:aws:ecs:us-east-1:123456789012:cluster/demo", "clusterName": "demo"}],
+			"services": [%s],
+			"tasks": [%s]
+		}]
+	}`, strings.Join(services, ","), strings.Join(tasks, ","))), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario, err := mockserver.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scenario
+}
+
+func TestEndpointPagination(t *testing.T) {
+	layer, throttled := serve(t, pagedScenario(t))
+
+	// one ListClusters and three ListServices pages, the last one throttled
+	services, err := layer.FetchServiceList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, service := range services {
+		names = append(names, service.Cluster+"/"+service.Service)
+	}
+	if got, want := strings.Join(names, " "), "demo/svc-1 demo/svc-2 demo/svc-3 demo/svc-4 demo/svc-5"; got != want {
+		t.Errorf("services = %s, want %s", got, want)
+	}
+	if throttled() == 0 {
+		t.Error("no request was throttled, the retry is not covered")
+	}
+
+	tasks, err := layer.FetchServiceTasks("demo", "svc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("%d tasks listed over the pages, want 5", len(tasks))
+	}
+	if got, want := aws.TimeValue(tasks[0].StartedAt), time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("task started at %v, want %v", got, want)
+	}
+
+	status, err := layer.FetchServiceStatus("demo", "svc-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := aws.TimeValue(status.Ecs.CreatedAt), time.Unix(1710406800, 0); !got.Equal(want) {
+		t.Errorf("service created at %v, want %v", got, want)
+	}
+}
+
+func TestEndpointScenario(t *testing.T) {
+	scenario, err := mockserver.Load("mockserver/scenarios/blue-green.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, _ := serve(t, scenario)
+
+	status, err := layer.FetchServiceStatus("demo", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Ecs.TaskSets) == 0 {
+		t.Fatal("the service has no task sets")
+	}
+	// target groups, rules and health come over the XML protocol of ELBv2
+	taskSets, err := layer.FetchTaskSetStatus("demo", "web", status.Ecs.TaskSets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taskSets.Errors) > 0 {
+		t.Fatalf("section errors: %v", taskSets.Errors)
+	}
+	for _, ts := range status.Ecs.TaskSets {
+		connections := taskSets.TaskSetConnections[*ts.Id]
+		if len(connections) == 0 {
+			t.Fatalf("task set %s has no connections", *ts.Id)
+		}
+		if c := connections[0]; c.LBName == "" || c.TGName == "" || len(c.TGHealth) == 0 {
+			t.Errorf("task set %s connection = %+v, want its load balancer, target group and health", *ts.Id, c)
+		}
+		// images keep their registry
+		for _, image := range taskSets.TaskSetImages[*ts.Id] {
+			if !strings.HasPrefix(image, "123456789012.dkr.ecr.us-east-1.amazonaws.com/web:") {
+				t.Errorf("task set %s image = %q, want the full reference", *ts.Id, image)
+			}
+		}
+	}
+}
+
+// fakeECR serves DescribeImages for the digests in images, failing the whole
+// call when one of the requested ones is not there, as ECR does.
+type fakeECR struct {
+	mu     sync.Mutex
+	images map[string]bool
+	calls  [][]string
+}
+
+func (f *fakeECR) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if target := r.Header.Get("X-Amz-Target"); !strings.HasSuffix(target, ".DescribeImages") {
+		http.Error(w, `{"__type":"UnsupportedOperation","message":"`+target+`"}`, http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		ImageIds []struct {
+			ImageDigest string `json:"imageDigest"`
+		} `json:"imageIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	digests := []string{}
+	for _, id := range input.ImageIds {
+		digests = append(digests, id.ImageDigest)
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, digests)
+	f.mu.Unlock()
+
+	details := []map[string]interface{}{}
+	for _, digest := range digests {
+		if !f.images[digest] {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"__type":"ImageNotFoundException","message":"The image with imageId {imageDigest:'%s'} does not exist within the repository with name 'web'"}`, digest)
+			return
+		}
+		details = append(details, map[string]interface{}{
+			"imageDigest":              digest,
+			"imageTags":                []string{"v1"},
+			"imagePushedAt":            1710406800,
+			"imageSizeInBytes":         52428800,
+			"imageScanStatus":          map[string]string{"status": "COMPLETE"},
+			"imageScanFindingsSummary": map[string]interface{}{"findingSeverityCounts": map[string]int{"HIGH": 1}},
+		})
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(map[string]interface{}{"imageDetails": details})
+}
+
+func TestDescribeECRImagesMissing(t *testing.T) {
+	const (
+		kept    = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		expired = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	ecrAPI := &fakeECR{images: map[string]bool{kept: true}}
+	layer := newEndpointLayer(t, ecrAPI)
+	ref, ok := utils.ParseECRImage("123456789012.dkr.ecr.us-east-1.amazonaws.com/web:v1")
+	if !ok {
+		t.Fatal("the image reference does not parse")
+	}
+
+	images, err := layer.describeECRImages(ref, map[string][]string{kept: {"task-1"}, expired: {"task-2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byDigest := map[string]types.ECRImageDetails{}
+	for _, image := range images {
+		byDigest[image.Digest] = image
+	}
+	if image := byDigest[kept]; image.Missing || image.ScanStatus != "COMPLETE" || image.Findings["HIGH"] != 1 || len(image.Tags) != 1 {
+		t.Errorf("kept image = %+v, want its details", image)
+	}
+	if image, ok := byDigest[expired]; !ok || !image.Missing {
+		t.Errorf("expired image = %+v, want it marked missing", image)
+	}
+	// the batch call, then one per image
+	if len(ecrAPI.calls) != 3 || len(ecrAPI.calls[0]) != 2 || len(ecrAPI.calls[1]) != 1 || len(ecrAPI.calls[2]) != 1 {
+		t.Errorf("DescribeImages calls = %v, want the batch then each image alone", ecrAPI.calls)
+	}
+
+	// when they are all there a single call does
+	ecrAPI.images[expired] = true
+	ecrAPI.calls = nil
+	images, err = layer.describeECRImages(ref, map[string][]string{kept: {"task-1"}, expired: {"task-2"}})
+	if err != nil || len(images) != 2 || images[0].Missing || images[1].Missing {
+		t.Errorf("images = %+v, %v, want both described", images, err)
+	}
+	if len(ecrAPI.calls) != 1 {
+		t.Errorf("%d DescribeImages calls, want one", len(ecrAPI.calls))
+	}
+}
+
+func TestFetchNetworkDetailsRegistryError(t *testing.T) {
+	layer := newEndpointLayer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type":"ServiceNotFound","message":"Service not found"}`)
+	}))
+	service := &ecs.Service{
+		ServiceName:       aws.String("web"),
+		ServiceRegistries: []*ecs.ServiceRegistry{{RegistryArn: aws.String("arn:aws:servicediscovery:us-east-1:123456789012:service/srv-web")}},
+	}
+
+	details, err := layer.FetchNetworkDetails(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Registries) != 1 || details.Registries[0].Service != nil {
+		t.Errorf("registries = %+v, want the one that failed without its service", details.Registries)
+	}
+	err = details.Errors.Get(types.SectionServiceDiscovery)
+	if err == nil || !strings.HasPrefix(err.Error(), "srv-web: ServiceNotFound") {
+		t.Errorf("service discovery error = %v, want the registry's", err)
+	}
+}
@@ -0,0 +1,449 @@
+// Package fixtures builds service states for rendering tests: the service
+// as returned by DescribeServices together with what the task set and
+// deployment sections fetch for it.
+package fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+const (
+	account   = "139007003299"
+	region    = "me-central-1"
+	cluster   = "app-cluster-staging"
+	service   = "staging-api"
+	container = "staging-api"
+	image     = account + ".dkr.ecr." + region + ".amazonaws.com/staging-api"
+)
+
+// EventTime is when the latest fixture event happened. Events are rendered
+// with absolute timestamps, so they are fixed; creation times are relative
+// to now because they are rendered as "2 hours ago".
+var EventTime = time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)
+
+// Scenario is a service with the statuses its task set or deployment section
+// loads. One of TaskSets and Deployments is set, depending on the service's
+// deployment controller, or neither for a service without either.
+type Scenario struct {
+	Name        string
+	Service     *types.ServiceStatus
+	TaskSets    *types.TaskSetStatus
+	Deployments *types.DeploymentStatus
+}
+
+// Scenarios lists every fixture.
+func Scenarios() []Scenario {
+	return []Scenario{
+		RollingDeployment(),
+		BlueGreen(),
+		UnattachedTaskSets(),
+		FailedRollout(),
+		Empty(),
+		HugeTaskCount(),
+	}
+}
+
+func arn(resource string) *string {
+	return aws.String(fmt.Sprintf("arn:aws:ecs:%s:%s:%s", region, account, resource))
+}
+
+func targetGroupArn(name string) string {
+	return fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:targetgroup/%s/7f0c1d4ac8c3b215", region, account, name)
+}
+
+func taskDefinition(revision int) *string {
+	return arn(fmt.Sprintf("task-definition/%s:%d", service, revision))
+}
+
+func ago(d time.Duration) *time.Time {
+	t := time.Now().Add(-d)
+	return &t
+}
+
+// task builds a Fargate task with a private IP derived from n, so target
+// health can be matched to it.
+func task(n int, lastStatus string, revision int) *ecs.Task {
+	return &ecs.Task{
+		TaskArn:              arn(fmt.Sprintf("task/%s/%s", cluster, taskID(n))),
+		ClusterArn:           arn("cluster/" + cluster),
+		LastStatus:           aws.String(lastStatus),
+		DesiredStatus:        aws.String(ecs.DesiredStatusRunning),
+		TaskDefinitionArn:    taskDefinition(revision),
+		AvailabilityZone:     aws.String(fmt.Sprintf("%s%c", region, 'a'+n%3)),
+		LaunchType:           aws.String(ecs.LaunchTypeFargate),
+		EnableExecuteCommand: aws.Bool(true),
+		Attachments: []*ecs.Attachment{{
+			Type: aws.String("ElasticNetworkInterface"),
+			Details: []*ecs.KeyValuePair{
+				{Name: aws.String("privateIPv4Address"), Value: aws.String(privateIP(n))},
+			},
+		}},
+		Containers: []*ecs.Container{{
+			Name:       aws.String(container),
+			LastStatus: aws.String(lastStatus),
+			Image:      aws.String(fmt.Sprintf("%s:%d", image, revision)),
+		}},
+	}
+}
+
+// taskID spreads task ids like ECS does, so truncated ids stay distinct.
+func taskID(n int) string {
+	return fmt.Sprintf("%08x%024x", uint32(n+1)*2654435761, n)
+}
+
+func privateIP(n int) string {
+	return fmt.Sprintf("10.0.%d.%d", n/250, n%250+4)
+}
+
+func tasks(from, count int, lastStatus string, revision int) []*ecs.Task {
+	result := make([]*ecs.Task, 0, count)
+	for i := from; i < from+count; i++ {
+		result = append(result, task(i, lastStatus, revision))
+	}
+	return result
+}
+
+// health registers every task as a target in the given state.
+func health(tasks []*ecs.Task, state string) []*elbv2.TargetHealthDescription {
+	result := []*elbv2.TargetHealthDescription{}
+	for _, t := range tasks {
+		description := &elbv2.TargetHealthDescription{
+			Target: &elbv2.TargetDescription{
+				Id:               aws.String(utils.TaskPrivateIP(t)),
+				Port:             aws.Int64(9292),
+				AvailabilityZone: t.AvailabilityZone,
+			},
+			TargetHealth: &elbv2.TargetHealth{State: aws.String(state)},
+		}
+		if state != elbv2.TargetHealthStateEnumHealthy {
+			description.TargetHealth.Reason = aws.String(elbv2.TargetHealthReasonEnumTargetFailedHealthChecks)
+		}
+		result = append(result, description)
+	}
+	return result
+}
+
+func baseService(controller string, revision int) *ecs.Service {
+	return &ecs.Service{
+		ClusterArn:  arn("cluster/" + cluster),
+		ServiceArn:  arn(fmt.Sprintf("service/%s/%s", cluster, service)),
+		ServiceName: aws.String(service),
+		Status:      aws.String("ACTIVE"),
+		DeploymentConfiguration: &ecs.DeploymentConfiguration{
+			MaximumPercent:        aws.Int64(200),
+			MinimumHealthyPercent: aws.Int64(100),
+		},
+		DeploymentController: &ecs.DeploymentController{Type: aws.String(controller)},
+		LaunchType:           aws.String(ecs.LaunchTypeFargate),
+		SchedulingStrategy:   aws.String(ecs.SchedulingStrategyReplica),
+		TaskDefinition:       taskDefinition(revision),
+		EnableExecuteCommand: aws.Bool(true),
+		PendingCount:         aws.Int64(0),
+	}
+}
+
+func events(messages ...string) []*ecs.ServiceEvent {
+	result := []*ecs.ServiceEvent{}
+	for i, message := range messages {
+		result = append(result, &ecs.ServiceEvent{
+			Id:        aws.String(fmt.Sprintf("%08x-fd88-46d4-9707-5a466d6d8f8d", i)),
+			CreatedAt: aws.Time(EventTime.Add(-time.Duration(i) * 7 * time.Minute)),
+			Message:   aws.String(message),
+		})
+	}
+	return result
+}
+
+func images(revision int) []string {
+	return []string{fmt.Sprintf("%s:%d", image, revision)}
+}
+
+// RollingDeployment is an ECS controller service halfway through replacing
+// revision 441 with 442.
+func RollingDeployment() Scenario {
+	oldTasks := tasks(0, 2, ecs.DesiredStatusRunning, 441)
+	newTasks := append(tasks(2, 1, ecs.DesiredStatusRunning, 442), tasks(3, 1, ecs.DesiredStatusPending, 442)...)
+
+	svc := baseService(ecs.DeploymentControllerTypeEcs, 442)
+	svc.DesiredCount = aws.Int64(2)
+	svc.RunningCount = aws.Int64(3)
+	svc.PendingCount = aws.Int64(1)
+	svc.LoadBalancers = []*ecs.LoadBalancer{{
+		ContainerName:  aws.String(container),
+		ContainerPort:  aws.Int64(9292),
+		TargetGroupArn: aws.String(targetGroupArn("staging-api-tg")),
+	}}
+	svc.Deployments = []*ecs.Deployment{{
+		Id:             aws.String("ecs-svc/1111111111111111111"),
+		Status:         aws.String("ACTIVE"),
+		TaskDefinition: taskDefinition(441),
+		DesiredCount:   aws.Int64(2),
+		RunningCount:   aws.Int64(2),
+		PendingCount:   aws.Int64(0),
+		RolloutState:   aws.String(ecs.DeploymentRolloutStateCompleted),
+		CreatedAt:      ago(26 * time.Hour),
+		UpdatedAt:      ago(26 * time.Hour),
+	}, {
+		Id:             aws.String("ecs-svc/2222222222222222222"),
+		Status:         aws.String("PRIMARY"),
+		TaskDefinition: taskDefinition(442),
+		DesiredCount:   aws.Int64(2),
+		RunningCount:   aws.Int64(1),
+		PendingCount:   aws.Int64(1),
+		RolloutState:   aws.String(ecs.DeploymentRolloutStateInProgress),
+		CreatedAt:      ago(2 * time.Hour),
+		UpdatedAt:      ago(2 * time.Hour),
+	}}
+	svc.Events = events(
+		"(service staging-api) has started 1 tasks: (task "+taskID(3)+").",
+		"(service staging-api) registered 1 targets in (target-group "+targetGroupArn("staging-api-tg")+")",
+		"(service staging-api) has started 1 tasks: (task "+taskID(2)+").",
+		"(service staging-api) has reached a steady state.",
+	)
+
+	return Scenario{
+		Name:    "rolling_deployment",
+		Service: &types.ServiceStatus{Ecs: svc, Asg: types.ServiceScale{Min: 2, Max: 6}, Images: images(442)},
+		Deployments: &types.DeploymentStatus{
+			DeploymentImages: map[string][]string{
+				"ecs-svc/1111111111111111111": images(441),
+				"ecs-svc/2222222222222222222": images(442),
+			},
+			DeploymentTasks: map[string][]*ecs.Task{
+				"ecs-svc/1111111111111111111": oldTasks,
+				"ecs-svc/2222222222222222222": newTasks,
+			},
+			DeploymentConnections: []types.ConnectionConfig{{
+				LBName:       "staging-api-lb",
+				TGName:       "staging-api-tg",
+				TGArn:        targetGroupArn("staging-api-tg"),
+				TGWeigth:     100,
+				ListenerPort: 443,
+				TGHealth: append(append(health(oldTasks, elbv2.TargetHealthStateEnumHealthy),
+					health(newTasks[:1], elbv2.TargetHealthStateEnumHealthy)...),
+					health(newTasks[1:], elbv2.TargetHealthStateEnumInitial)...),
+			}},
+			Errors: types.SectionErrors{},
+		},
+	}
+}
+
+// blueGreenService is an EXTERNAL controller service with a blue and a green
+// task set, the green one not attached to a target group if attachGreen is
+// false.
+func blueGreenService(attachGreen bool) (*ecs.Service, *types.TaskSetStatus) {
+	blueTasks := tasks(0, 2, ecs.DesiredStatusRunning, 441)
+	greenTasks := tasks(2, 2, ecs.DesiredStatusRunning, 442)
+
+	svc := baseService(ecs.DeploymentControllerTypeExternal, 442)
+	svc.DesiredCount = aws.Int64(2)
+	svc.RunningCount = aws.Int64(4)
+	blue := &ecs.TaskSet{
+		Id:                   aws.String("ecs-svc/3517849243791983451"),
+		TaskSetArn:           arn(fmt.Sprintf("task-set/%s/%s/ecs-svc/3517849243791983451", cluster, service)),
+		Status:               aws.String("PRIMARY"),
+		StabilityStatus:      aws.String(ecs.StabilityStatusSteadyState),
+		TaskDefinition:       taskDefinition(441),
+		ComputedDesiredCount: aws.Int64(2),
+		RunningCount:         aws.Int64(2),
+		PendingCount:         aws.Int64(0),
+		Scale:                &ecs.Scale{Unit: aws.String(ecs.ScaleUnitPercent), Value: aws.Float64(100)},
+		CreatedAt:            ago(50 * time.Hour),
+		LoadBalancers: []*ecs.LoadBalancer{{
+			ContainerName:  aws.String(container),
+			ContainerPort:  aws.Int64(9292),
+			TargetGroupArn: aws.String(targetGroupArn("staging-api-blue")),
+		}},
+	}
+	green := &ecs.TaskSet{
+		Id:                   aws.String("ecs-svc/8895224990753999325"),
+		TaskSetArn:           arn(fmt.Sprintf("task-set/%s/%s/ecs-svc/8895224990753999325", cluster, service)),
+		Status:               aws.String("ACTIVE"),
+		StabilityStatus:      aws.String(ecs.StabilityStatusStabilizing),
+		TaskDefinition:       taskDefinition(442),
+		ComputedDesiredCount: aws.Int64(2),
+		RunningCount:         aws.Int64(2),
+		PendingCount:         aws.Int64(0),
+		Scale:                &ecs.Scale{Unit: aws.String(ecs.ScaleUnitPercent), Value: aws.Float64(100)},
+		CreatedAt:            ago(3 * time.Hour),
+	}
+	if attachGreen {
+		green.LoadBalancers = []*ecs.LoadBalancer{{
+			ContainerName:  aws.String(container),
+			ContainerPort:  aws.Int64(9292),
+			TargetGroupArn: aws.String(targetGroupArn("staging-api-green")),
+		}}
+	}
+	svc.TaskSets = []*ecs.TaskSet{blue, green}
+	svc.Events = events(
+		"(service staging-api, taskSet ecs-svc/8895224990753999325) registered 2 targets in (target-group "+targetGroupArn("staging-api-green")+")",
+		"(service staging-api, taskSet ecs-svc/8895224990753999325) has started 2 tasks: (task "+taskID(2)+") (task "+taskID(3)+").",
+		"(service staging-api) updated computedDesiredCount for taskSet ecs-svc/8895224990753999325 to 2.",
+		"(service staging-api) has reached a steady state.",
+	)
+
+	status := &types.TaskSetStatus{
+		TaskSetImages: map[string][]string{
+			*blue.Id:  images(441),
+			*green.Id: images(442),
+		},
+		TaskSetTasks: map[string][]*ecs.Task{
+			*blue.Id:  blueTasks,
+			*green.Id: greenTasks,
+		},
+		TaskSetConnections: map[string][]types.ConnectionConfig{
+			*blue.Id: {{
+				TaskSetID:    *blue.Id,
+				LBName:       "staging-api-lb",
+				TGName:       "staging-api-blue",
+				TGArn:        targetGroupArn("staging-api-blue"),
+				TGWeigth:     90,
+				ListenerPort: 443,
+				Priority:     "10",
+				TGHealth:     health(blueTasks, elbv2.TargetHealthStateEnumHealthy),
+			}},
+		},
+		Errors: types.SectionErrors{},
+	}
+	if attachGreen {
+		status.TaskSetConnections[*green.Id] = []types.ConnectionConfig{{
+			TaskSetID:    *green.Id,
+			LBName:       "staging-api-lb",
+			TGName:       "staging-api-green",
+			TGArn:        targetGroupArn("staging-api-green"),
+			TGWeigth:     10,
+			ListenerPort: 443,
+			Priority:     "10",
+			TGHealth: append(health(greenTasks[:1], elbv2.TargetHealthStateEnumHealthy),
+				health(greenTasks[1:], elbv2.TargetHealthStateEnumUnhealthy)...),
+		}}
+	}
+	return svc, status
+}
+
+// BlueGreen is an EXTERNAL controller service shifting traffic from a blue
+// to a green task set behind the same listener rule.
+func BlueGreen() Scenario {
+	svc, status := blueGreenService(true)
+	return Scenario{
+		Name:     "blue_green",
+		Service:  &types.ServiceStatus{Ecs: svc, Asg: types.ServiceScale{Min: 2, Max: 4}, Images: images(442)},
+		TaskSets: status,
+	}
+}
+
+// UnattachedTaskSets is a blue/green service whose green task set is not
+// registered to any target group yet.
+func UnattachedTaskSets() Scenario {
+	svc, status := blueGreenService(false)
+	return Scenario{
+		Name:     "unattached_task_sets",
+		Service:  &types.ServiceStatus{Ecs: svc, Asg: types.ServiceScale{Min: 2, Max: 4}, Images: images(442)},
+		TaskSets: status,
+	}
+}
+
+// FailedRollout is an ECS controller service whose deployment circuit
+// breaker rolled back revision 443.
+func FailedRollout() Scenario {
+	goodTasks := tasks(0, 2, ecs.DesiredStatusRunning, 442)
+	failedTasks := tasks(2, 2, ecs.DesiredStatusStopped, 443)
+
+	svc := baseService(ecs.DeploymentControllerTypeEcs, 442)
+	svc.DesiredCount = aws.Int64(2)
+	svc.RunningCount = aws.Int64(2)
+	svc.DeploymentConfiguration.DeploymentCircuitBreaker = &ecs.DeploymentCircuitBreaker{Enable: aws.Bool(true), Rollback: aws.Bool(true)}
+	svc.Deployments = []*ecs.Deployment{{
+		Id:                 aws.String("ecs-svc/4444444444444444444"),
+		Status:             aws.String("PRIMARY"),
+		TaskDefinition:     taskDefinition(442),
+		DesiredCount:       aws.Int64(2),
+		RunningCount:       aws.Int64(2),
+		PendingCount:       aws.Int64(0),
+		RolloutState:       aws.String(ecs.DeploymentRolloutStateInProgress),
+		RolloutStateReason: aws.String("ECS deployment circuit breaker: rolling back to deploymentId ecs-svc/4444444444444444444."),
+		CreatedAt:          ago(1 * time.Hour),
+	}, {
+		Id:                 aws.String("ecs-svc/5555555555555555555"),
+		Status:             aws.String("ACTIVE"),
+		TaskDefinition:     taskDefinition(443),
+		DesiredCount:       aws.Int64(0),
+		RunningCount:       aws.Int64(0),
+		PendingCount:       aws.Int64(0),
+		FailedTasks:        aws.Int64(6),
+		RolloutState:       aws.String(ecs.DeploymentRolloutStateFailed),
+		RolloutStateReason: aws.String("ECS deployment circuit breaker: tasks failed to start."),
+		CreatedAt:          ago(3 * time.Hour),
+	}}
+	svc.Events = events(
+		"(service staging-api) (deployment ecs-svc/5555555555555555555) deployment failed: tasks failed to start.",
+		"(service staging-api) rolling back to deployment ecs-svc/4444444444444444444.",
+		"(service staging-api) has stopped 2 running tasks: (task "+taskID(2)+") (task "+taskID(3)+").",
+		"(service staging-api) is unable to consistently start tasks successfully.",
+	)
+
+	return Scenario{
+		Name:    "failed_rollout",
+		Service: &types.ServiceStatus{Ecs: svc, Asg: types.ServiceScale{Min: 2, Max: 2}, Images: images(442)},
+		Deployments: &types.DeploymentStatus{
+			DeploymentImages: map[string][]string{
+				"ecs-svc/4444444444444444444": images(442),
+				"ecs-svc/5555555555555555555": images(443),
+			},
+			DeploymentTasks: map[string][]*ecs.Task{
+				"ecs-svc/4444444444444444444": goodTasks,
+				"ecs-svc/5555555555555555555": failedTasks,
+			},
+			Errors: types.SectionErrors{},
+		},
+	}
+}
+
+// Empty is a service scaled to zero that has neither deployments nor task
+// sets, e.g. one whose only task set was deleted.
+func Empty() Scenario {
+	svc := baseService(ecs.DeploymentControllerTypeExternal, 1)
+	svc.DesiredCount = aws.Int64(0)
+	svc.RunningCount = aws.Int64(0)
+	return Scenario{
+		Name:    "empty",
+		Service: &types.ServiceStatus{Ecs: svc},
+	}
+}
+
+// HugeTaskCount is a rolling deployment of a service running 60 tasks.
+func HugeTaskCount() Scenario {
+	running := tasks(0, 60, ecs.DesiredStatusRunning, 442)
+
+	svc := baseService(ecs.DeploymentControllerTypeEcs, 442)
+	svc.DesiredCount = aws.Int64(60)
+	svc.RunningCount = aws.Int64(60)
+	svc.Deployments = []*ecs.Deployment{{
+		Id:             aws.String("ecs-svc/6666666666666666666"),
+		Status:         aws.String("PRIMARY"),
+		TaskDefinition: taskDefinition(442),
+		DesiredCount:   aws.Int64(60),
+		RunningCount:   aws.Int64(60),
+		PendingCount:   aws.Int64(0),
+		RolloutState:   aws.String(ecs.DeploymentRolloutStateCompleted),
+		CreatedAt:      ago(5 * time.Hour),
+	}}
+	svc.Events = events("(service staging-api) has reached a steady state.")
+
+	return Scenario{
+		Name:    "huge_task_count",
+		Service: &types.ServiceStatus{Ecs: svc, Asg: types.ServiceScale{Min: 60, Max: 120}, Images: images(442)},
+		Deployments: &types.DeploymentStatus{
+			DeploymentImages: map[string][]string{"ecs-svc/6666666666666666666": images(442)},
+			DeploymentTasks:  map[string][]*ecs.Task{"ecs-svc/6666666666666666666": running},
+			Errors:           types.SectionErrors{},
+		},
+	}
+}
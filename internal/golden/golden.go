@@ -0,0 +1,64 @@
+// Package golden compares rendered views against golden files under a
+// package's testdata directory. Run the tests with -update to rewrite them:
+//
+//	go test ./tui/... -update
+package golden
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the rendered views")
+
+// Widths are the terminal widths views are rendered at, from a laptop split
+// to an ultrawide.
+var Widths = []int{80, 120, 200}
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+func init() {
+	// renders the same on a terminal and in CI
+	lipgloss.SetColorProfile(termenv.Ascii)
+	lipgloss.SetHasDarkBackground(true)
+}
+
+// Normalize strips ANSI sequences and trailing whitespace, so golden files
+// only change when the layout or the text does.
+func Normalize(view string) string {
+	lines := strings.Split(ansiSequence.ReplaceAllString(view, ""), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// Assert compares view with testdata/<name>.golden.
+func Assert(t *testing.T, name, view string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := Normalize(view)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run the test with -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match the rendered view, run the test with -update if the change is intended\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
@@ -1,13 +1,25 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/audit"
+	"github.com/mtyurt/ecstui/config"
 	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/record"
 	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/actions"
+	"github.com/mtyurt/ecstui/tui/errorview"
+	"github.com/mtyurt/ecstui/tui/keys"
 	listtui "github.com/mtyurt/ecstui/tui/list"
 	servicetui "github.com/mtyurt/ecstui/tui/service"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -18,7 +30,7 @@ const (
 	initialLoad sessionState = iota
 	listView
 	detailView
-	fatalError
+	errorView
 )
 
 type mainModel struct {
@@ -26,12 +38,85 @@ type mainModel struct {
 	list          listtui.Model
 	spinner       spinnertui.Model
 	serviceDetail *servicetui.Model
+	errorView     errorview.Model
 	initialCall   func() tea.Msg
 	err           error
-	awsLayer      *AWSInteractionLayer
+	fetchers      servicetui.Fetchers
+	writers       actions.Writers
+	recovery      errorview.Recovery
+	// auditLog is only set with --allow-writes.
+	auditLog *audit.Log
+	// showHelp covers the service screen with the full help of its keys.
+	showHelp      bool
 	width, height int
 }
 
+var helpBox = lipgloss.NewStyle().
+	Padding(1, 2).
+	BorderStyle(lipgloss.RoundedBorder())
+
+// banner warns that actions can change live resources, it stays on screen
+// for the whole session with --allow-writes.
+func (m mainModel) banner() string {
+	if m.auditLog == nil {
+		return ""
+	}
+	return theme.Current.Banner.Copy().Width(m.width).Render("WRITE MODE: actions change live resources and are recorded to " + m.auditLog.Path())
+}
+
+// contentHeight is the height left to the views under the banner.
+func (m mainModel) contentHeight() int {
+	if m.auditLog == nil {
+		return m.height
+	}
+	return m.height - lipgloss.Height(m.banner())
+}
+
+// writeAccess lets the service screen take actions, nil keeps it read-only.
+func (m mainModel) writeAccess() *servicetui.WriteAccess {
+	if m.auditLog == nil {
+		return nil
+	}
+	return &servicetui.WriteAccess{
+		Writers:  m.writers,
+		AuditLog: m.auditLog,
+	}
+}
+
+// serviceFetchers are the calls the service screen reads with.
+func serviceFetchers(awsLayer *AWSInteractionLayer) servicetui.Fetchers {
+	return servicetui.Fetchers{
+		ServiceStatus:    awsLayer.FetchServiceStatus,
+		TaskSetStatus:    awsLayer.FetchTaskSetStatus,
+		DeploymentStatus: awsLayer.FetchDeploymentsStatus,
+		TaskDefinition:   awsLayer.FetchTaskDefinition,
+		Revisions:        awsLayer.ListTaskDefinitionRevisions,
+		ImageDetails:     awsLayer.FetchImageDetails,
+		ScalingDetails:   awsLayer.FetchScalingDetails,
+		PlacementDetails: awsLayer.FetchPlacementDetails,
+		ServiceTasks:     awsLayer.FetchServiceTasks,
+		Instances:        awsLayer.FetchContainerInstances,
+		InstanceTasks:    awsLayer.FetchInstanceTasks,
+		NetworkDetails:   awsLayer.FetchNetworkDetails,
+		TargetGroup:      awsLayer.FetchTargetGroupDetails,
+	}
+}
+
+// serviceWriters are the calls actions are taken with, they are only handed
+// to the service screen with --allow-writes.
+func serviceWriters(awsLayer *AWSInteractionLayer) actions.Writers {
+	return actions.Writers{
+		UpdateService:               awsLayer.UpdateService,
+		StopTask:                    awsLayer.StopTask,
+		UpdateServicePrimaryTaskSet: awsLayer.UpdateServicePrimaryTaskSet,
+		DeleteTaskSet:               awsLayer.DeleteTaskSet,
+		ModifyRule:                  awsLayer.ModifyRule,
+		ModifyListener:              awsLayer.ModifyListener,
+		ExecuteCommand:              awsLayer.ExecuteCommand,
+		SessionManager:              awsLayer.SessionManagerCommand,
+	}
+}
+
 func (m mainModel) Init() tea.Cmd {
 	return tea.Batch(m.initialCall, m.spinner.SpinnerTick())
 }
@@ -39,41 +124,58 @@ func (m mainModel) Init() tea.Cmd {
 func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	newServiceDetail := false
+	// forward is what the active view gets, msg itself unless it has to be
+	// adjusted to the view.
+	forward := msg
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		logger.Printf("keymsg: %v\n", msg)
-		if m.state == fatalError {
-			return m, tea.Quit
-		}
-		k := msg.String()
-		if k == "ctrl+c" {
+		if key.Matches(msg, keys.Map.Global.ForceQuit) || (key.Matches(msg, keys.Map.Global.Quit) && m.state == errorView) {
 			return m, tea.Quit
-		} else if msg.Type == tea.KeyEnter && m.state == listView && !m.list.IsFiltering() {
-			selectedService := m.list.GetSelectedServiceArn()
-			m.state = detailView
-			serviceDetail := servicetui.New(selectedService.Cluster(),
-				selectedService.Service(),
-				selectedService.ServiceArn(),
-				m.awsLayer.FetchServiceStatus,
-				m.awsLayer.FetchTaskSetStatus,
-				m.awsLayer.FetchDeploymentsStatus,
-			)
-			serviceDetail.SetSize(m.width, m.height)
-			m.serviceDetail = &serviceDetail
+		} else if m.showHelp {
+			if key.Matches(msg, keys.Map.Global.Help, keys.Map.Global.Back) {
+				m.showHelp = false
+			}
+			return m, nil
+		} else if m.state == detailView && key.Matches(msg, keys.Map.Global.Help) && !m.serviceDetail.InputFocused() {
+			m.showHelp = true
+			return m, nil
+		} else if key.Matches(msg, keys.Map.List.Open) && m.state == listView && !m.list.IsFiltering() {
+			if !m.openService() { // nothing listed or nothing matches the filter
+				return m, nil
+			}
 			cmds = append(cmds, m.serviceDetail.Init())
 			newServiceDetail = true
-		} else if m.state == detailView && k == "esc" && m.serviceDetail != nil && m.serviceDetail.Focused {
+		} else if m.state == detailView && key.Matches(msg, keys.Map.Global.Back) && m.serviceDetail != nil && m.serviceDetail.Focused {
 			logger.Println("esc pressed, unfocusing service detail", m.serviceDetail.Focused)
 			m.state = listView
 			m.serviceDetail = nil
+			// the list would take the same esc as clearing its filter
+			return m, nil
+		}
+	case tea.MouseMsg:
+		if m.showHelp {
+			return m, nil
+		}
+		// the views are laid out under the banner
+		msg.Y -= m.height - m.contentHeight()
+		forward = msg
+		if m.state == listView && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && !m.list.IsFiltering() {
+			if !m.list.Click(msg.X, msg.Y) || !m.openService() {
+				return m, nil
+			}
+			return m, m.serviceDetail.Init()
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.list.SetSize(msg.Width, msg.Height)
+		m.list.SetSize(msg.Width, m.contentHeight())
+		m.errorView.SetSize(msg.Width, m.contentHeight())
 		if m.serviceDetail != nil {
-			m.serviceDetail.SetSize(msg.Width, msg.Height)
+			m.serviceDetail.SetSize(msg.Width, m.contentHeight())
 		}
+		// views below the banner size themselves from the message too
+		forward = tea.WindowSizeMsg{Width: msg.Width, Height: m.contentHeight()}
 
 	case serviceListMsg:
 		m.list.SetItems(msg)
@@ -81,21 +183,33 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.state = listView
 	case errMsg:
 		m.err = msg
-		m.state = fatalError
+		m.errorView = errorview.New(msg.err, m.recovery, keys.Map.Global.Quit)
+		m.errorView.SetSize(m.width, m.contentHeight())
+		m.state = errorView
 		return m, nil
+	case errorview.RetryMsg:
+		if m.state == errorView {
+			logger.Println("retrying service listing")
+			m.err = nil
+			m.state = initialLoad
+			return m, tea.Batch(m.initialCall, m.spinner.SpinnerTick())
+		}
 	}
 
 	var cmd tea.Cmd
 	switch m.state {
 	case initialLoad:
-		m.spinner, cmd = m.spinner.Update(msg)
+		m.spinner, cmd = m.spinner.Update(forward)
+		cmds = append(cmds, cmd)
+	case errorView:
+		m.errorView, cmd = m.errorView.Update(forward)
 		cmds = append(cmds, cmd)
 	case listView:
-		m.list, cmd = m.list.Update(msg)
+		m.list, cmd = m.list.Update(forward)
 		cmds = append(cmds, cmd)
 	case detailView:
 		if !newServiceDetail {
-			serviceDetail, cmd := m.serviceDetail.Update(msg)
+			serviceDetail, cmd := m.serviceDetail.Update(forward)
 			m.serviceDetail = &serviceDetail
 			cmds = append(cmds, cmd)
 		}
@@ -103,19 +217,67 @@ func (m mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// openService opens the service screen of the selected service, false if
+// there is none.
+func (m *mainModel) openService() bool {
+	selectedService, ok := m.list.GetSelectedServiceArn()
+	if !ok {
+		return false
+	}
+	m.state = detailView
+	serviceDetail := servicetui.New(selectedService.Cluster(),
+		selectedService.Service(),
+		selectedService.ServiceArn(),
+		m.fetchers,
+		m.writeAccess(),
+		m.recovery,
+	)
+	serviceDetail.SetSize(m.width, m.contentHeight())
+	m.serviceDetail = &serviceDetail
+	return true
+}
+
+// helpView lists the keys of the screen on top of the service screen, the
+// same bindings its footer is made from and its Update matches.
+func (m mainModel) helpView() string {
+	columns := [][]key.Binding{}
+	bindings := m.serviceDetail.Help()
+	for len(bindings) > 8 {
+		columns = append(columns, bindings[:8])
+		bindings = bindings[8:]
+	}
+	columns = append(columns, bindings, []key.Binding{keys.Describe(keys.Map.Global.Help, "close help"), keys.Map.Global.ForceQuit})
+	h := help.New()
+	h.Styles = theme.Current.Help()
+	h.Width = m.width - 8
+	box := theme.Current.Bordered(helpBox).Render(theme.Current.Title.Render("keys") + "\n\n" + h.FullHelpView(columns))
+	return lipgloss.Place(m.width, m.contentHeight(), lipgloss.Center, lipgloss.Center, box)
+}
+
 func (m mainModel) View() string {
+	view := ""
 	switch m.state {
-	case fatalError:
-		return m.err.Error()
+	case errorView:
+		view = m.errorView.View()
 	case initialLoad:
-		return m.spinner.View()
+		view = m.spinner.View()
 	case listView:
-		return m.list.View()
+		view = m.list.View()
 	case detailView:
-		return m.serviceDetail.View()
+		if m.showHelp {
+			view = m.helpView()
+		} else {
+			view = m.serviceDetail.View()
+		}
 	default:
-		return "View State Error"
+		view = "View State Error"
 	}
+	// the views mark what a click can find, the terminal gets them clean
+	view = zone.Clean(view)
+	if banner := m.banner(); banner != "" {
+		return banner + "\n" + view
+	}
+	return view
 }
 
 type serviceListMsg []listtui.ListItem
@@ -124,17 +286,92 @@ type errMsg struct{ err error }
 
 func (e errMsg) Error() string { return e.err.Error() }
 
-func newModel(initialCall func() tea.Msg, awsLayer *AWSInteractionLayer) mainModel {
+func newModel(initialCall func() tea.Msg, fetchers servicetui.Fetchers, writers actions.Writers, recovery errorview.Recovery, auditLog *audit.Log) mainModel {
 	return mainModel{spinner: spinnertui.New("Loading Services..."),
 		list:        listtui.New(),
 		state:       initialLoad,
 		initialCall: initialCall,
-		awsLayer:    awsLayer,
+		fetchers:    fetchers,
+		writers:     writers,
+		recovery:    recovery,
+		auditLog:    auditLog,
 	}
 
 }
 func main() {
-	awsLayer := NewAWSInteractionLayer()
+	configPath := flag.String("config", config.DefaultPath(), "path to the config file")
+	allowWrites := flag.Bool("allow-writes", false, "enable actions that change services and tasks, each is confirmed and recorded to the audit log")
+	recordPath := flag.String("record", "", "record every AWS API call and its response to this file")
+	replayPath := flag.String("replay", "", "serve AWS API calls from a file made with --record instead of calling AWS")
+	endpoint := flag.String("endpoint", "", "call this endpoint instead of AWS, e.g. a mock server started with cmd/mockserver")
+	flag.Parse()
+
+	if *recordPath != "" && *replayPath != "" {
+		fmt.Println("--record and --replay cannot be used together")
+		os.Exit(1)
+	}
+	if *endpoint != "" && *replayPath != "" {
+		fmt.Println("--endpoint and --replay cannot be used together")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
+	if err := theme.Load(cfg.Theme); err != nil {
+		fmt.Println("Error loading theme:", err)
+		os.Exit(1)
+	}
+	if err := keys.Map.Apply(cfg.Keys); err != nil {
+		fmt.Println("Error loading key bindings:", err)
+		os.Exit(1)
+	}
+
+	var auditLog *audit.Log
+	if *allowWrites {
+		auditLog, err = audit.Open(cfg.AuditLog)
+		if err != nil {
+			fmt.Println("Error opening audit log:", err)
+			os.Exit(1)
+		}
+	}
+
+	var recorder *record.Recorder
+	if *recordPath != "" {
+		recorder, err = record.Create(*recordPath)
+		if err != nil {
+			fmt.Println("Error creating recording:", err)
+			os.Exit(1)
+		}
+	}
+	// os.Exit skips deferred calls, so the recording is closed explicitly on
+	// every way out
+	closeRecorder := func() {
+		if recorder != nil {
+			recorder.Close()
+		}
+	}
+	exit := func(code int) {
+		closeRecorder()
+		os.Exit(code)
+	}
+	var replayer *record.Replayer
+	if *replayPath != "" {
+		replayer, err = record.Load(*replayPath)
+		if err != nil {
+			fmt.Println("Error loading recording:", err)
+			exit(1)
+		}
+	}
+	sess, err := NewSession(*endpoint, recorder, replayer)
+	if err != nil {
+		fmt.Println("Error creating AWS session:", err)
+		exit(1)
+	}
+
+	awsLayer := NewAWSInteractionLayer(sess)
 	initialCall := func() tea.Msg {
 		services, err := awsLayer.FetchServiceList()
 		if err != nil {
@@ -148,16 +385,21 @@ func main() {
 		return serviceListMsg(items)
 	}
 
-	m := newModel(initialCall, awsLayer)
+	recovery := errorview.Recovery{
+		LoginCommand:      cfg.LoginCommand,
+		ReloadCredentials: awsLayer.ReloadCredentials,
+	}
+	m := newModel(initialCall, serviceFetchers(awsLayer), serviceWriters(awsLayer), recovery, auditLog)
 	if os.Getenv("DEBUG") == "true" {
 		f, _ := tea.LogToFile("log.txt", "debug")
 		logger.Initialize(f)
 		defer f.Close()
 	}
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Error running program:", err)
-		os.Exit(1)
+		exit(1)
 	}
+	closeRecorder()
 }
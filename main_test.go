@@ -0,0 +1,549 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/audit"
+	"github.com/mtyurt/ecstui/internal/fixtures"
+	"github.com/mtyurt/ecstui/tui/actions"
+	"github.com/mtyurt/ecstui/tui/errorview"
+	"github.com/mtyurt/ecstui/tui/keys"
+	listtui "github.com/mtyurt/ecstui/tui/list"
+	servicetui "github.com/mtyurt/ecstui/tui/service"
+	"github.com/mtyurt/ecstui/types"
+)
+
+// account fakes the AWS calls of the screens: staging-api is a rolling
+// deployment, payments a blue/green service and reporting cannot be
+// described. Failures can be injected per call.
+type account struct {
+	mu           sync.Mutex
+	listFailures int
+	listCalls    int
+	statusCalls  map[string]int
+	// statusErrs fail the next calls of a service, one error each.
+	statusErrs map[string][]error
+}
+
+func newAccount() *account {
+	return &account{statusCalls: map[string]int{}, statusErrs: map[string][]error{}}
+}
+
+var scenarios = map[string]fixtures.Scenario{
+	"staging-api": fixtures.RollingDeployment(),
+	"payments":    fixtures.BlueGreen(),
+}
+
+func (a *account) listServices() tea.Msg {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.listCalls++
+	if a.listFailures > 0 {
+		a.listFailures--
+		return errMsg{awserr.New("ExpiredTokenException", "The security token included in the request is expired", nil)}
+	}
+	return serviceListMsg{
+		listtui.NewListItem("staging-api", "app-cluster-staging", "arn:aws:ecs:me-central-1:139007003299:service/app-cluster-staging/staging-api"),
+		listtui.NewListItem("payments", "app-cluster-staging", "arn:aws:ecs:me-central-1:139007003299:service/app-cluster-staging/payments"),
+		listtui.NewListItem("reporting", "batch", "arn:aws:ecs:me-central-1:139007003299:service/batch/reporting"),
+	}
+}
+
+func (a *account) serviceStatus(cluster, service string) (*types.ServiceStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.statusCalls[service]++
+	if errs := a.statusErrs[service]; len(errs) > 0 {
+		a.statusErrs[service] = errs[1:]
+		return nil, errs[0]
+	}
+	scenario, ok := scenarios[service]
+	if !ok {
+		return nil, awserr.New("AccessDeniedException", "User is not authorized to perform: ecs:DescribeServices", nil)
+	}
+	return scenario.Service, nil
+}
+
+func (a *account) calls(service string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.statusCalls[service]
+}
+
+func (a *account) fetchers() servicetui.Fetchers {
+	return servicetui.Fetchers{
+		ServiceStatus: a.serviceStatus,
+		TaskSetStatus: func(cluster, service string, taskSets []*ecs.TaskSet) (*types.TaskSetStatus, error) {
+			return scenarios[service].TaskSets, nil
+		},
+		DeploymentStatus: func(cluster, service string, deployments []*ecs.Deployment, loadBalancers []*ecs.LoadBalancer) (*types.DeploymentStatus, error) {
+			return scenarios[service].Deployments, nil
+		},
+		ServiceTasks: func(cluster, service string) ([]*ecs.Task, error) {
+			var tasks []*ecs.Task
+			for _, deploymentTasks := range scenarios[service].Deployments.DeploymentTasks {
+				tasks = append(tasks, deploymentTasks...)
+			}
+			return tasks, nil
+		},
+	}
+}
+
+func newTestModel(a *account, auditLog *audit.Log) mainModel {
+	recovery := errorview.Recovery{LoginCommand: "aws sso login"}
+	return newModel(a.listServices, a.fetchers(), actions.Writers{}, recovery, auditLog)
+}
+
+func expectContains(t *testing.T, view string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(view, w) {
+			t.Fatalf("screen does not contain %q:\n%s", w, view)
+		}
+	}
+}
+
+func expectNotContains(t *testing.T, view string, unwanted ...string) {
+	t.Helper()
+	for _, u := range unwanted {
+		if strings.Contains(view, u) {
+			t.Fatalf("screen contains %q:\n%s", u, view)
+		}
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	a := newAccount()
+	d := newDriver(t, newTestModel(a, nil), 160, 50)
+	d.expectState(listView)
+	expectContains(t, d.view(), "ECS Services", "staging-api", "payments", "reporting")
+
+	d.keys("enter")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "service/app-cluster-staging/staging-api", "ecs-svc/2222222222222222222")
+
+	d.keys("ctrl+e")
+	expectContains(t, d.view(), "Press / to filter", "has started 1 tasks")
+
+	d.keys("esc")
+	d.expectState(detailView)
+	expectNotContains(t, d.view(), "Press / to filter")
+	expectContains(t, d.view(), "ecs-svc/2222222222222222222")
+
+	d.keys("esc")
+	d.expectState(listView)
+	if d.model.serviceDetail != nil {
+		t.Fatal("service detail is kept after going back to the list")
+	}
+
+	d.keys("down", "enter")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "service/app-cluster-staging/payments")
+	if calls := a.calls("payments"); calls != 1 {
+		t.Fatalf("payments described %d times, want 1", calls)
+	}
+}
+
+func TestEventsFilterEscStaysInEvents(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("enter", "ctrl+e", "/")
+	d.typeText("registered")
+	expectContains(t, d.view(), "Filter: registered", "registered 1 targets")
+	expectNotContains(t, d.view(), "has started 1 tasks")
+
+	// the first esc clears the filter, the second one leaves the events
+	d.keys("esc")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "Press / to filter", "has started 1 tasks")
+	d.keys("esc")
+	expectNotContains(t, d.view(), "Press / to filter")
+	d.keys("esc")
+	d.expectState(listView)
+}
+
+func TestListFilter(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("/")
+	d.typeText("pay")
+	// keys go to the filter while typing, q must not quit and enter only
+	// applies the filter
+	d.typeText("q")
+	d.keys("backspace", "enter")
+	d.expectState(listView)
+	if d.quit {
+		t.Fatal("q quit while filtering")
+	}
+	expectContains(t, d.view(), "payments")
+	expectNotContains(t, d.view(), "staging-api", "reporting")
+
+	d.keys("enter")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "service/app-cluster-staging/payments")
+
+	// the esc leaving the service must not also clear the list's filter
+	d.keys("esc")
+	d.expectState(listView)
+	expectContains(t, d.view(), "payments")
+	expectNotContains(t, d.view(), "staging-api", "reporting")
+
+	d.keys("esc")
+	expectContains(t, d.view(), "staging-api", "payments", "reporting")
+}
+
+func TestEnterWithoutServices(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	// a filter without matches is cleared when it is applied
+	d.keys("/")
+	d.typeText("nothing-matches")
+	d.keys("enter")
+	d.expectState(listView)
+	expectContains(t, d.view(), "staging-api", "payments", "reporting")
+
+	d.send(serviceListMsg{})
+	d.keys("enter")
+	d.expectState(listView)
+	expectContains(t, d.view(), "No items")
+}
+
+func TestQuit(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("enter", "ctrl+c")
+	if !d.quit {
+		t.Fatal("ctrl+c did not quit the service screen")
+	}
+
+	d = newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("q")
+	if !d.quit {
+		t.Fatal("q did not quit the list")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	a := newAccount()
+	d := newDriver(t, newTestModel(a, nil), 160, 50)
+	d.keys("enter", "ctrl+r")
+	if calls := a.calls("staging-api"); calls != 2 {
+		t.Fatalf("staging-api described %d times, want 2", calls)
+	}
+	expectNotContains(t, d.view(), "refresh failed")
+
+	// a failed refresh keeps the last status on screen
+	a.statusErrs["staging-api"] = []error{awserr.New("ThrottlingException", "Rate exceeded", nil)}
+	d.keys("ctrl+r")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "refresh failed", "Rate exceeded", "ecs-svc/2222222222222222222")
+
+	d.keys("ctrl+r")
+	expectNotContains(t, d.view(), "refresh failed")
+	expectContains(t, d.view(), "ecs-svc/2222222222222222222")
+}
+
+func TestListError(t *testing.T) {
+	a := newAccount()
+	a.listFailures = 1
+	d := newDriver(t, newTestModel(a, nil), 160, 50)
+	d.expectState(errorView)
+	expectContains(t, d.view(), "Request failed", "security token included in the request is expired", "press l to log in again", "q quit")
+
+	d.keys("r")
+	d.expectState(listView)
+	if a.listCalls != 2 {
+		t.Fatalf("services listed %d times, want 2", a.listCalls)
+	}
+	expectContains(t, d.view(), "staging-api")
+}
+
+func TestServiceError(t *testing.T) {
+	a := newAccount()
+	d := newDriver(t, newTestModel(a, nil), 160, 50)
+	d.keys("down", "down", "enter")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "Request failed", "not authorized to perform: ecs:DescribeServices", "esc back")
+
+	d.keys("r")
+	if calls := a.calls("reporting"); calls != 2 {
+		t.Fatalf("reporting described %d times, want 2", calls)
+	}
+	expectContains(t, d.view(), "Request failed")
+
+	d.keys("esc")
+	d.expectState(listView)
+	expectContains(t, d.view(), "reporting")
+}
+
+func TestServiceErrorRetry(t *testing.T) {
+	a := newAccount()
+	a.statusErrs["payments"] = []error{errors.New("connection reset by peer")}
+	d := newDriver(t, newTestModel(a, nil), 160, 50)
+	d.keys("down", "enter")
+	expectContains(t, d.view(), "Request failed", "connection reset by peer")
+
+	d.keys("r")
+	d.expectState(detailView)
+	expectNotContains(t, d.view(), "Request failed")
+	expectContains(t, d.view(), "service/app-cluster-staging/payments", "ecs-svc/8895224990753999325")
+}
+
+func TestWriteModeSizes(t *testing.T) {
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDriver(t, newTestModel(newAccount(), auditLog), 160, 40)
+	expectContains(t, d.view(), "WRITE MODE")
+	d.expectHeight()
+
+	d.keys("enter", "ctrl+k")
+	expectContains(t, d.view(), "WRITE MODE", "tasks of staging-api")
+	d.expectHeight()
+
+	// views size themselves from resizes too, they must leave the banner
+	// its line
+	d.width, d.height = 150, 30
+	d.send(tea.WindowSizeMsg{Width: d.width, Height: d.height})
+	d.expectHeight()
+}
+
+func TestWriteGate(t *testing.T) {
+	calls := 0
+	writers := actions.Writers{UpdateService: func(input *ecs.UpdateServiceInput) error {
+		calls++
+		return nil
+	}}
+	recovery := errorview.Recovery{LoginCommand: "aws sso login"}
+
+	// without --allow-writes there is no audit log and no way to the actions
+	a := newAccount()
+	d := newDriver(t, newModel(a.listServices, a.fetchers(), writers, recovery, nil), 160, 50)
+	d.keys("enter", "ctrl+x")
+	expectNotContains(t, d.view(), "WRITE MODE", "force new deployment")
+	d.keys("ctrl+k")
+	expectNotContains(t, d.view(), "stop task")
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a = newAccount()
+	d = newDriver(t, newModel(a.listServices, a.fetchers(), writers, recovery, auditLog), 160, 50)
+	d.keys("enter", "ctrl+x")
+	expectContains(t, d.view(), "WRITE MODE", "force new deployment")
+	d.keys("enter")
+	expectContains(t, d.view(), "changes the live service staging-api", "ecs:UpdateService")
+	if calls != 0 {
+		t.Fatalf("UpdateService called %d times before the action was confirmed", calls)
+	}
+	d.keys("y")
+	expectContains(t, d.view(), "ecs:UpdateService succeeded", "recorded to "+path)
+	if calls != 1 {
+		t.Fatalf("UpdateService called %d times, want once", calls)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"phase":"intent"`) || !strings.Contains(lines[1], `"phase":"outcome"`) {
+		t.Errorf("audit log = %s, want the intent and the outcome of the call", data)
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("enter", "?")
+	expectContains(t, d.view(), "keys", "ctrl+e", "events", "ctrl+k", "tasks", "close help")
+	expectNotContains(t, d.view(), "ecs-svc/2222222222222222222")
+
+	// keys other than closing it are swallowed
+	d.keys("ctrl+e", "esc")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "ecs-svc/2222222222222222222")
+
+	// the overlay follows the open view
+	d.keys("ctrl+k", "?")
+	expectContains(t, d.view(), "detail", "container instance")
+	d.keys("?")
+	expectContains(t, d.view(), "tasks of staging-api")
+
+	// ? is typed into the events filter rather than opening the overlay
+	d.keys("esc", "ctrl+e", "/")
+	d.typeText("?")
+	expectContains(t, d.view(), "Filter: ?")
+}
+
+func TestKeyOverrides(t *testing.T) {
+	t.Cleanup(func() { keys.Map = keys.Default() })
+	err := keys.Map.Apply(map[string][]string{
+		"service.events": {"e"},
+		"global.back":    {"h", "esc"},
+		"service.tasks":  {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("enter")
+	// the footer follows the bindings
+	expectContains(t, d.view(), "e events", "h back")
+	expectNotContains(t, d.view(), "ctrl+e events", "ctrl+k tasks", "ctrl+shift+key works!")
+
+	d.keys("ctrl+e")
+	expectNotContains(t, d.view(), "Press / to filter")
+	d.keys("e")
+	expectContains(t, d.view(), "Press / to filter")
+	d.keys("h")
+	expectNotContains(t, d.view(), "Press / to filter")
+
+	d.keys("ctrl+k")
+	expectNotContains(t, d.view(), "tasks of staging-api")
+	d.keys("h")
+	d.expectState(listView)
+}
+
+func TestSmallTerminal(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 80, 24)
+	d.keys("enter")
+	d.expectHeight()
+	// the sections scroll under the footer
+	expectContains(t, d.view(), "running 3", "last update")
+	expectNotContains(t, d.view(), "has started 1 tasks")
+	for i := 0; i < 80; i++ {
+		d.keys("down")
+	}
+	expectContains(t, d.view(), "has started 1 tasks", "last update")
+	expectNotContains(t, d.view(), "running 3")
+	d.expectHeight()
+
+	d.keys("ctrl+e")
+	expectContains(t, d.view(), "Press / to filter")
+	d.expectHeight()
+
+	// growing the window gives the events the room
+	d.width, d.height = 200, 60
+	d.send(tea.WindowSizeMsg{Width: d.width, Height: d.height})
+	d.expectHeight()
+	if h := lipgloss.Height(d.model.View()); h != d.height {
+		t.Errorf("events are %d lines high in a %d line window", h, d.height)
+	}
+	d.keys("esc")
+	expectContains(t, d.view(), "running 3", "has started 1 tasks")
+	d.expectHeight()
+}
+
+func TestSectionFocus(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 80, 24)
+	d.keys("enter")
+	expectNotContains(t, d.view(), "┏")
+
+	// shift+tab wraps around to the events, scrolling them into sight
+	d.keys("shift+tab")
+	expectContains(t, d.view(), "┏", "has started 1 tasks", "esc unfocus")
+	expectNotContains(t, d.view(), "running 3")
+	overview := d.view()
+	d.keys("enter")
+	expectContains(t, d.view(), "Press / to filter")
+
+	// esc comes back to the same scroll position and focus
+	d.keys("esc")
+	d.expectState(detailView)
+	if got := d.view(); got != overview {
+		t.Errorf("overview after esc:\n%s\nwant:\n%s", got, overview)
+	}
+
+	d.keys("tab")
+	expectContains(t, d.view(), "┏", "running 3")
+	d.keys("tab", "tab", "tab", "enter")
+	expectContains(t, d.view(), "tasks of staging-api")
+	d.keys("esc")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "┏", "tasksets", "open tasks")
+
+	d.keys("esc")
+	d.expectState(detailView)
+	expectNotContains(t, d.view(), "┏")
+	d.keys("esc")
+	d.expectState(listView)
+}
+
+func TestTaskTableFocus(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.keys("enter")
+	expectContains(t, d.view(), "] focus deployment")
+	expectNotContains(t, d.view(), "┏")
+
+	d.keys("]")
+	expectContains(t, d.view(), "┏", "›", "s sort by start time", "esc unfocus")
+	d.keys("s")
+	expectContains(t, d.view(), "started", "by start time")
+
+	// esc gives the keys back to the section, then to the overview, before
+	// leaving the service
+	d.keys("esc")
+	d.expectState(detailView)
+	expectNotContains(t, d.view(), "›")
+	expectContains(t, d.view(), "┏", "open tasks", "esc unfocus")
+	d.keys("esc")
+	d.expectState(detailView)
+	expectNotContains(t, d.view(), "┏")
+	d.keys("esc")
+	d.expectState(listView)
+
+	// other screens take no keys from the tables, which are focused again
+	// when the overview is back
+	d.keys("enter", "[", "ctrl+k", "s", "esc")
+	expectContains(t, d.view(), "›", "s sort by start time")
+	d.keys("esc", "esc", "esc")
+	d.expectState(listView)
+}
+
+func TestMouse(t *testing.T) {
+	d := newDriver(t, newTestModel(newAccount(), nil), 160, 50)
+	d.click("ECS Services")
+	d.expectState(listView)
+	d.click("payments")
+	d.expectState(detailView)
+	expectContains(t, d.view(), "service/app-cluster-staging/payments", "tasksets")
+
+	// footer entries press their keys
+	d.click("ctrl+e events")
+	expectContains(t, d.view(), "Press / to filter")
+	d.keys("esc")
+
+	// a task row opens the tasks of its box at the task
+	d.click("daa66d13")
+	expectContains(t, d.view(), "tasks of ecs-svc/8895224990753999325", "task: daa66d13000000000000000000000002")
+	d.keys("esc", "esc")
+	expectContains(t, d.view(), "┏", "esc unfocus")
+
+	// the wheel scrolls the overview under the events
+	top := d.view()
+	d.click("has started 2 tasks", tea.MouseButtonWheelDown)
+	if d.view() == top {
+		t.Errorf("the wheel did not scroll the overview:\n%s", top)
+	}
+	d.click("has started 2 tasks")
+	expectContains(t, d.view(), "Press / to filter")
+}
+
+func TestMouseUnderBanner(t *testing.T) {
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDriver(t, newTestModel(newAccount(), auditLog), 160, 50)
+	d.click("payments")
+	expectContains(t, d.view(), "WRITE MODE", "service/app-cluster-staging/payments")
+	d.click("9e3779b1")
+	expectContains(t, d.view(), "tasks of ecs-svc/3517849243791983451", "task: 9e3779b1000000000000000000000000")
+}
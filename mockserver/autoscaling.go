@@ -0,0 +1,86 @@
+package mockserver
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	autoscaling "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+)
+
+func (s *Server) autoscaling(state *Step, operation string, body []byte) (interface{}, error) {
+	switch operation {
+	case "DescribeScalableTargets":
+		input := &autoscaling.DescribeScalableTargetsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		targets := []*autoscaling.ScalableTarget{}
+		for _, target := range state.ScalableTargets {
+			if aws.StringValue(target.ServiceNamespace) == aws.StringValue(input.ServiceNamespace) &&
+				(len(input.ResourceIds) == 0 || contains(input.ResourceIds, target.ResourceId)) {
+				targets = append(targets, target)
+			}
+		}
+		start, end, next, err := s.paginate(len(targets), input.NextToken, input.MaxResults, 50)
+		if err != nil {
+			return nil, err
+		}
+		return &autoscaling.DescribeScalableTargetsOutput{ScalableTargets: targets[start:end], NextToken: next}, nil
+	case "DescribeScalingPolicies":
+		input := &autoscaling.DescribeScalingPoliciesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		policies := []*autoscaling.ScalingPolicy{}
+		for _, policy := range state.ScalingPolicies {
+			if input.ResourceId == nil || aws.StringValue(policy.ResourceId) == *input.ResourceId {
+				policies = append(policies, policy)
+			}
+		}
+		start, end, next, err := s.paginate(len(policies), input.NextToken, input.MaxResults, 50)
+		if err != nil {
+			return nil, err
+		}
+		return &autoscaling.DescribeScalingPoliciesOutput{ScalingPolicies: policies[start:end], NextToken: next}, nil
+	case "DescribeScheduledActions":
+		input := &autoscaling.DescribeScheduledActionsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		actions := []*autoscaling.ScheduledAction{}
+		for _, action := range state.ScheduledActions {
+			if input.ResourceId == nil || aws.StringValue(action.ResourceId) == *input.ResourceId {
+				actions = append(actions, action)
+			}
+		}
+		start, end, next, err := s.paginate(len(actions), input.NextToken, input.MaxResults, 50)
+		if err != nil {
+			return nil, err
+		}
+		return &autoscaling.DescribeScheduledActionsOutput{ScheduledActions: actions[start:end], NextToken: next}, nil
+	case "DescribeScalingActivities":
+		input := &autoscaling.DescribeScalingActivitiesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		activities := []*autoscaling.ScalingActivity{}
+		for _, activity := range state.ScalingActivities {
+			if input.ResourceId == nil || aws.StringValue(activity.ResourceId) == *input.ResourceId {
+				activities = append(activities, activity)
+			}
+		}
+		start, end, next, err := s.paginate(len(activities), input.NextToken, input.MaxResults, 50)
+		if err != nil {
+			return nil, err
+		}
+		return &autoscaling.DescribeScalingActivitiesOutput{ScalingActivities: activities[start:end], NextToken: next}, nil
+	}
+	return nil, unknownOperation(operation)
+}
+
+func contains(values []*string, value *string) bool {
+	for _, v := range values {
+		if aws.StringValue(v) == aws.StringValue(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The SDK shapes carry their wire names in locationName tags rather than
+// json or xml ones, so they are walked here and handed to encoding/json and
+// encoding/xml as plain values.
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldName is the wire name of a field, "" for fields not on the wire.
+func fieldName(field reflect.StructField) string {
+	if !field.IsExported() || field.Tag.Get("location") != "" {
+		return ""
+	}
+	if name := field.Tag.Get("locationName"); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// marshalJSON encodes v the way the JSON protocol does: keys are the
+// locationNames, timestamps epoch seconds and unset fields left out.
+func marshalJSON(v interface{}) ([]byte, error) {
+	return json.Marshal(jsonValue(reflect.ValueOf(v)))
+}
+
+func jsonValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == timeType:
+		t := v.Interface().(time.Time)
+		return float64(t.UnixNano()) / float64(time.Second)
+	case v.Kind() == reflect.Struct:
+		object := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			name := fieldName(v.Type().Field(i))
+			if name == "" || isUnset(v.Field(i)) {
+				continue
+			}
+			object[name] = jsonValue(v.Field(i))
+		}
+		return object
+	case v.Kind() == reflect.Slice:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	case v.Kind() == reflect.Map:
+		object := map[string]interface{}{}
+		for _, key := range v.MapKeys() {
+			object[key.String()] = jsonValue(v.MapIndex(key))
+		}
+		return object
+	}
+	return v.Interface()
+}
+
+func isUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// unmarshalJSON decodes data into the shape v points to. Keys match the
+// locationNames or field names case-insensitively, and timestamps may be
+// epoch seconds or RFC 3339 strings, as the AWS CLI prints them.
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return assign(reflect.ValueOf(v).Elem(), value, "")
+}
+
+func assign(v reflect.Value, value interface{}, path string) error {
+	if value == nil {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assign(v.Elem(), value, path)
+	}
+	mismatch := func() error {
+		return fmt.Errorf("%s: cannot use %T as %s", strings.TrimPrefix(path, "."), value, v.Type())
+	}
+	switch {
+	case v.Type() == timeType:
+		switch value := value.(type) {
+		case json.Number:
+			seconds, err := value.Float64()
+			if err != nil {
+				return mismatch()
+			}
+			whole, fraction := math.Modf(seconds)
+			v.Set(reflect.ValueOf(time.Unix(int64(whole), int64(fraction*float64(time.Second))).UTC()))
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", strings.TrimPrefix(path, "."), err)
+			}
+			v.Set(reflect.ValueOf(t))
+		default:
+			return mismatch()
+		}
+	case v.Kind() == reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		for key, fieldValue := range object {
+			for i := 0; i < v.NumField(); i++ {
+				field := v.Type().Field(i)
+				name := fieldName(field)
+				if name == "" || (!strings.EqualFold(key, name) && !strings.EqualFold(key, field.Name)) {
+					continue
+				}
+				if err := assign(v.Field(i), fieldValue, path+"."+key); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	case v.Kind() == reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		slice := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := assign(slice.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case v.Kind() == reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(object))
+		for key, item := range object {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := assign(elem, item, path+"."+key); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+	case v.Kind() == reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch()
+		}
+		v.SetBool(b)
+	case v.CanInt():
+		n, ok := value.(json.Number)
+		i, err := n.Int64()
+		if !ok || err != nil {
+			return mismatch()
+		}
+		v.SetInt(i)
+	case v.CanFloat():
+		n, ok := value.(json.Number)
+		f, err := n.Float64()
+		if !ok || err != nil {
+			return mismatch()
+		}
+		v.SetFloat(f)
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+// writeXML encodes v as the members of a query protocol result: elements
+// named by the locationNames, lists wrapped in member elements unless they
+// are flattened, and ISO 8601 timestamps.
+func writeXML(encoder *xml.Encoder, v interface{}) error {
+	if err := xmlFields(encoder, reflect.Indirect(reflect.ValueOf(v))); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func xmlFields(encoder *xml.Encoder, v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := fieldName(field)
+		if name == "" || isUnset(v.Field(i)) {
+			continue
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Slice && field.Tag.Get("flattened") == "true" {
+			for j := 0; j < value.Len(); j++ {
+				if err := xmlElement(encoder, name, value.Index(j), ""); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := xmlElement(encoder, name, value, field.Tag.Get("locationNameList")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func xmlElement(encoder *xml.Encoder, name string, v reflect.Value, memberName string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case v.Type() == timeType:
+		err = encoder.EncodeToken(xml.CharData(v.Interface().(time.Time).UTC().Format(time.RFC3339Nano)))
+	case v.Kind() == reflect.Struct:
+		err = xmlFields(encoder, v)
+	case v.Kind() == reflect.Slice:
+		if memberName == "" {
+			memberName = "member"
+		}
+		for i := 0; i < v.Len() && err == nil; i++ {
+			err = xmlElement(encoder, memberName, v.Index(i), "")
+		}
+	case v.Kind() == reflect.String:
+		err = encoder.EncodeToken(xml.CharData(v.String()))
+	case v.Kind() == reflect.Bool:
+		err = encoder.EncodeToken(xml.CharData(strconv.FormatBool(v.Bool())))
+	case v.CanInt():
+		err = encoder.EncodeToken(xml.CharData(strconv.FormatInt(v.Int(), 10)))
+	case v.CanFloat():
+		err = encoder.EncodeToken(xml.CharData(strconv.FormatFloat(v.Float(), 'f', -1, 64)))
+	default:
+		err = fmt.Errorf("%s: cannot encode %s as XML", name, v.Type())
+	}
+	if err != nil {
+		return err
+	}
+	return encoder.EncodeToken(start.End())
+}
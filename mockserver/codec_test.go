@@ -0,0 +1,85 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	service := &ecs.Service{}
+	err := unmarshalJSON([]byte(`{"ServiceName": "web", "desiredCount": 3, "createdAt": 1710406800.5,
+		"deployments": [{"id": "ecs-svc/1", "updatedAt": "2024-03-14T09:00:00Z"}]}`), service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(service.ServiceName) != "web" || aws.Int64Value(service.DesiredCount) != 3 {
+		t.Errorf("decoded %v", service)
+	}
+	if got, want := aws.TimeValue(service.CreatedAt), time.Unix(1710406800, 5e8); !got.Equal(want) {
+		t.Errorf("createdAt = %v, want %v", got, want)
+	}
+	if got, want := aws.TimeValue(service.Deployments[0].UpdatedAt), time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("updatedAt = %v, want %v", got, want)
+	}
+
+	encoded, err := marshalJSON(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"serviceName":"web"`, `"createdAt":1710406800.5`, `"deployments":[{`} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("%s does not contain %s", encoded, want)
+		}
+	}
+	if strings.Contains(string(encoded), "runningCount") {
+		t.Errorf("unset fields are encoded: %s", encoded)
+	}
+}
+
+func TestJSONMismatch(t *testing.T) {
+	err := unmarshalJSON([]byte(`{"desiredCount": "three"}`), &ecs.Service{})
+	if err == nil || !strings.Contains(err.Error(), "desiredCount") {
+		t.Errorf("err = %v, want it to name desiredCount", err)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	output := &elbv2.DescribeTargetGroupsOutput{
+		TargetGroups: []*elbv2.TargetGroup{{
+			TargetGroupName:  aws.String("a<b"),
+			Port:             aws.Int64(80),
+			LoadBalancerArns: []*string{aws.String("lb-1")},
+		}},
+		NextMarker: aws.String("2"),
+	}
+	var b bytes.Buffer
+	if err := writeXML(xml.NewEncoder(&b), output); err != nil {
+		t.Fatal(err)
+	}
+	want := "<NextMarker>2</NextMarker><TargetGroups><member><LoadBalancerArns><member>lb-1</member></LoadBalancerArns>" +
+		"<Port>80</Port><TargetGroupName>a&lt;b</TargetGroupName></member></TargetGroups>"
+	if b.String() != want {
+		t.Errorf("XML =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	s := &Server{pageSize: 2}
+	start, end, next, err := s.paginate(5, aws.String("2"), nil, 100)
+	if err != nil || start != 2 || end != 4 || aws.StringValue(next) != "4" {
+		t.Errorf("page = %d-%d next %v err %v, want 2-4 next 4", start, end, aws.StringValue(next), err)
+	}
+	if _, _, next, _ := s.paginate(5, aws.String("4"), nil, 100); next != nil {
+		t.Errorf("last page has next token %q", *next)
+	}
+	if _, _, _, err := s.paginate(5, aws.String("x"), nil, 100); err == nil {
+		t.Error("invalid token accepted")
+	}
+}
@@ -0,0 +1,245 @@
+package mockserver
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const defaultCluster = "default"
+
+func (s *Server) ecs(state *Step, operation string, body []byte) (interface{}, error) {
+	switch operation {
+	case "ListClusters":
+		input := &ecs.ListClustersInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		arns := []*string{}
+		for _, c := range state.Clusters {
+			arns = append(arns, c.ClusterArn)
+		}
+		start, end, next, err := s.paginate(len(arns), input.NextToken, input.MaxResults, 100)
+		if err != nil {
+			return nil, err
+		}
+		return &ecs.ListClustersOutput{ClusterArns: arns[start:end], NextToken: next}, nil
+	case "DescribeClusters":
+		input := &ecs.DescribeClustersInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		output := &ecs.DescribeClustersOutput{Clusters: []*ecs.Cluster{}}
+		for _, c := range state.Clusters {
+			if matchesAny(input.Clusters, c.ClusterArn) {
+				output.Clusters = append(output.Clusters, c)
+			}
+		}
+		return output, nil
+	case "ListServices":
+		input := &ecs.ListServicesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		arns := []*string{}
+		for _, svc := range state.Services {
+			if inCluster(input.Cluster, svc.ClusterArn) {
+				arns = append(arns, svc.ServiceArn)
+			}
+		}
+		start, end, next, err := s.paginate(len(arns), input.NextToken, input.MaxResults, 10)
+		if err != nil {
+			return nil, err
+		}
+		return &ecs.ListServicesOutput{ServiceArns: arns[start:end], NextToken: next}, nil
+	case "DescribeServices":
+		input := &ecs.DescribeServicesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		output := &ecs.DescribeServicesOutput{Services: []*ecs.Service{}, Failures: []*ecs.Failure{}}
+		for _, name := range input.Services {
+			found := false
+			for _, svc := range state.Services {
+				if inCluster(input.Cluster, svc.ClusterArn) && matches(aws.StringValue(name), svc.ServiceArn) {
+					output.Services = append(output.Services, svc)
+					found = true
+				}
+			}
+			if !found {
+				output.Failures = append(output.Failures, &ecs.Failure{Arn: name, Reason: aws.String("MISSING")})
+			}
+		}
+		return output, nil
+	case "DescribeTaskDefinition":
+		input := &ecs.DescribeTaskDefinitionInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		if td := findTaskDefinition(state, aws.StringValue(input.TaskDefinition)); td != nil {
+			return &ecs.DescribeTaskDefinitionOutput{TaskDefinition: td}, nil
+		}
+		return nil, &apiError{code: "ClientException", message: "Unable to describe task definition.", status: 400}
+	case "ListTaskDefinitions":
+		input := &ecs.ListTaskDefinitionsInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		status := aws.StringValue(input.Status)
+		if status == "" {
+			status = ecs.TaskDefinitionStatusActive
+		}
+		matching := []*ecs.TaskDefinition{}
+		for _, td := range state.TaskDefinitions {
+			if aws.StringValue(td.Status) == status && strings.HasPrefix(aws.StringValue(td.Family), aws.StringValue(input.FamilyPrefix)) {
+				matching = append(matching, td)
+			}
+		}
+		slices.SortFunc(matching, func(i, j *ecs.TaskDefinition) int {
+			if c := strings.Compare(aws.StringValue(i.Family), aws.StringValue(j.Family)); c != 0 {
+				return c
+			}
+			return int(aws.Int64Value(i.Revision) - aws.Int64Value(j.Revision))
+		})
+		if aws.StringValue(input.Sort) == ecs.SortOrderDesc {
+			slices.Reverse(matching)
+		}
+		arns := []*string{}
+		for _, td := range matching {
+			arns = append(arns, td.TaskDefinitionArn)
+		}
+		start, end, next, err := s.paginate(len(arns), input.NextToken, input.MaxResults, 100)
+		if err != nil {
+			return nil, err
+		}
+		return &ecs.ListTaskDefinitionsOutput{TaskDefinitionArns: arns[start:end], NextToken: next}, nil
+	case "ListTasks":
+		input := &ecs.ListTasksInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		desiredStatus := aws.StringValue(input.DesiredStatus)
+		if desiredStatus == "" {
+			desiredStatus = ecs.DesiredStatusRunning
+		}
+		arns := []*string{}
+		for _, task := range state.Tasks {
+			switch {
+			case !inCluster(input.Cluster, task.ClusterArn),
+				aws.StringValue(task.DesiredStatus) != desiredStatus,
+				input.ServiceName != nil && aws.StringValue(task.Group) != "service:"+*input.ServiceName,
+				input.StartedBy != nil && aws.StringValue(task.StartedBy) != *input.StartedBy,
+				input.ContainerInstance != nil && !matches(*input.ContainerInstance, task.ContainerInstanceArn),
+				input.Family != nil && !strings.HasPrefix(aws.StringValue(task.Group), "family:"+*input.Family):
+				continue
+			}
+			arns = append(arns, task.TaskArn)
+		}
+		start, end, next, err := s.paginate(len(arns), input.NextToken, input.MaxResults, 100)
+		if err != nil {
+			return nil, err
+		}
+		return &ecs.ListTasksOutput{TaskArns: arns[start:end], NextToken: next}, nil
+	case "DescribeTasks":
+		input := &ecs.DescribeTasksInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		if len(input.Tasks) > 100 {
+			return nil, invalidParameter("Tasks can have at most 100 items")
+		}
+		output := &ecs.DescribeTasksOutput{Tasks: []*ecs.Task{}, Failures: []*ecs.Failure{}}
+		for _, arn := range input.Tasks {
+			found := false
+			for _, task := range state.Tasks {
+				if inCluster(input.Cluster, task.ClusterArn) && matches(aws.StringValue(arn), task.TaskArn) {
+					output.Tasks = append(output.Tasks, task)
+					found = true
+				}
+			}
+			if !found {
+				output.Failures = append(output.Failures, &ecs.Failure{Arn: arn, Reason: aws.String("MISSING")})
+			}
+		}
+		return output, nil
+	case "ListContainerInstances":
+		input := &ecs.ListContainerInstancesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		arns := []*string{}
+		for _, instance := range state.ContainerInstances {
+			if inCluster(input.Cluster, clusterOfContainerInstance(instance)) {
+				arns = append(arns, instance.ContainerInstanceArn)
+			}
+		}
+		start, end, next, err := s.paginate(len(arns), input.NextToken, input.MaxResults, 100)
+		if err != nil {
+			return nil, err
+		}
+		return &ecs.ListContainerInstancesOutput{ContainerInstanceArns: arns[start:end], NextToken: next}, nil
+	case "DescribeContainerInstances":
+		input := &ecs.DescribeContainerInstancesInput{}
+		if err := decode(body, input); err != nil {
+			return nil, err
+		}
+		if len(input.ContainerInstances) > 100 {
+			return nil, invalidParameter("ContainerInstances can have at most 100 items")
+		}
+		output := &ecs.DescribeContainerInstancesOutput{ContainerInstances: []*ecs.ContainerInstance{}, Failures: []*ecs.Failure{}}
+		for _, arn := range input.ContainerInstances {
+			found := false
+			for _, instance := range state.ContainerInstances {
+				if matches(aws.StringValue(arn), instance.ContainerInstanceArn) {
+					output.ContainerInstances = append(output.ContainerInstances, instance)
+					found = true
+				}
+			}
+			if !found {
+				output.Failures = append(output.Failures, &ecs.Failure{Arn: arn, Reason: aws.String("MISSING")})
+			}
+		}
+		return output, nil
+	}
+	return nil, unknownOperation(operation)
+}
+
+// inCluster reports whether a resource of clusterArn is in the cluster a
+// call asks for, the default cluster if it names none.
+func inCluster(cluster *string, clusterArn *string) bool {
+	name := aws.StringValue(cluster)
+	if name == "" {
+		name = defaultCluster
+	}
+	return matches(name, clusterArn)
+}
+
+// clusterOfContainerInstance derives the cluster from the container
+// instance ARN, arn:aws:ecs:region:account:container-instance/cluster/id.
+func clusterOfContainerInstance(instance *ecs.ContainerInstance) *string {
+	arn := aws.StringValue(instance.ContainerInstanceArn)
+	parts := strings.Split(arn, "/")
+	if len(parts) < 3 {
+		return aws.String("")
+	}
+	return aws.String(parts[len(parts)-2])
+}
+
+// findTaskDefinition resolves a task definition by ARN, family:revision or
+// family, the latest ACTIVE revision.
+func findTaskDefinition(state *Step, name string) *ecs.TaskDefinition {
+	var latest *ecs.TaskDefinition
+	for _, td := range state.TaskDefinitions {
+		arn := aws.StringValue(td.TaskDefinitionArn)
+		if name == arn || name == arn[strings.LastIndex(arn, "/")+1:] {
+			return td
+		}
+		if name == aws.StringValue(td.Family) && aws.StringValue(td.Status) == ecs.TaskDefinitionStatusActive &&
+			(latest == nil || aws.Int64Value(td.Revision) > aws.Int64Value(latest.Revision)) {
+			latest = td
+		}
+	}
+	return latest
+}
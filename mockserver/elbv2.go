@@ -0,0 +1,125 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+func (s *Server) elbv2(state *Step, action string, form url.Values) (interface{}, error) {
+	marker := formString(form, "Marker")
+	pageSize, err := formInt(form, "PageSize")
+	if err != nil {
+		return nil, err
+	}
+	switch action {
+	case "DescribeLoadBalancers":
+		arns, names := formList(form, "LoadBalancerArns"), formList(form, "Names")
+		lbs := []*elbv2.LoadBalancer{}
+		for _, lb := range state.LoadBalancers {
+			if (len(arns) == 0 || contains(arns, lb.LoadBalancerArn)) && (len(names) == 0 || contains(names, lb.LoadBalancerName)) {
+				lbs = append(lbs, lb)
+			}
+		}
+		start, end, next, err := s.paginate(len(lbs), marker, pageSize, 400)
+		if err != nil {
+			return nil, err
+		}
+		return &elbv2.DescribeLoadBalancersOutput{LoadBalancers: lbs[start:end], NextMarker: next}, nil
+	case "DescribeListeners":
+		lbArn, arns := formString(form, "LoadBalancerArn"), formList(form, "ListenerArns")
+		if lbArn == nil && len(arns) == 0 {
+			return nil, &apiError{code: "ValidationError", message: "a load balancer ARN or listener ARNs must be specified", status: 400}
+		}
+		listeners := []*elbv2.Listener{}
+		for _, listener := range state.Listeners {
+			if (lbArn == nil || aws.StringValue(listener.LoadBalancerArn) == *lbArn) && (len(arns) == 0 || contains(arns, listener.ListenerArn)) {
+				listeners = append(listeners, listener)
+			}
+		}
+		start, end, next, err := s.paginate(len(listeners), marker, pageSize, 400)
+		if err != nil {
+			return nil, err
+		}
+		return &elbv2.DescribeListenersOutput{Listeners: listeners[start:end], NextMarker: next}, nil
+	case "DescribeRules":
+		listenerArn := formString(form, "ListenerArn")
+		if listenerArn == nil {
+			return nil, &apiError{code: "ValidationError", message: "a listener ARN must be specified", status: 400}
+		}
+		rules := []*elbv2.Rule{}
+		for _, listener := range state.Rules {
+			if aws.StringValue(listener.ListenerArn) == *listenerArn {
+				rules = append(rules, listener.Rules...)
+			}
+		}
+		start, end, next, err := s.paginate(len(rules), marker, pageSize, 400)
+		if err != nil {
+			return nil, err
+		}
+		return &elbv2.DescribeRulesOutput{Rules: rules[start:end], NextMarker: next}, nil
+	case "DescribeTargetGroups":
+		arns, names := formList(form, "TargetGroupArns"), formList(form, "Names")
+		lbArn := formString(form, "LoadBalancerArn")
+		groups := []*elbv2.TargetGroup{}
+		for _, group := range state.TargetGroups {
+			if (len(arns) == 0 || contains(arns, group.TargetGroupArn)) && (len(names) == 0 || contains(names, group.TargetGroupName)) &&
+				(lbArn == nil || contains(group.LoadBalancerArns, lbArn)) {
+				groups = append(groups, group)
+			}
+		}
+		if len(arns) > 0 && len(groups) < len(arns) {
+			return nil, &apiError{code: elbv2.ErrCodeTargetGroupNotFoundException, message: "One or more target groups not found", status: 400}
+		}
+		start, end, next, err := s.paginate(len(groups), marker, pageSize, 400)
+		if err != nil {
+			return nil, err
+		}
+		return &elbv2.DescribeTargetGroupsOutput{TargetGroups: groups[start:end], NextMarker: next}, nil
+	case "DescribeTargetHealth":
+		arn := formString(form, "TargetGroupArn")
+		for _, health := range state.TargetHealth {
+			if aws.StringValue(health.TargetGroupArn) == aws.StringValue(arn) {
+				return &elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: health.TargetHealthDescriptions}, nil
+			}
+		}
+		for _, group := range state.TargetGroups {
+			if aws.StringValue(group.TargetGroupArn) == aws.StringValue(arn) {
+				return &elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: []*elbv2.TargetHealthDescription{}}, nil
+			}
+		}
+		return nil, &apiError{code: elbv2.ErrCodeTargetGroupNotFoundException, message: fmt.Sprintf("Target group '%s' not found", aws.StringValue(arn)), status: 400}
+	}
+	return nil, &apiError{code: "InvalidAction", message: action + " is not implemented by the mock server", status: 400}
+}
+
+func formString(form url.Values, name string) *string {
+	if !form.Has(name) {
+		return nil
+	}
+	return aws.String(form.Get(name))
+}
+
+func formInt(form url.Values, name string) (*int64, error) {
+	if !form.Has(name) {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(form.Get(name), 10, 64)
+	if err != nil {
+		return nil, &apiError{code: "ValidationError", message: fmt.Sprintf("%s: %v", name, err), status: 400}
+	}
+	return &value, nil
+}
+
+// formList reads a query protocol list, sent as Name.member.1, Name.member.2
+// and so on.
+func formList(form url.Values, name string) []*string {
+	values := []*string{}
+	for i := 1; form.Has(fmt.Sprintf("%s.member.%d", name, i)); i++ {
+		values = append(values, aws.String(form.Get(fmt.Sprintf("%s.member.%d", name, i))))
+	}
+	return values
+}
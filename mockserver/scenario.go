@@ -0,0 +1,141 @@
+// Package mockserver serves a scripted ECS account over the JSON and query
+// protocols of the ECS, Application Auto Scaling and ELBv2 endpoints, so
+// ecstui can be run end to end against it with --endpoint.
+package mockserver
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	autoscaling "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// Scenario is the file the server is driven by. The account starts in the
+// first step and moves to the next one every StepInterval, staying in the
+// last one, e.g. to play a blue/green rollout shifting traffic step by step.
+//
+// Resources are written the way the AWS CLI prints them, so the output of
+// `aws ecs describe-services` and friends can be pasted in; keys are matched
+// case-insensitively and timestamps may be ISO 8601 strings or epoch seconds.
+type Scenario struct {
+	_ struct{} `type:"structure"`
+
+	// StepInterval is a duration such as "10s", it defaults to 10 seconds.
+	StepInterval *string `locationName:"stepInterval" type:"string"`
+	// PageSize caps the results of paginated calls, so small scenarios still
+	// exercise pagination. Zero keeps the AWS defaults.
+	PageSize *int64 `locationName:"pageSize" type:"integer"`
+	// ThrottleEvery fails every nth request with a throttling error.
+	ThrottleEvery *int64  `locationName:"throttleEvery" type:"integer"`
+	Steps         []*Step `locationName:"steps" type:"list"`
+}
+
+// Step is the state of the account while the step is active. Collections a
+// step leaves out are carried over from the previous step, so later steps
+// only list what changed.
+type Step struct {
+	_ struct{} `type:"structure"`
+
+	Clusters           []*ecs.Cluster           `locationName:"clusters" type:"list"`
+	Services           []*ecs.Service           `locationName:"services" type:"list"`
+	Tasks              []*ecs.Task              `locationName:"tasks" type:"list"`
+	TaskDefinitions    []*ecs.TaskDefinition    `locationName:"taskDefinitions" type:"list"`
+	ContainerInstances []*ecs.ContainerInstance `locationName:"containerInstances" type:"list"`
+
+	LoadBalancers []*elbv2.LoadBalancer `locationName:"loadBalancers" type:"list"`
+	Listeners     []*elbv2.Listener     `locationName:"listeners" type:"list"`
+	Rules         []*ListenerRules      `locationName:"rules" type:"list"`
+	TargetGroups  []*elbv2.TargetGroup  `locationName:"targetGroups" type:"list"`
+	TargetHealth  []*TargetGroupHealth  `locationName:"targetHealth" type:"list"`
+
+	ScalableTargets   []*autoscaling.ScalableTarget  `locationName:"scalableTargets" type:"list"`
+	ScalingPolicies   []*autoscaling.ScalingPolicy   `locationName:"scalingPolicies" type:"list"`
+	ScheduledActions  []*autoscaling.ScheduledAction `locationName:"scheduledActions" type:"list"`
+	ScalingActivities []*autoscaling.ScalingActivity `locationName:"scalingActivities" type:"list"`
+}
+
+// ListenerRules are the rules of a listener, rules do not carry the ARN of
+// their listener themselves.
+type ListenerRules struct {
+	_ struct{} `type:"structure"`
+
+	ListenerArn *string       `locationName:"listenerArn" type:"string"`
+	Rules       []*elbv2.Rule `locationName:"rules" type:"list"`
+}
+
+// TargetGroupHealth is what DescribeTargetHealth returns for a target group.
+type TargetGroupHealth struct {
+	_ struct{} `type:"structure"`
+
+	TargetGroupArn           *string                          `locationName:"targetGroupArn" type:"string"`
+	TargetHealthDescriptions []*elbv2.TargetHealthDescription `locationName:"targetHealthDescriptions" type:"list"`
+}
+
+const defaultStepInterval = 10 * time.Second
+
+// Load reads a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario := &Scenario{}
+	if err := unmarshalJSON(data, scenario); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(scenario.Steps) == 0 {
+		return nil, fmt.Errorf("%s: no steps", path)
+	}
+	return scenario, nil
+}
+
+func (s *Scenario) interval() (time.Duration, error) {
+	if s.StepInterval == nil {
+		return defaultStepInterval, nil
+	}
+	interval, err := time.ParseDuration(*s.StepInterval)
+	if err != nil {
+		return 0, fmt.Errorf("stepInterval: %w", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("stepInterval must be positive")
+	}
+	return interval, nil
+}
+
+// states resolves every step to the full state of the account, filling in
+// the collections carried over from earlier steps.
+func (s *Scenario) states() []Step {
+	states := make([]Step, len(s.Steps))
+	for i, step := range s.Steps {
+		if i > 0 {
+			states[i] = states[i-1]
+		}
+		state := &states[i]
+		carry(&state.Clusters, step.Clusters)
+		carry(&state.Services, step.Services)
+		carry(&state.Tasks, step.Tasks)
+		carry(&state.TaskDefinitions, step.TaskDefinitions)
+		carry(&state.ContainerInstances, step.ContainerInstances)
+		carry(&state.LoadBalancers, step.LoadBalancers)
+		carry(&state.Listeners, step.Listeners)
+		carry(&state.Rules, step.Rules)
+		carry(&state.TargetGroups, step.TargetGroups)
+		carry(&state.TargetHealth, step.TargetHealth)
+		carry(&state.ScalableTargets, step.ScalableTargets)
+		carry(&state.ScalingPolicies, step.ScalingPolicies)
+		carry(&state.ScheduledActions, step.ScheduledActions)
+		carry(&state.ScalingActivities, step.ScalingActivities)
+	}
+	return states
+}
+
+// carry replaces a collection with the step's version if the step sets it.
+func carry[T any](collection *[]T, step []T) {
+	if step != nil {
+		*collection = step
+	}
+}
@@ -0,0 +1,252 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	ecsTargetPrefix         = "AmazonEC2ContainerServiceV20141113"
+	autoscalingTargetPrefix = "AnyScaleFrontendService"
+	elbv2Version            = "2015-12-01"
+	elbv2Namespace          = "http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/"
+)
+
+// apiError is returned to the client in the error format of the protocol.
+type apiError struct {
+	code    string
+	message string
+	status  int
+}
+
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+func invalidParameter(format string, args ...interface{}) *apiError {
+	return &apiError{code: "InvalidParameterException", message: fmt.Sprintf(format, args...), status: http.StatusBadRequest}
+}
+
+// Server serves a scenario. Only the calls ecstui makes to read a service are
+// implemented, every other call fails with an unknown operation error.
+type Server struct {
+	states        []Step
+	interval      time.Duration
+	pageSize      int
+	throttleEvery int
+	// Logf, if set, is called for every request.
+	Logf func(format string, args ...interface{})
+
+	mu       sync.Mutex
+	start    time.Time
+	now      func() time.Time
+	requests int
+}
+
+func New(scenario *Scenario) (*Server, error) {
+	interval, err := scenario.interval()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		states:        scenario.states(),
+		interval:      interval,
+		pageSize:      int(aws.Int64Value(scenario.PageSize)),
+		throttleEvery: int(aws.Int64Value(scenario.ThrottleEvery)),
+		start:         time.Now(),
+		now:           time.Now,
+	}, nil
+}
+
+// Step is the index of the active step.
+func (s *Server) Step() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.step()
+}
+
+func (s *Server) step() int {
+	step := int(s.now().Sub(s.start) / s.interval)
+	return min(step, len(s.states)-1)
+}
+
+// next counts the request and returns the state it is served from, or false
+// if it is to be throttled.
+func (s *Server) next() (*Step, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.throttleEvery > 0 && s.requests%s.throttleEvery == 0 {
+		return nil, false
+	}
+	return &s.states[s.step()], true
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		s.serveJSON(w, target, body)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.serveQuery(w, form)
+}
+
+// serveJSON serves the JSON protocol of ECS and Application Auto Scaling,
+// where the operation is named by the X-Amz-Target header.
+func (s *Server) serveJSON(w http.ResponseWriter, target string, body []byte) {
+	prefix, operation, _ := strings.Cut(target, ".")
+	state, ok := s.next()
+	s.logf("step %d %s %s throttled=%t", s.Step(), prefix, operation, !ok)
+
+	var output interface{}
+	var err error
+	switch {
+	case !ok:
+		err = &apiError{code: "ThrottlingException", message: "Rate exceeded", status: http.StatusBadRequest}
+	case prefix == ecsTargetPrefix:
+		output, err = s.ecs(state, operation, body)
+	case prefix == autoscalingTargetPrefix:
+		output, err = s.autoscaling(state, operation, body)
+	default:
+		err = &apiError{code: "UnknownOperationException", message: "unknown target " + target, status: http.StatusBadRequest}
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	if err != nil {
+		apiErr := toAPIError(err)
+		w.WriteHeader(apiErr.status)
+		json.NewEncoder(w).Encode(map[string]string{"__type": apiErr.code, "message": apiErr.message})
+		return
+	}
+	response, err := marshalJSON(output)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(response)
+}
+
+// serveQuery serves the query protocol of ELBv2, where the operation is the
+// Action form value and responses are XML.
+func (s *Server) serveQuery(w http.ResponseWriter, form url.Values) {
+	action := form.Get("Action")
+	state, ok := s.next()
+	s.logf("step %d elasticloadbalancing %s throttled=%t", s.Step(), action, !ok)
+
+	var output interface{}
+	var err error
+	switch {
+	case !ok:
+		err = &apiError{code: "Throttling", message: "Rate exceeded", status: http.StatusBadRequest}
+	case form.Get("Version") == elbv2Version:
+		output, err = s.elbv2(state, action, form)
+	default:
+		err = &apiError{code: "InvalidAction", message: fmt.Sprintf("unknown action %s of version %s", action, form.Get("Version")), status: http.StatusBadRequest}
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	var response bytes.Buffer
+	if err != nil {
+		apiErr := toAPIError(err)
+		w.WriteHeader(apiErr.status)
+		fmt.Fprintf(&response, "<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>", apiErr.code)
+		xml.EscapeText(&response, []byte(apiErr.message))
+		response.WriteString("</Message></Error><RequestId>mock</RequestId></ErrorResponse>")
+		w.Write(response.Bytes())
+		return
+	}
+	fmt.Fprintf(&response, `<%sResponse xmlns="%s"><%sResult>`, action, elbv2Namespace, action)
+	if err := writeXML(xml.NewEncoder(&response), output); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(&response, "</%sResult><ResponseMetadata><RequestId>mock</RequestId></ResponseMetadata></%sResponse>", action, action)
+	w.Write(response.Bytes())
+}
+
+func toAPIError(err error) *apiError {
+	if apiErr, ok := err.(*apiError); ok {
+		return apiErr
+	}
+	return &apiError{code: "InternalFailure", message: err.Error(), status: http.StatusInternalServerError}
+}
+
+func unknownOperation(operation string) error {
+	return &apiError{code: "UnknownOperationException", message: operation + " is not implemented by the mock server", status: http.StatusBadRequest}
+}
+
+func decode(body []byte, input interface{}) error {
+	if len(body) == 0 {
+		return nil
+	}
+	if err := unmarshalJSON(body, input); err != nil {
+		return invalidParameter("%v", err)
+	}
+	return nil
+}
+
+// paginate returns the page of items starting at token, an offset encoded
+// by the previous page. The page holds at most max items, the server's page
+// size or defaultSize, whichever is set and smallest.
+func (s *Server) paginate(count int, token *string, max *int64, defaultSize int) (start, end int, next *string, err error) {
+	size := defaultSize
+	if s.pageSize > 0 && s.pageSize < size {
+		size = s.pageSize
+	}
+	if max != nil && *max > 0 && int(*max) < size {
+		size = int(*max)
+	}
+	if token != nil && *token != "" {
+		start, err = strconv.Atoi(*token)
+		if err != nil || start < 0 || start > count {
+			return 0, 0, nil, invalidParameter("invalid pagination token %q", *token)
+		}
+	}
+	end = min(start+size, count)
+	if end < count {
+		next = aws.String(strconv.Itoa(end))
+	}
+	return start, end, next, nil
+}
+
+// matches reports whether value names the resource, by ARN or by the last
+// part of it.
+func matches(value string, arn *string) bool {
+	resource := aws.StringValue(arn)
+	return value == resource || value == resource[strings.LastIndex(resource, "/")+1:]
+}
+
+func matchesAny(values []*string, arn *string) bool {
+	for _, value := range values {
+		if matches(aws.StringValue(value), arn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// Package record captures the AWS API calls of a session to a file and
+// serves them back, so a TUI session can be replayed without credentials.
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/mtyurt/ecstui/logger"
+)
+
+// ErrCodeNotRecorded is the error code of calls a replay has no response for.
+const ErrCodeNotRecorded = "NotRecorded"
+
+// Interaction is one API call with its outcome. Params and Response are the
+// SDK input and output structs encoded as JSON.
+type Interaction struct {
+	Time      time.Time       `json:"time"`
+	Service   string          `json:"service"`
+	Region    string          `json:"region"`
+	Operation string          `json:"operation"`
+	Params    json.RawMessage `json:"params"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+// Error is a failed call's AWS error.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// key identifies the calls a recorded response can be served to.
+func (i Interaction) key() string {
+	return i.Service + " " + i.Operation + " " + string(i.Params)
+}
+
+// Recorder appends every completed call as a JSON line to a file.
+type Recorder struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// Create truncates or creates the recording at path. It holds responses
+// verbatim, so it is only readable by the user.
+func Create(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f}, nil
+}
+
+// Attach records the calls of every client created from handlers afterwards,
+// e.g. a session's.
+func (r *Recorder) Attach(handlers *request.Handlers) {
+	handlers.Complete.PushBackNamed(request.NamedHandler{Name: "ecstui.record", Fn: r.record})
+}
+
+// Close closes the recording file.
+func (r *Recorder) Close() error {
+	return r.f.Close()
+}
+
+func (r *Recorder) record(req *request.Request) {
+	interaction, err := newInteraction(req)
+	if err == nil {
+		var line []byte
+		line, err = json.Marshal(interaction)
+		if err == nil {
+			r.mu.Lock()
+			_, err = r.f.Write(append(line, '\n'))
+			r.mu.Unlock()
+		}
+	}
+	// the call itself went through, a gap in the recording must not fail it
+	if err != nil {
+		logger.Printf("recording %s: %v", req.Operation.Name, err)
+	}
+}
+
+func newInteraction(req *request.Request) (Interaction, error) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return Interaction{}, err
+	}
+	interaction := Interaction{
+		Time:      time.Now(),
+		Service:   req.ClientInfo.ServiceName,
+		Region:    aws.StringValue(req.Config.Region),
+		Operation: req.Operation.Name,
+		Params:    params,
+	}
+	if req.Error != nil {
+		interaction.Error = &Error{Message: req.Error.Error()}
+		if awsErr, ok := req.Error.(awserr.Error); ok {
+			interaction.Error = &Error{Code: awsErr.Code(), Message: awsErr.Message()}
+		}
+		return interaction, nil
+	}
+	interaction.Response, err = json.Marshal(req.Data)
+	return interaction, err
+}
+
+// Replayer serves recorded responses instead of calling AWS. Calls are
+// matched by service, operation and input; repeated calls get the recorded
+// responses in order, and the last one once they run out, so a refresh loop
+// keeps showing the final recorded state.
+type Replayer struct {
+	mu     sync.Mutex
+	region string
+	calls  map[string][]Interaction
+	served map[string]int
+}
+
+// Load reads a recording made by a Recorder.
+func Load(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses a recording from r.
+func Read(r io.Reader) (*Replayer, error) {
+	replayer := &Replayer{calls: map[string][]Interaction{}, served: map[string]int{}}
+	scanner := bufio.NewScanner(r)
+	// responses of large services easily exceed the default line limit
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if replayer.region == "" {
+			replayer.region = interaction.Region
+		}
+		key := interaction.key()
+		replayer.calls[key] = append(replayer.calls[key], interaction)
+	}
+	return replayer, scanner.Err()
+}
+
+// Region is the region of the first recorded call, clients need one even
+// though nothing is sent.
+func (r *Replayer) Region() string {
+	return r.region
+}
+
+// Attach replaces sending, response parsing and retries of every client
+// created from handlers afterwards with serving the recording.
+func (r *Replayer) Attach(handlers *request.Handlers) {
+	handlers.Send.Clear()
+	handlers.ValidateResponse.Clear()
+	handlers.UnmarshalMeta.Clear()
+	handlers.Unmarshal.Clear()
+	handlers.UnmarshalError.Clear()
+	handlers.Retry.Clear()
+	handlers.AfterRetry.Clear()
+	handlers.Send.PushBackNamed(request.NamedHandler{Name: "ecstui.replay", Fn: r.serve})
+}
+
+func (r *Replayer) serve(req *request.Request) {
+	req.HTTPResponse = &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}
+	req.Retryable = aws.Bool(false)
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		req.Error = err
+		return
+	}
+	interaction, ok := r.next(Interaction{Service: req.ClientInfo.ServiceName, Operation: req.Operation.Name, Params: params}.key())
+	if !ok {
+		req.Error = awserr.New(ErrCodeNotRecorded, fmt.Sprintf("no recorded response for %s %s %s", req.ClientInfo.ServiceName, req.Operation.Name, params), nil)
+		return
+	}
+	if interaction.Error != nil {
+		req.Error = awserr.New(interaction.Error.Code, interaction.Error.Message, nil)
+		return
+	}
+	if err := json.Unmarshal(interaction.Response, req.Data); err != nil {
+		req.Error = fmt.Errorf("replaying %s: %w", req.Operation.Name, err)
+	}
+}
+
+func (r *Replayer) next(key string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls[key]
+	if len(calls) == 0 {
+		return Interaction{}, false
+	}
+	i := r.served[key]
+	if i < len(calls)-1 {
+		r.served[key] = i + 1
+	}
+	return calls[i], true
+}
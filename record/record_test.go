@@ -0,0 +1,143 @@
+package record
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func newSession(t *testing.T) *session.Session {
+	t.Helper()
+	sess, err := session.NewSession(aws.NewConfig().
+		WithRegion("eu-west-1").
+		WithCredentials(credentials.AnonymousCredentials))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+// fakeAWS answers the calls of clients created from sess: ListClusters with
+// one more cluster each time, DescribeClusters with a not found error.
+func fakeAWS(sess *session.Session) {
+	calls := 0
+	sess.Handlers.Send.Clear()
+	sess.Handlers.ValidateResponse.Clear()
+	sess.Handlers.UnmarshalMeta.Clear()
+	sess.Handlers.Unmarshal.Clear()
+	sess.Handlers.UnmarshalError.Clear()
+	sess.Handlers.Retry.Clear()
+	sess.Handlers.AfterRetry.Clear()
+	sess.Handlers.Send.PushBack(func(req *request.Request) {
+		req.HTTPResponse = &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}
+		switch out := req.Data.(type) {
+		case *ecs.ListClustersOutput:
+			calls++
+			for i := 1; i <= calls; i++ {
+				out.ClusterArns = append(out.ClusterArns, aws.String(fmt.Sprintf("cluster-%d", i)))
+			}
+		case *ecs.DescribeClustersOutput:
+			req.Error = awserr.New(ecs.ErrCodeClusterNotFoundException, "Cluster not found.", nil)
+		}
+	})
+}
+
+func listClusters(t *testing.T, client *ecs.ECS) string {
+	t.Helper()
+	out, err := client.ListClusters(&ecs.ListClustersInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(aws.StringValueSlice(out.ClusterArns), " ")
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := newSession(t)
+	fakeAWS(sess)
+	recorder.Attach(&sess.Handlers)
+	live := ecs.New(sess)
+	listClusters(t, live)
+	listClusters(t, live)
+	if _, err := live.DescribeClusters(&ecs.DescribeClustersInput{Clusters: aws.StringSlice([]string{"demo"})}); err == nil {
+		t.Fatal("DescribeClusters succeeded, want the fake's error")
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := replayer.Region(); got != "eu-west-1" {
+		t.Errorf("region = %q, want eu-west-1", got)
+	}
+	sess = newSession(t)
+	replayer.Attach(&sess.Handlers)
+	replayed := ecs.New(sess)
+
+	// served in the recorded order, then the last one over and over
+	for i, want := range []string{"cluster-1", "cluster-1 cluster-2", "cluster-1 cluster-2", "cluster-1 cluster-2"} {
+		if got := listClusters(t, replayed); got != want {
+			t.Errorf("ListClusters #%d = %q, want %q", i+1, got, want)
+		}
+	}
+
+	_, err = replayed.DescribeClusters(&ecs.DescribeClustersInput{Clusters: aws.StringSlice([]string{"demo"})})
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != ecs.ErrCodeClusterNotFoundException || awsErr.Message() != "Cluster not found." {
+		t.Errorf("DescribeClusters error = %v, want the recorded one", err)
+	}
+}
+
+func TestReplayNotRecorded(t *testing.T) {
+	replayer, err := Read(strings.NewReader(`{"service":"ecs","region":"eu-west-1","operation":"ListClusters","params":{"MaxResults":null,"NextToken":null},"response":{"clusterArns":["cluster-1"]}}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := newSession(t)
+	replayer.Attach(&sess.Handlers)
+	client := ecs.New(sess)
+
+	if got := listClusters(t, client); got != "cluster-1" {
+		t.Errorf("ListClusters = %q, want cluster-1", got)
+	}
+	// same operation, other input
+	_, err = client.ListClusters(&ecs.ListClustersInput{NextToken: aws.String("page-2")})
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != ErrCodeNotRecorded {
+		t.Errorf("ListClusters with another input: error = %v, want %s", err, ErrCodeNotRecorded)
+	}
+	_, err = client.ListServices(&ecs.ListServicesInput{})
+	if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != ErrCodeNotRecorded {
+		t.Errorf("ListServices error = %v, want %s", err, ErrCodeNotRecorded)
+	}
+}
+
+func TestRecordWriteFailureKeepsCall(t *testing.T) {
+	recorder, err := Create(filepath.Join(t.TempDir(), "session.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// writes to a closed file fail
+	recorder.Close()
+	sess := newSession(t)
+	fakeAWS(sess)
+	recorder.Attach(&sess.Handlers)
+
+	if got := listClusters(t, ecs.New(sess)); got != "cluster-1" {
+		t.Errorf("ListClusters = %q, want cluster-1", got)
+	}
+}
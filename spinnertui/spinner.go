@@ -6,9 +6,11 @@ package spinnertui
 import (
 	"fmt"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
 )
 
 type errMsg error
@@ -23,7 +25,7 @@ type Model struct {
 func New(loadingMsg string) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	s.Style = theme.Current.Highlight
 	return Model{spinner: s, loadingMsg: loadingMsg}
 }
 
@@ -38,13 +40,11 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "esc", "ctrl+c":
+		if key.Matches(msg, keys.Map.Global.Quit, keys.Map.Global.Back, keys.Map.Global.ForceQuit) {
 			m.quitting = true
 			return m, tea.Quit
-		default:
-			return m, nil
 		}
+		return m, nil
 
 	case errMsg:
 		m.err = msg
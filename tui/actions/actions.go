@@ -0,0 +1,482 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/audit"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// Writers are the AWS calls write actions are made with. The service screen
+// only gets them with --allow-writes.
+type Writers struct {
+	UpdateService               func(input *ecs.UpdateServiceInput) error
+	StopTask                    func(input *ecs.StopTaskInput) error
+	UpdateServicePrimaryTaskSet func(input *ecs.UpdateServicePrimaryTaskSetInput) error
+	DeleteTaskSet               func(input *ecs.DeleteTaskSetInput) error
+	ModifyRule                  func(input *elbv2.ModifyRuleInput) error
+	ModifyListener              func(input *elbv2.ModifyListenerInput) error
+	ExecuteCommand              func(input *ecs.ExecuteCommandInput) (*ecs.ExecuteCommandOutput, error)
+	// SessionManager builds the session-manager-plugin command attaching the
+	// terminal to an ExecuteCommand session.
+	SessionManager func(output *ecs.ExecuteCommandOutput, target string) (*exec.Cmd, error)
+}
+
+// Request is a write API call waiting for confirmation. Input is shown as
+// is, it is the exact input Run calls the API with. Preview, if set, is a
+// dry run of what the call changes. WatchDeployment asks the service screen
+// to follow the deployment the call starts. Next, if set, runs once the call
+// succeeded.
+type Request struct {
+	Title           string
+	Operation       string
+	Input           fmt.Stringer
+	Preview         []string
+	Run             func() error
+	Next            func() tea.Cmd
+	WatchDeployment bool
+}
+
+// ForceNewDeployment replaces every task of the service with the same task
+// definition.
+func ForceNewDeployment(writers Writers, service *ecs.Service) Request {
+	input := &ecs.UpdateServiceInput{
+		Cluster:            service.ClusterArn,
+		Service:            service.ServiceName,
+		ForceNewDeployment: aws.Bool(true),
+	}
+	return Request{
+		Title:     "force new deployment",
+		Operation: "ecs:UpdateService",
+		Input:     input,
+		Run:       func() error { return writers.UpdateService(input) },
+	}
+}
+
+// UpdateDesiredCount sets the desired count of the service.
+func UpdateDesiredCount(writers Writers, service *ecs.Service, count int64) Request {
+	input := &ecs.UpdateServiceInput{
+		Cluster:      service.ClusterArn,
+		Service:      service.ServiceName,
+		DesiredCount: aws.Int64(count),
+	}
+	return Request{
+		Title:     fmt.Sprintf("update desired count %d → %d", aws.Int64Value(service.DesiredCount), count),
+		Operation: "ecs:UpdateService",
+		Input:     input,
+		Run:       func() error { return writers.UpdateService(input) },
+	}
+}
+
+// StopTask stops a single task, the service starts a replacement.
+func StopTask(writers Writers, task *ecs.Task) Request {
+	input := &ecs.StopTaskInput{
+		Cluster: task.ClusterArn,
+		Task:    task.TaskArn,
+		Reason:  aws.String("stopped from ecstui"),
+	}
+	return Request{
+		Title:     "stop task " + utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/"),
+		Operation: "ecs:StopTask",
+		Input:     input,
+		Run:       func() error { return writers.StopTask(input) },
+	}
+}
+
+// DoneMsg is sent after an action succeeded, the parent view refreshes what
+// it shows.
+type DoneMsg struct {
+	Request Request
+}
+
+type resultMsg struct {
+	err      error
+	auditErr error
+}
+
+type stage int
+
+const (
+	choosing stage = iota
+	entering
+	confirming
+	running
+	done
+)
+
+// item is a menu entry. Entries with options open them as a nested menu,
+// entries with a prompt ask for a value first, value being the default, and
+// build their request from it. disabled explains why the entry cannot be
+// chosen.
+type item struct {
+	label    string
+	prompt   string
+	value    string
+	request  func(value string) (Request, error)
+	options  []item
+	disabled error
+}
+
+type Model struct {
+	log     *audit.Log
+	cluster string
+	service string
+	items   []item
+	cursor  int
+	// parents are the menus above the current one, with their cursors.
+	parents       [][]item
+	parentCursors []int
+	input         textinput.Model
+	// inputErr is why the entered value or the chosen entry gave no request.
+	inputErr      error
+	request       *Request
+	result        resultMsg
+	session       *sessionEndedMsg
+	stage         stage
+	spinner       spinnertui.Model
+	width, height int
+}
+
+func newModel(log *audit.Log, service *ecs.Service, width, height int) Model {
+	input := textinput.New()
+	input.CharLimit = 6
+	m := Model{
+		log:     log,
+		cluster: utils.GetLastItemAfterSplit(aws.StringValue(service.ClusterArn), "/"),
+		service: aws.StringValue(service.ServiceName),
+		input:   input,
+		spinner: spinnertui.New("Waiting for AWS"),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+// NewMenu lists the actions that can be taken on the service. conns are the
+// load balancer connections of its task sets, offered for weight shifting.
+func NewMenu(writers Writers, log *audit.Log, service *ecs.Service, conns []types.ConnectionConfig, width, height int) Model {
+	m := newModel(log, service, width, height)
+	m.items = []item{
+		{
+			label:   "force new deployment",
+			request: func(string) (Request, error) { return ForceNewDeployment(writers, service), nil },
+		},
+		{
+			label:  "update desired count",
+			prompt: fmt.Sprintf("desired count (now %d): ", aws.Int64Value(service.DesiredCount)),
+			request: func(value string) (Request, error) {
+				count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				if err != nil || count < 0 {
+					return Request{}, fmt.Errorf("desired count must be a non-negative number")
+				}
+				return UpdateDesiredCount(writers, service, count), nil
+			},
+		},
+	}
+	m.items = append(m.items, taskSetItems(writers, service, conns)...)
+	return m
+}
+
+// NewConfirm asks to confirm a single request, without a menu to go back to.
+func NewConfirm(request Request, log *audit.Log, service *ecs.Service, width, height int) Model {
+	m := newModel(log, service, width, height)
+	m.request = &request
+	m.stage = confirming
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.input.Width = max(width-40, 10)
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Focused reports whether esc should close the view. esc steps back to the
+// menu first, and is ignored while a call is in flight.
+func (m Model) Focused() bool {
+	switch m.stage {
+	case choosing:
+		return len(m.parents) == 0
+	case done:
+		return true
+	case confirming:
+		return len(m.items) == 0
+	}
+	return false
+}
+
+// errNotCalled is the result of a call that was not made because its intent
+// could not be recorded.
+var errNotCalled = errors.New("not called, the action could not be recorded")
+
+// run records the intent to make the call, makes it and records its outcome.
+// Without a record of the intent the call is not made.
+func (m Model) run() tea.Msg {
+	request := *m.request
+	// the input is logged the way the API receives it, without unset fields
+	input, err := audit.Input(request.Input)
+	if err != nil {
+		return resultMsg{err: errNotCalled, auditErr: err}
+	}
+	entry := audit.Entry{
+		Time:      time.Now().UTC(),
+		Phase:     audit.Intent,
+		Cluster:   m.cluster,
+		Service:   m.service,
+		Operation: request.Operation,
+		Input:     input,
+	}
+	if err := m.log.Record(entry); err != nil {
+		return resultMsg{err: errNotCalled, auditErr: err}
+	}
+
+	logger.Println("running", request.Operation, request.Input)
+	err = request.Run()
+	entry.Time = time.Now().UTC()
+	entry.Phase = audit.Outcome
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return resultMsg{err: err, auditErr: m.log.Record(entry)}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case resultMsg:
+		m.result = msg
+		m.stage = done
+		if msg.err == nil {
+			request := *m.request
+			cmds := []tea.Cmd{func() tea.Msg { return DoneMsg{Request: request} }}
+			if request.Next != nil {
+				cmds = append(cmds, request.Next())
+			}
+			return m, tea.Batch(cmds...)
+		}
+		return m, nil
+	case sessionEndedMsg:
+		m.session = &msg
+		return m, nil
+	case tea.KeyMsg:
+		switch m.stage {
+		case choosing:
+			m.inputErr = nil
+			switch {
+			case key.Matches(msg, keys.Map.Common.Up):
+				m.cursor = max(m.cursor-1, 0)
+			case key.Matches(msg, keys.Map.Common.Down):
+				m.cursor = min(m.cursor+1, len(m.items)-1)
+			case key.Matches(msg, keys.Map.Global.Back):
+				if len(m.parents) > 0 {
+					last := len(m.parents) - 1
+					m.items, m.cursor = m.parents[last], m.parentCursors[last]
+					m.parents, m.parentCursors = m.parents[:last], m.parentCursors[:last]
+				}
+			case key.Matches(msg, keys.Map.Actions.Choose):
+				if len(m.items) == 0 {
+					return m, nil
+				}
+				selected := m.items[m.cursor]
+				if selected.disabled != nil {
+					return m, nil
+				}
+				if len(selected.options) > 0 {
+					m.parents = append(m.parents, m.items)
+					m.parentCursors = append(m.parentCursors, m.cursor)
+					m.items, m.cursor = selected.options, 0
+					return m, nil
+				}
+				if selected.prompt != "" {
+					m.input.Prompt = selected.prompt
+					m.input.SetValue(selected.value)
+					m.input.CursorEnd()
+					m.inputErr = nil
+					m.stage = entering
+					return m, m.input.Focus()
+				}
+				request, err := selected.request("")
+				if err != nil {
+					m.inputErr = err
+					return m, nil
+				}
+				m.request = &request
+				m.stage = confirming
+			}
+			return m, nil
+		case entering:
+			switch {
+			case key.Matches(msg, keys.Map.Global.Back):
+				m.input.Blur()
+				m.inputErr = nil
+				m.stage = choosing
+				return m, nil
+			case key.Matches(msg, keys.Map.Actions.Choose):
+				request, err := m.items[m.cursor].request(m.input.Value())
+				if err != nil {
+					m.inputErr = err
+					return m, nil
+				}
+				m.input.Blur()
+				m.request = &request
+				m.stage = confirming
+				return m, nil
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		case confirming:
+			switch {
+			case key.Matches(msg, keys.Map.Actions.Confirm):
+				m.stage = running
+				return m, tea.Batch(m.run, m.spinner.SpinnerTick())
+			case key.Matches(msg, keys.Map.Actions.Cancel, keys.Map.Global.Back):
+				if len(m.items) > 0 {
+					m.request = nil
+					m.stage = choosing
+				}
+			}
+			return m, nil
+		}
+	}
+
+	if m.stage == running {
+		m.spinner, cmd = m.spinner.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderMenu() []string {
+	lines := []string{}
+	for i, item := range m.items {
+		label := item.label
+		if item.disabled != nil {
+			label = theme.Current.Subtle.Render(label)
+		}
+		if i == m.cursor {
+			lines = append(lines, theme.Current.Selected.Render("> "+item.label))
+		} else {
+			lines = append(lines, "  "+label)
+		}
+	}
+	if len(m.items) > 0 && m.items[m.cursor].disabled != nil {
+		lines = append(lines, "", theme.Current.Warning.Render("⚠ "+m.items[m.cursor].disabled.Error()))
+	}
+	return lines
+}
+
+func (m Model) renderRequest() []string {
+	lines := []string{
+		theme.Current.Warning.Render(fmt.Sprintf("⚠ %s changes the live service %s in %s", m.request.Title, m.service, m.cluster)),
+	}
+	if len(m.request.Preview) > 0 {
+		lines = append(lines, "", theme.Current.Call.Render("dry run"))
+		lines = append(lines, m.request.Preview...)
+	}
+	return append(lines,
+		"",
+		"this calls "+theme.Current.Call.Render(m.request.Operation)+" with",
+		m.request.Input.String(),
+	)
+}
+
+func (m Model) renderResult() []string {
+	lines := []string{}
+	if m.result.err != nil {
+		lines = append(lines, theme.Current.Failed.Render(fmt.Sprintf("✗ %s failed: %v", m.request.Operation, m.result.err)))
+	} else {
+		lines = append(lines, theme.Current.OK.Render(fmt.Sprintf("✓ %s succeeded", m.request.Operation)))
+	}
+	if m.session != nil {
+		if m.session.err != nil {
+			lines = append(lines, theme.Current.Failed.Render(fmt.Sprintf("✗ session: %v", m.session.err)))
+		} else {
+			lines = append(lines, theme.Current.OK.Render("✓ session ended"))
+		}
+	}
+	if m.result.auditErr != nil {
+		lines = append(lines, theme.Current.Failed.Render(fmt.Sprintf("✗ could not record the action to %s: %v", m.log.Path(), m.result.auditErr)))
+	} else {
+		lines = append(lines, theme.Current.Subtle.Render("recorded to "+m.log.Path()))
+	}
+	return lines
+}
+
+// Entering reports whether a value is being typed, so keys go to the input.
+func (m Model) Entering() bool {
+	return m.stage == entering
+}
+
+// Help lists the keys of the current stage.
+func (m Model) Help() []key.Binding {
+	back := keys.Map.Global.Back
+	switch m.stage {
+	case choosing:
+		return []key.Binding{keys.Map.Common.Select(), keys.Map.Actions.Choose, back}
+	case entering:
+		return []key.Binding{keys.Describe(keys.Map.Actions.Choose, "continue"), back}
+	case confirming:
+		cancel := keys.Describe(back, "cancel")
+		if len(m.items) > 0 && keys.Map.Actions.Cancel.Enabled() {
+			cancel = key.NewBinding(
+				key.WithKeys(append(keys.Map.Actions.Cancel.Keys(), back.Keys()...)...),
+				key.WithHelp(keys.Map.Actions.Cancel.Help().Key+"/"+back.Help().Key, "cancel"),
+			)
+		}
+		return []key.Binding{keys.Map.Actions.Confirm, cancel}
+	case done:
+		return []key.Binding{back}
+	}
+	return nil
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	lines := []string{theme.Current.Title.Render("actions") + " " + theme.Current.Subtle.Render(m.service), ""}
+	switch m.stage {
+	case choosing:
+		lines = append(lines, m.renderMenu()...)
+		if m.inputErr != nil {
+			lines = append(lines, "", theme.Current.Failed.Render(m.inputErr.Error()))
+		}
+	case entering:
+		lines = append(lines, m.renderMenu()...)
+		lines = append(lines, "", m.input.View())
+		if m.inputErr != nil {
+			lines = append(lines, theme.Current.Failed.Render(m.inputErr.Error()))
+		}
+	case confirming:
+		lines = append(lines, m.renderRequest()...)
+	case running:
+		lines = append(lines, m.renderRequest()...)
+		lines = append(lines, "", m.spinner.View())
+	case done:
+		lines = append(lines, m.renderRequest()...)
+		lines = append(lines, "")
+		lines = append(lines, m.renderResult()...)
+	}
+	lines = append(lines, "", m.footerView())
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtyurt/ecstui/audit"
+)
+
+var testService = &ecs.Service{
+	ClusterArn:   aws.String("arn:aws:ecs:us-east-1:123456789012:cluster/demo"),
+	ServiceName:  aws.String("web"),
+	DesiredCount: aws.Int64(2),
+}
+
+func openLog(t *testing.T) (*audit.Log, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := audit.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log, path
+}
+
+func readEntries(t *testing.T, path string) []audit.Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRunRecordsIntentAndOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		callErr error
+	}{
+		{name: "succeeded"},
+		{name: "failed", callErr: errors.New("AccessDeniedException: not allowed")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			log, path := openLog(t)
+			var atCall []audit.Entry
+			writers := Writers{UpdateService: func(input *ecs.UpdateServiceInput) error {
+				atCall = readEntries(t, path)
+				return tc.callErr
+			}}
+			m := NewConfirm(UpdateDesiredCount(writers, testService, 5), log, testService, 100, 40)
+
+			result := m.run().(resultMsg)
+			if result.err != tc.callErr || result.auditErr != nil {
+				t.Fatalf("result = %+v, want the call's error and no audit error", result)
+			}
+			if len(atCall) != 1 || atCall[0].Phase != audit.Intent {
+				t.Fatalf("log when the call was made = %+v, want its intent", atCall)
+			}
+
+			entries := readEntries(t, path)
+			if len(entries) != 2 {
+				t.Fatalf("%d entries, want the intent and the outcome", len(entries))
+			}
+			intent, outcome := entries[0], entries[1]
+			for _, entry := range entries {
+				if entry.Cluster != "demo" || entry.Service != "web" || entry.Operation != "ecs:UpdateService" {
+					t.Errorf("entry = %+v, want ecs:UpdateService on demo/web", entry)
+				}
+				if got, want := string(entry.Input), `{"Cluster":"arn:aws:ecs:us-east-1:123456789012:cluster/demo","DesiredCount":5,"Service":"web"}`; got != want {
+					t.Errorf("input = %s, want %s", got, want)
+				}
+			}
+			if intent.Error != "" {
+				t.Errorf("intent has error %q", intent.Error)
+			}
+			if outcome.Phase != audit.Outcome {
+				t.Errorf("second entry phase = %q, want %q", outcome.Phase, audit.Outcome)
+			}
+			wantErr := ""
+			if tc.callErr != nil {
+				wantErr = tc.callErr.Error()
+			}
+			if outcome.Error != wantErr {
+				t.Errorf("outcome error = %q, want %q", outcome.Error, wantErr)
+			}
+			if outcome.Time.Before(intent.Time) {
+				t.Errorf("outcome at %v before the intent at %v", outcome.Time, intent.Time)
+			}
+		})
+	}
+}
+
+func TestRunWithoutAuditLog(t *testing.T) {
+	log, path := openLog(t)
+	// a directory in place of the log makes every write fail
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	writers := Writers{UpdateService: func(input *ecs.UpdateServiceInput) error {
+		called = true
+		return nil
+	}}
+	m := NewConfirm(ForceNewDeployment(writers, testService), log, testService, 100, 40)
+
+	result := m.run().(resultMsg)
+	if called {
+		t.Error("the call was made though its intent could not be recorded")
+	}
+	if result.err == nil || result.auditErr == nil {
+		t.Errorf("result = %+v, want the call reported as not made and the audit error", result)
+	}
+	m, _ = m.Update(result)
+	view := m.View()
+	for _, want := range []string{"not called", "could not record the action"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestChooseRequestError(t *testing.T) {
+	log, path := openLog(t)
+	m := newModel(log, testService, 100, 40)
+	m.items = []item{{
+		label:   "promote",
+		request: func(string) (Request, error) { return Request{}, errors.New("no task set to promote") },
+	}}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.stage != choosing || m.request != nil {
+		t.Fatalf("stage = %v with request %v, want to stay in the menu", m.stage, m.request)
+	}
+	if view := m.View(); !strings.Contains(view, "no task set to promote") {
+		t.Errorf("view does not show the error:\n%s", view)
+	}
+	// moving on clears it
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if view := m.View(); strings.Contains(view, "no task set to promote") {
+		t.Errorf("view still shows the error:\n%s", view)
+	}
+	if entries := readEntries(t, path); len(entries) != 0 {
+		t.Errorf("%d entries recorded for an action that was not confirmed", len(entries))
+	}
+}
@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtyurt/ecstui/audit"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// SessionManagerPlugin is the binary ECS Exec sessions are attached with,
+// the same one the AWS CLI uses.
+const SessionManagerPlugin = "session-manager-plugin"
+
+const (
+	defaultExecCommand = "/bin/sh"
+	execAgent          = "ExecuteCommandAgent"
+)
+
+// sessionEndedMsg is sent when the session-manager-plugin exits and the
+// terminal is back.
+type sessionEndedMsg struct {
+	err error
+}
+
+// NewExec lists the containers of task to open an interactive ECS Exec
+// session in. Containers that cannot be reached are listed with the reason.
+func NewExec(writers Writers, log *audit.Log, service *ecs.Service, task *ecs.Task, width, height int) Model {
+	m := newModel(log, service, width, height)
+	for _, c := range task.Containers {
+		c := c
+		m.items = append(m.items, item{
+			label:    "exec into " + aws.StringValue(c.Name),
+			prompt:   "command: ",
+			value:    defaultExecCommand,
+			disabled: execUnavailable(task, c),
+			request: func(value string) (Request, error) {
+				if value == "" {
+					return Request{}, fmt.Errorf("command cannot be empty")
+				}
+				return ExecCommand(writers, task, c, value), nil
+			},
+		})
+	}
+	return m
+}
+
+// execUnavailable explains why an exec session cannot be opened in the
+// container, or returns nil if it can.
+func execUnavailable(task *ecs.Task, c *ecs.Container) error {
+	if !aws.BoolValue(task.EnableExecuteCommand) {
+		return fmt.Errorf("ECS Exec is disabled for this task, enable it on the service with enableExecuteCommand and start new tasks")
+	}
+	if _, err := exec.LookPath(SessionManagerPlugin); err != nil {
+		return fmt.Errorf("%s is not installed or not in PATH, install the Session Manager plugin for the AWS CLI", SessionManagerPlugin)
+	}
+	for _, agent := range c.ManagedAgents {
+		if aws.StringValue(agent.Name) == execAgent {
+			if status := aws.StringValue(agent.LastStatus); status != "RUNNING" {
+				return fmt.Errorf("the %s of the container is %s", execAgent, status)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("the container has no %s, it was started before ECS Exec was enabled", execAgent)
+}
+
+// ExecCommand starts command in the container through ECS Exec and then
+// hands the terminal to the session-manager-plugin until the session ends.
+func ExecCommand(writers Writers, task *ecs.Task, c *ecs.Container, command string) Request {
+	input := &ecs.ExecuteCommandInput{
+		Cluster:     task.ClusterArn,
+		Task:        task.TaskArn,
+		Container:   c.Name,
+		Command:     aws.String(command),
+		Interactive: aws.Bool(true),
+	}
+	// the plugin addresses the container the way the AWS CLI does
+	target := fmt.Sprintf("ecs:%s_%s_%s",
+		utils.GetLastItemAfterSplit(aws.StringValue(task.ClusterArn), "/"),
+		utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/"),
+		aws.StringValue(c.RuntimeId))
+	var output *ecs.ExecuteCommandOutput
+	return Request{
+		Title:     fmt.Sprintf("exec %s in %s of task %s", command, aws.StringValue(c.Name), utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/")),
+		Operation: "ecs:ExecuteCommand",
+		Input:     input,
+		Run: func() error {
+			var err error
+			output, err = writers.ExecuteCommand(input)
+			return err
+		},
+		Next: func() tea.Cmd {
+			cmd, err := writers.SessionManager(output, target)
+			if err != nil {
+				return func() tea.Msg { return sessionEndedMsg{err} }
+			}
+			return tea.ExecProcess(cmd, func(err error) tea.Msg { return sessionEndedMsg{err} })
+		},
+	}
+}
@@ -0,0 +1,289 @@
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// maxRuleWeight is the largest weight a listener rule accepts for a target
+// group.
+const maxRuleWeight = 999
+
+// taskSetItems lists the actions of EXTERNAL controller services: shifting
+// listener rule weights between task sets, promoting and deleting them.
+func taskSetItems(writers Writers, service *ecs.Service, conns []types.ConnectionConfig) []item {
+	if service.DeploymentController == nil || aws.StringValue(service.DeploymentController.Type) != ecs.DeploymentControllerTypeExternal || len(service.TaskSets) == 0 {
+		return nil
+	}
+	items := []item{}
+	if rules := weightedRules(conns); len(rules) > 0 {
+		shift := item{label: "shift traffic"}
+		for _, conn := range rules {
+			conn := conn
+			shift.options = append(shift.options, item{
+				label:  ruleLabel(conn),
+				prompt: fmt.Sprintf("weights for %s (now %s): ", strings.Join(ruleTargetGroupNames(conn.Rule), "/"), joinWeights(ruleWeights(conn.Rule))),
+				request: func(value string) (Request, error) {
+					weights, err := parseWeights(value, len(ruleWeights(conn.Rule)))
+					if err != nil {
+						return Request{}, err
+					}
+					return ShiftWeights(writers, conn, weights, conns), nil
+				},
+			})
+		}
+		items = append(items, shift)
+	}
+
+	promote := item{label: "promote task set to primary"}
+	remove := item{label: "delete task set"}
+	for _, ts := range service.TaskSets {
+		ts := ts
+		label := fmt.Sprintf("%s %s", aws.StringValue(ts.Status), aws.StringValue(ts.Id))
+		if aws.StringValue(ts.Status) != "PRIMARY" {
+			promote.options = append(promote.options, item{
+				label:   label,
+				request: func(string) (Request, error) { return PromoteTaskSet(writers, service, ts), nil },
+			})
+			remove.options = append(remove.options, item{
+				label:   label,
+				request: func(string) (Request, error) { return DeleteTaskSet(writers, service, ts, conns), nil },
+			})
+		}
+	}
+	if len(promote.options) > 0 {
+		items = append(items, promote, remove)
+	}
+	return items
+}
+
+// weightedRules returns one connection per listener rule that forwards to
+// more than one target group, the rules whose weights can be shifted.
+func weightedRules(conns []types.ConnectionConfig) []types.ConnectionConfig {
+	seen := make(map[string]bool)
+	rules := []types.ConnectionConfig{}
+	for _, conn := range conns {
+		if conn.Rule == nil || seen[aws.StringValue(conn.Rule.RuleArn)] || len(ruleWeights(conn.Rule)) < 2 {
+			continue
+		}
+		seen[aws.StringValue(conn.Rule.RuleArn)] = true
+		rules = append(rules, conn)
+	}
+	slices.SortFunc(rules, func(i, j types.ConnectionConfig) int {
+		return strings.Compare(ruleLabel(i), ruleLabel(j))
+	})
+	return rules
+}
+
+func forwardAction(rule *elbv2.Rule) *elbv2.Action {
+	for _, action := range rule.Actions {
+		if aws.StringValue(action.Type) == elbv2.ActionTypeEnumForward && action.ForwardConfig != nil {
+			return action
+		}
+	}
+	return nil
+}
+
+func ruleWeights(rule *elbv2.Rule) []int64 {
+	action := forwardAction(rule)
+	if action == nil {
+		return nil
+	}
+	weights := []int64{}
+	for _, tg := range action.ForwardConfig.TargetGroups {
+		weights = append(weights, aws.Int64Value(tg.Weight))
+	}
+	return weights
+}
+
+func ruleTargetGroupNames(rule *elbv2.Rule) []string {
+	names := []string{}
+	for _, tg := range forwardAction(rule).ForwardConfig.TargetGroups {
+		names = append(names, targetGroupName(aws.StringValue(tg.TargetGroupArn)))
+	}
+	return names
+}
+
+func targetGroupName(arn string) string {
+	return strings.Split(utils.GetLastItemAfterSplit(arn, "targetgroup/"), "/")[0]
+}
+
+func ruleLabel(conn types.ConnectionConfig) string {
+	priority := "priority " + conn.Priority
+	if aws.BoolValue(conn.Rule.IsDefault) {
+		priority = "default rule"
+	}
+	return fmt.Sprintf("%s :%d %s", conn.LBName, conn.ListenerPort, priority)
+}
+
+func joinWeights(weights []int64) string {
+	parts := []string{}
+	for _, w := range weights {
+		parts = append(parts, strconv.FormatInt(w, 10))
+	}
+	return strings.Join(parts, "/")
+}
+
+// parseWeights reads weights written like 50/50, one per target group.
+func parseWeights(value string, count int) ([]int64, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d weights separated by /, e.g. %s", count, joinWeights(make([]int64, count)))
+	}
+	weights := []int64{}
+	total := int64(0)
+	for _, part := range parts {
+		w, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || w < 0 || w > maxRuleWeight {
+			return nil, fmt.Errorf("weights must be numbers between 0 and %d", maxRuleWeight)
+		}
+		weights = append(weights, w)
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("at least one target group needs a weight above 0")
+	}
+	return weights, nil
+}
+
+// ShiftWeights rewrites the forward action of the connection's rule with new
+// target group weights, keeping every other action and setting as is. The
+// default rule of a listener is changed through the listener itself.
+func ShiftWeights(writers Writers, conn types.ConnectionConfig, weights []int64, conns []types.ConnectionConfig) Request {
+	forward := forwardAction(conn.Rule)
+	groups := []*elbv2.TargetGroupTuple{}
+	for i, tg := range forward.ForwardConfig.TargetGroups {
+		groups = append(groups, &elbv2.TargetGroupTuple{TargetGroupArn: tg.TargetGroupArn, Weight: aws.Int64(weights[i])})
+	}
+	actions := []*elbv2.Action{}
+	for _, action := range conn.Rule.Actions {
+		if action == forward {
+			action = &elbv2.Action{
+				Type:  action.Type,
+				Order: action.Order,
+				ForwardConfig: &elbv2.ForwardActionConfig{
+					TargetGroups:                groups,
+					TargetGroupStickinessConfig: action.ForwardConfig.TargetGroupStickinessConfig,
+				},
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	request := Request{
+		Title:   fmt.Sprintf("shift traffic on %s to %s", ruleLabel(conn), joinWeights(weights)),
+		Preview: weightsPreview(forward.ForwardConfig.TargetGroups, weights, conns),
+	}
+	if aws.BoolValue(conn.Rule.IsDefault) {
+		input := &elbv2.ModifyListenerInput{ListenerArn: aws.String(conn.ListenerArn), DefaultActions: actions}
+		request.Operation = "elasticloadbalancing:ModifyListener"
+		request.Input = input
+		request.Run = func() error { return writers.ModifyListener(input) }
+	} else {
+		input := &elbv2.ModifyRuleInput{RuleArn: conn.Rule.RuleArn, Actions: actions}
+		request.Operation = "elasticloadbalancing:ModifyRule"
+		request.Input = input
+		request.Run = func() error { return writers.ModifyRule(input) }
+	}
+	return request
+}
+
+// weightsPreview shows the traffic share of each target group before and
+// after the change, with the task set behind it, and warns about target
+// groups that start getting traffic without a healthy target.
+func weightsPreview(groups []*elbv2.TargetGroupTuple, weights []int64, conns []types.ConnectionConfig) []string {
+	before, after := int64(0), int64(0)
+	for i, tg := range groups {
+		before += aws.Int64Value(tg.Weight)
+		after += weights[i]
+	}
+	share := func(w, total int64) int64 {
+		if total == 0 {
+			return 0
+		}
+		return w * 100 / total
+	}
+	lines := []string{}
+	warnings := []string{}
+	for i, tg := range groups {
+		arn := aws.StringValue(tg.TargetGroupArn)
+		taskSet, healthy := "", 0
+		for _, conn := range conns {
+			if conn.TGArn != arn {
+				continue
+			}
+			if conn.TaskSetID != "" {
+				taskSet = conn.TaskSetID
+			}
+			healthy = 0
+			for _, h := range conn.TGHealth {
+				if aws.StringValue(h.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+					healthy++
+				}
+			}
+		}
+		lines = append(lines, fmt.Sprintf("  %-32s %3d%% → %3d%%  %s", targetGroupName(arn), share(aws.Int64Value(tg.Weight), before), share(weights[i], after), theme.Current.Subtle.Render(taskSet)))
+		if weights[i] > 0 && healthy == 0 {
+			warnings = append(warnings, theme.Current.Warning.Render(fmt.Sprintf("  ⚠ %s gets traffic but has no healthy targets", targetGroupName(arn))))
+		}
+	}
+	return append(lines, warnings...)
+}
+
+// PromoteTaskSet makes the task set the primary one of the service.
+func PromoteTaskSet(writers Writers, service *ecs.Service, ts *ecs.TaskSet) Request {
+	input := &ecs.UpdateServicePrimaryTaskSetInput{
+		Cluster:        service.ClusterArn,
+		Service:        service.ServiceName,
+		PrimaryTaskSet: ts.Id,
+	}
+	preview := []string{}
+	for _, other := range service.TaskSets {
+		if aws.StringValue(other.Status) == "PRIMARY" {
+			preview = append(preview, fmt.Sprintf("  primary %s → %s", aws.StringValue(other.Id), aws.StringValue(ts.Id)))
+		}
+	}
+	preview = append(preview, fmt.Sprintf("  %s runs %d of %d tasks, %s", aws.StringValue(ts.Id), aws.Int64Value(ts.RunningCount), aws.Int64Value(ts.ComputedDesiredCount), aws.StringValue(ts.StabilityStatus)))
+	if aws.Int64Value(ts.RunningCount) < aws.Int64Value(ts.ComputedDesiredCount) {
+		preview = append(preview, theme.Current.Warning.Render("  ⚠ the task set is not fully scaled"))
+	}
+	return Request{
+		Title:     "promote task set " + aws.StringValue(ts.Id),
+		Operation: "ecs:UpdateServicePrimaryTaskSet",
+		Input:     input,
+		Preview:   preview,
+		Run:       func() error { return writers.UpdateServicePrimaryTaskSet(input) },
+	}
+}
+
+// DeleteTaskSet deletes a task set that is not the primary one, warning if
+// a listener rule still sends it traffic.
+func DeleteTaskSet(writers Writers, service *ecs.Service, ts *ecs.TaskSet, conns []types.ConnectionConfig) Request {
+	input := &ecs.DeleteTaskSetInput{
+		Cluster: service.ClusterArn,
+		Service: service.ServiceName,
+		TaskSet: ts.Id,
+	}
+	preview := []string{fmt.Sprintf("  %s and its %d running tasks are removed", aws.StringValue(ts.Id), aws.Int64Value(ts.RunningCount))}
+	for _, conn := range conns {
+		if conn.TaskSetID == aws.StringValue(ts.Id) && conn.TGWeigth > 0 {
+			preview = append(preview, theme.Current.Warning.Render(fmt.Sprintf("  ⚠ %s still forwards weight %d to %s", conn.LBName, conn.TGWeigth, conn.TGName)))
+		}
+	}
+	return Request{
+		Title:     "delete task set " + aws.StringValue(ts.Id),
+		Operation: "ecs:DeleteTaskSet",
+		Input:     input,
+		Preview:   preview,
+		Run:       func() error { return writers.DeleteTaskSet(input) },
+	}
+}
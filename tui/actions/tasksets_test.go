@@ -0,0 +1,250 @@
+package actions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/mtyurt/ecstui/types"
+)
+
+func TestParseWeights(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		count   int
+		want    []int64
+		wantErr string
+	}{
+		{value: "50/50", count: 2, want: []int64{50, 50}},
+		{value: " 10 / 90 ", count: 2, want: []int64{10, 90}},
+		// weights are relative, they need not add up to 100
+		{value: "1/3", count: 2, want: []int64{1, 3}},
+		{value: "999/999", count: 2, want: []int64{999, 999}},
+		{value: "0/100/0", count: 3, want: []int64{0, 100, 0}},
+		{value: "0/0", count: 2, wantErr: "at least one target group needs a weight above 0"},
+		{value: "ten/90", count: 2, wantErr: "weights must be numbers between 0 and 999"},
+		{value: "50%/50%", count: 2, wantErr: "weights must be numbers between 0 and 999"},
+		{value: "/", count: 2, wantErr: "weights must be numbers between 0 and 999"},
+		{value: "-10/110", count: 2, wantErr: "weights must be numbers between 0 and 999"},
+		{value: "1000/0", count: 2, wantErr: "weights must be numbers between 0 and 999"},
+		{value: "100", count: 2, wantErr: "expected 2 weights separated by /, e.g. 0/0"},
+		{value: "50,50", count: 2, wantErr: "expected 2 weights separated by /"},
+		{value: "", count: 2, wantErr: "expected 2 weights separated by /"},
+	} {
+		got, err := parseWeights(tc.value, tc.count)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("parseWeights(%q) error = %v, want %q", tc.value, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseWeights(%q) = %v, %v, want %v", tc.value, got, err, tc.want)
+		}
+	}
+}
+
+const (
+	blueTG  = "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web-blue/1111"
+	greenTG = "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web-green/2222"
+)
+
+// weightedConns are the connections of a blue and a green task set behind
+// one rule forwarding to both target groups, after an authentication action.
+func weightedConns(isDefault bool) []types.ConnectionConfig {
+	rule := &elbv2.Rule{
+		RuleArn:   aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:listener-rule/app/web/1/2/3"),
+		IsDefault: aws.Bool(isDefault),
+		Priority:  aws.String("10"),
+		Actions: []*elbv2.Action{
+			{Type: aws.String(elbv2.ActionTypeEnumAuthenticateOidc), Order: aws.Int64(1)},
+			{
+				Type:  aws.String(elbv2.ActionTypeEnumForward),
+				Order: aws.Int64(2),
+				ForwardConfig: &elbv2.ForwardActionConfig{
+					TargetGroups: []*elbv2.TargetGroupTuple{
+						{TargetGroupArn: aws.String(blueTG), Weight: aws.Int64(90)},
+						{TargetGroupArn: aws.String(greenTG), Weight: aws.Int64(10)},
+					},
+					TargetGroupStickinessConfig: &elbv2.TargetGroupStickinessConfig{Enabled: aws.Bool(true), DurationSeconds: aws.Int64(300)},
+				},
+			},
+		},
+	}
+	healthy := []*elbv2.TargetHealthDescription{{TargetHealth: &elbv2.TargetHealth{State: aws.String(elbv2.TargetHealthStateEnumHealthy)}}}
+	base := types.ConnectionConfig{LBName: "web", ListenerPort: 443, Priority: "10", ListenerArn: "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/web/1/2", Rule: rule}
+	blue, green := base, base
+	blue.TaskSetID, blue.TGName, blue.TGArn, blue.TGWeigth, blue.TGHealth = "ecs-svc/blue", "web-blue", blueTG, 90, healthy
+	green.TaskSetID, green.TGName, green.TGArn, green.TGWeigth = "ecs-svc/green", "web-green", greenTG, 10
+	return []types.ConnectionConfig{blue, green}
+}
+
+// calls records the load balancer calls requests are run with.
+type calls struct {
+	rules     []*elbv2.ModifyRuleInput
+	listeners []*elbv2.ModifyListenerInput
+}
+
+func (c *calls) writers() Writers {
+	return Writers{
+		ModifyRule: func(input *elbv2.ModifyRuleInput) error {
+			c.rules = append(c.rules, input)
+			return nil
+		},
+		ModifyListener: func(input *elbv2.ModifyListenerInput) error {
+			c.listeners = append(c.listeners, input)
+			return nil
+		},
+	}
+}
+
+func TestShiftWeights(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		isDefault bool
+		operation string
+	}{
+		{name: "rule", operation: "elasticloadbalancing:ModifyRule"},
+		{name: "default rule", isDefault: true, operation: "elasticloadbalancing:ModifyListener"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conns := weightedConns(tc.isDefault)
+			c := &calls{}
+			request := ShiftWeights(c.writers(), conns[0], []int64{1, 3}, conns)
+			if request.Operation != tc.operation {
+				t.Errorf("operation = %s, want %s", request.Operation, tc.operation)
+			}
+			if err := request.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			var actions []*elbv2.Action
+			if tc.isDefault {
+				// the default rule can only be changed through its listener
+				if len(c.listeners) != 1 || len(c.rules) != 0 {
+					t.Fatalf("%d ModifyListener and %d ModifyRule calls, want one ModifyListener", len(c.listeners), len(c.rules))
+				}
+				if got := aws.StringValue(c.listeners[0].ListenerArn); got != conns[0].ListenerArn {
+					t.Errorf("listener = %s, want %s", got, conns[0].ListenerArn)
+				}
+				if c.listeners[0] != request.Input {
+					t.Error("the listener is modified with another input than the one shown")
+				}
+				actions = c.listeners[0].DefaultActions
+			} else {
+				if len(c.rules) != 1 || len(c.listeners) != 0 {
+					t.Fatalf("%d ModifyRule and %d ModifyListener calls, want one ModifyRule", len(c.rules), len(c.listeners))
+				}
+				if got := aws.StringValue(c.rules[0].RuleArn); got != aws.StringValue(conns[0].Rule.RuleArn) {
+					t.Errorf("rule = %s, want %s", got, aws.StringValue(conns[0].Rule.RuleArn))
+				}
+				if c.rules[0] != request.Input {
+					t.Error("the rule is modified with another input than the one shown")
+				}
+				actions = c.rules[0].Actions
+			}
+
+			// the other actions and the stickiness are kept
+			if len(actions) != 2 || actions[0] != conns[0].Rule.Actions[0] {
+				t.Fatalf("actions = %v, want the authentication action kept in front of the forward", actions)
+			}
+			forward := actions[1].ForwardConfig
+			if got := aws.BoolValue(forward.TargetGroupStickinessConfig.Enabled); !got {
+				t.Error("stickiness dropped")
+			}
+			weights := map[string]int64{}
+			for _, tg := range forward.TargetGroups {
+				weights[aws.StringValue(tg.TargetGroupArn)] = aws.Int64Value(tg.Weight)
+			}
+			if want := map[string]int64{blueTG: 1, greenTG: 3}; !reflect.DeepEqual(weights, want) {
+				t.Errorf("weights = %v, want %v", weights, want)
+			}
+			// the rule shown in the view keeps its current weights
+			if got := aws.Int64Value(conns[0].Rule.Actions[1].ForwardConfig.TargetGroups[0].Weight); got != 90 {
+				t.Errorf("the connection's rule was changed to weight %d", got)
+			}
+		})
+	}
+}
+
+func TestShiftWeightsPreview(t *testing.T) {
+	conns := weightedConns(false)
+	// 1/3 is a quarter and three quarters of the traffic
+	preview := strings.Join(ShiftWeights(Writers{}, conns[0], []int64{1, 3}, conns).Preview, "\n")
+	for _, want := range []string{"web-blue", " 90% →  25%", "ecs-svc/blue", "web-green", " 10% →  75%", "ecs-svc/green", "⚠ web-green gets traffic but has no healthy targets"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("preview does not contain %q:\n%s", want, preview)
+		}
+	}
+	if strings.Contains(preview, "web-blue gets traffic") {
+		t.Errorf("preview warns about the healthy target group:\n%s", preview)
+	}
+}
+
+func TestPromoteTaskSet(t *testing.T) {
+	blue := &ecs.TaskSet{Id: aws.String("ecs-svc/blue"), Status: aws.String("PRIMARY"), RunningCount: aws.Int64(4), ComputedDesiredCount: aws.Int64(4)}
+	green := &ecs.TaskSet{Id: aws.String("ecs-svc/green"), Status: aws.String("ACTIVE"), RunningCount: aws.Int64(2), ComputedDesiredCount: aws.Int64(4), StabilityStatus: aws.String("STABILIZING")}
+	service := &ecs.Service{ClusterArn: aws.String("arn:aws:ecs:us-east-1:123456789012:cluster/demo"), ServiceName: aws.String("web"), TaskSets: []*ecs.TaskSet{blue, green}}
+
+	var promoted []*ecs.UpdateServicePrimaryTaskSetInput
+	writers := Writers{UpdateServicePrimaryTaskSet: func(input *ecs.UpdateServicePrimaryTaskSetInput) error {
+		promoted = append(promoted, input)
+		return nil
+	}}
+	request := PromoteTaskSet(writers, service, green)
+	preview := strings.Join(request.Preview, "\n")
+	for _, want := range []string{"primary ecs-svc/blue → ecs-svc/green", "ecs-svc/green runs 2 of 4 tasks, STABILIZING", "⚠ the task set is not fully scaled"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("preview does not contain %q:\n%s", want, preview)
+		}
+	}
+	if err := request.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(promoted) != 1 || aws.StringValue(promoted[0].PrimaryTaskSet) != "ecs-svc/green" || aws.StringValue(promoted[0].Service) != "web" {
+		t.Errorf("UpdateServicePrimaryTaskSet calls = %v, want one promoting ecs-svc/green of web", promoted)
+	}
+
+	green.RunningCount = aws.Int64(4)
+	if preview := strings.Join(PromoteTaskSet(writers, service, green).Preview, "\n"); strings.Contains(preview, "not fully scaled") {
+		t.Errorf("preview warns about a fully scaled task set:\n%s", preview)
+	}
+}
+
+func TestTaskSetItems(t *testing.T) {
+	service := &ecs.Service{
+		ServiceName:          aws.String("web"),
+		DeploymentController: &ecs.DeploymentController{Type: aws.String(ecs.DeploymentControllerTypeExternal)},
+		TaskSets: []*ecs.TaskSet{
+			{Id: aws.String("ecs-svc/blue"), Status: aws.String("PRIMARY")},
+			{Id: aws.String("ecs-svc/green"), Status: aws.String("ACTIVE")},
+		},
+	}
+	items := taskSetItems(Writers{}, service, weightedConns(true))
+	labels := []string{}
+	for _, item := range items {
+		labels = append(labels, item.label)
+	}
+	if want := []string{"shift traffic", "promote task set to primary", "delete task set"}; !reflect.DeepEqual(labels, want) {
+		t.Fatalf("items = %v, want %v", labels, want)
+	}
+	// both connections share the rule, it is offered once
+	if rules := items[0].options; len(rules) != 1 || rules[0].label != "web :443 default rule" {
+		t.Errorf("shift traffic options = %v, want the default rule once", rules)
+	}
+	if _, err := items[0].options[0].request("ten/90"); err == nil {
+		t.Error("non-numeric weights were accepted")
+	}
+	// the primary task set can be neither promoted nor deleted
+	if options := items[1].options; len(options) != 1 || options[0].label != "ACTIVE ecs-svc/green" {
+		t.Errorf("promote options = %v, want the green task set only", options)
+	}
+
+	service.DeploymentController.Type = aws.String(ecs.DeploymentControllerTypeEcs)
+	if items := taskSetItems(Writers{}, service, weightedConns(true)); len(items) != 0 {
+		t.Errorf("%d task set items for an ECS controller service", len(items))
+	}
+}
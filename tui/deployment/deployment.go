@@ -4,40 +4,43 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/ecs"
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	humanizer "github.com/dustin/go-humanize"
 	"github.com/mtyurt/ecstui/logger"
 	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/layout"
+	"github.com/mtyurt/ecstui/tui/tasktable"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
 	"github.com/mtyurt/ecstui/types"
 	"github.com/mtyurt/ecstui/utils"
 )
 
 var (
-	styles = list.DefaultStyles()
-
-	sectionWidth      = 40
+	// deployment and connection boxes share the width between them within
+	// these bounds
+	minSectionWidth   = 36
+	maxSectionWidth   = 70
 	smallSectionStyle = lipgloss.NewStyle().
 				Width(40).
 				Height(6).
 				Margin(0, 1, 0, 0).
 				PaddingLeft(4).
 				Align(lipgloss.Center).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
-	subtle              = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B"))
-	bold                = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFBF00"))
-	healthy             = lipgloss.NewStyle().Foreground(lipgloss.Color("#80C904"))
-	unhealthy           = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBF00"))
-	tgNameStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("#ADD8E6")) // Amber
-	refreshSpinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+				BorderStyle(lipgloss.NormalBorder())
 )
 
+// BoxZone is the zone kind of the deployment boxes, keyed by deployment ID.
+const BoxZone = "deployment"
+
 type sessionState int
 
 const (
@@ -49,6 +52,7 @@ const (
 type Model struct {
 	images             map[string][]string
 	connections        []types.ConnectionConfig
+	instanceIDs        map[string]string
 	tasks              map[string][]*ecs.Task
 	deploymentMap      map[string]ecs.Deployment
 	deployments        []*ecs.Deployment
@@ -59,6 +63,14 @@ type Model struct {
 	spinner            spinnertui.Model
 	refreshSpinner     spinner.Model
 	showRefreshSpinner bool
+	sectionErrors      types.SectionErrors
+	connectionsErr     error
+	staleSince         utils.StaleSections
+	lastUpdate         time.Time
+	// tables are the task tables by deployment ID, focused the deployment
+	// whose table has the keys.
+	tables  map[string]tasktable.Model
+	focused string
 }
 
 type DeploymentsFetcher func(deployments []*ecs.Deployment) (*types.DeploymentStatus, error)
@@ -82,8 +94,9 @@ func New(statusFetcher DeploymentsFetcher, deployments []*ecs.Deployment, width,
 		statusFetcher:      statusFetcher,
 		state:              initial,
 		spinner:            spinnertui.New("Loading deployments"),
-		refreshSpinner:     spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(refreshSpinnerStyle)),
+		refreshSpinner:     spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(theme.Current.Highlight)),
 		showRefreshSpinner: false,
+		tables:             make(map[string]tasktable.Model),
 	}
 	m.SetSize(width, height)
 	return m
@@ -112,8 +125,15 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.fetchStatus, m.spinner.SpinnerTick())
 }
 
-func (m Model) Refresh() (Model, tea.Cmd) {
+// Refresh reloads the status of deployments, the service's deployments as of
+// its last refresh.
+func (m Model) Refresh(deployments []*ecs.Deployment) (Model, tea.Cmd) {
 	logger.Println("refreshing taskset status")
+	m.deployments = deployments
+	m.deploymentMap = make(map[string]ecs.Deployment)
+	for _, d := range deployments {
+		m.deploymentMap[*d.Id] = *d
+	}
 	m.showRefreshSpinner = true
 	return m, tea.Batch(m.fetchStatus, m.refreshSpinner.Tick)
 
@@ -123,16 +143,23 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if table, ok := m.tables[m.focused]; ok {
+			m.tables[m.focused], cmd = table.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case StatusMsg:
 		logger.Println("deployment status fetched")
-		m.images = msg.DeploymentImages
-		m.connections = msg.DeploymentConnections
-		m.tasks = msg.DeploymentTasks
+		m.mergeStatus(msg)
+		m.err = nil
 		m.state = loaded
 		m.showRefreshSpinner = false
 	case errMsg:
 		m.err = msg.err
-		m.state = failed
+		m.showRefreshSpinner = false
+		if m.state != loaded { // keep showing the last good data on refresh failures
+			m.state = failed
+		}
 	}
 
 	switch m.state {
@@ -152,19 +179,173 @@ func (m Model) View() string {
 	case initial:
 		return m.spinner.View()
 	case loaded:
-		return m.renderView()
+		view := m.renderView()
+		if m.err != nil {
+			view = lipgloss.JoinVertical(lipgloss.Center, theme.Current.Warning.Render(utils.StaleBannerText(m.err, m.lastUpdate, keys.Map.Service.Refresh.Help().Key, m.width)), view)
+		}
+		return view
 	case failed:
-		return m.err.Error()
+		return m.err.Error() + "\n" + theme.Current.Subtle.Render(utils.RetryHint(keys.Map.Service.Refresh.Help().Key))
 	default:
 		return m.spinner.View()
 
 	}
 }
 
+// Connections returns the last fetched load balancer connections of the
+// service.
+func (m Model) Connections() []types.ConnectionConfig {
+	return m.connections
+}
+
+// AllTasks returns the last fetched tasks of every deployment.
+func (m Model) AllTasks() []*ecs.Task {
+	tasks := []*ecs.Task{}
+	for _, d := range m.deployments {
+		tasks = append(tasks, m.tasks[*d.Id]...)
+	}
+	return tasks
+}
+
+// InstanceIDs returns the EC2 instance IDs of the container instances the
+// tasks run on.
+func (m Model) InstanceIDs() map[string]string {
+	return m.instanceIDs
+}
+
+// targetHealth returns the target health of the deployment's tasks by task
+// ARN, or nil if the service is not registered to a target group.
+func (m Model) targetHealth(d ecs.Deployment) map[string]string {
+	if len(m.connections) == 0 {
+		return nil
+	}
+	health := []*elbv2.TargetHealthDescription{}
+	for _, conn := range m.connections {
+		health = append(health, conn.TGHealth...)
+	}
+	return utils.NewTargetMatcher(m.tasks[*d.Id], m.instanceIDs).TaskTargetHealth(m.tasks[*d.Id], health)
+}
+
+// Tasks returns the last fetched tasks of the deployment.
+func (m Model) Tasks(deploymentID string) []*ecs.Task {
+	return m.tasks[deploymentID]
+}
+
+// mergeStatus applies a fetched status. Sections that failed to load keep the
+// data of the previous refresh and are marked stale since it was loaded.
+func (m *Model) mergeStatus(status *types.DeploymentStatus) {
+	if status.DeploymentImages == nil {
+		status.DeploymentImages = make(map[string][]string)
+	}
+	if status.DeploymentTasks == nil {
+		status.DeploymentTasks = make(map[string][]*ecs.Task)
+	}
+	if status.InstanceIDs == nil {
+		status.InstanceIDs = make(map[string]string)
+	}
+	if status.Errors == nil {
+		status.Errors = make(types.SectionErrors)
+	}
+
+	merge := utils.NewSectionMerge(status.Errors, m.staleSince, m.lastUpdate)
+	for _, d := range m.deployments {
+		id := *d.Id
+		utils.KeepSection(merge, id, types.SectionImages, status.DeploymentImages, m.images)
+		utils.KeepSection(merge, id, types.SectionTasks, status.DeploymentTasks, m.tasks)
+		if merge.Failed(id, types.SectionInstances) {
+			for arn, instanceID := range m.instanceIDs {
+				if _, ok := status.InstanceIDs[arn]; !ok {
+					status.InstanceIDs[arn] = instanceID
+				}
+			}
+			merge.Keep(id, types.SectionInstances)
+		}
+	}
+	// the connections are shared by the deployments, they fail as a whole
+	if status.ConnectionsErr != nil && len(m.connections) > 0 {
+		status.DeploymentConnections = m.connections
+		merge.Keep("", types.SectionConnections)
+	}
+
+	m.images = status.DeploymentImages
+	m.connections = status.DeploymentConnections
+	m.tasks = status.DeploymentTasks
+	m.instanceIDs = status.InstanceIDs
+	m.sectionErrors = status.Errors
+	m.connectionsErr = status.ConnectionsErr
+	m.staleSince = merge.Stale
+	m.lastUpdate = time.Now()
+
+	for _, d := range m.deployments {
+		table, ok := m.tables[*d.Id]
+		if !ok {
+			table = tasktable.New()
+		}
+		table.SetTasks(m.tasks[*d.Id], m.targetHealth(*d))
+		m.tables[*d.Id] = table
+	}
+}
+
+// CycleFocus moves the keys to the task table of the next deployment, or the
+// previous one for a negative step, wrapping around. With no deployment
+// focused it starts from the first or the last.
+func (m *Model) CycleFocus(step int) {
+	ids := []string{}
+	for _, d := range m.deployments {
+		ids = append(ids, *d.Id)
+	}
+	slices.Sort(ids) // the order the boxes are shown in
+	next := tasktable.Next(ids, m.focused, step)
+	m.Blur()
+	if table, ok := m.tables[next]; ok {
+		table.Focus()
+		m.tables[next] = table
+		m.focused = next
+	}
+}
+
+// Blur takes the keys from the focused task table.
+func (m *Model) Blur() {
+	if table, ok := m.tables[m.focused]; ok {
+		table.Blur()
+		m.tables[m.focused] = table
+	}
+	m.focused = ""
+}
+
+// Focused reports whether a task table has the keys.
+func (m Model) Focused() bool {
+	return m.focused != ""
+}
+
+// Scroll scrolls the task table of the deployment by n tasks, see
+// tasktable.Model.Scroll.
+func (m *Model) Scroll(deploymentID string, n int) bool {
+	table, ok := m.tables[deploymentID]
+	if !ok || !table.Scroll(n) {
+		return false
+	}
+	m.tables[deploymentID] = table
+	return true
+}
+
+// Help lists the keys of the focused task table.
+func (m Model) Help() []key.Binding {
+	return m.tables[m.focused].Help()
+}
+
+func (m Model) sectionWarning(deploymentID, section string, width int) string {
+	err := m.sectionErrors.Get(deploymentID, section)
+	if deploymentID == "" && section == types.SectionConnections {
+		err = m.connectionsErr
+	}
+	return theme.Current.Alert(m.staleSince.Warning(err, deploymentID, section, keys.Map.Service.Refresh.Help().Key, width))
+}
+
 func (m *Model) renderView() string {
 	deployments := m.renderDeployments()
 	connections := m.renderConnections()
-	return lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(lipgloss.JoinVertical(lipgloss.Center, deployments, connections))
+	return layout.Center(m.width, lipgloss.JoinVertical(lipgloss.Center, deployments, connections))
 }
 
 func (m Model) renderDeployments() string {
@@ -175,11 +356,12 @@ func (m Model) renderDeployments() string {
 
 	views := []string{}
 
+	width := layout.Share(m.width-10, len(deployments), 3, minSectionWidth, maxSectionWidth)
 	for _, d := range deployments {
-		views = append(views, m.renderDeploymentDetails(*d))
+		views = append(views, m.renderDeploymentDetails(*d, width))
 	}
 
-	return lipgloss.NewStyle().Width(m.width - 10).Align(lipgloss.Center).Render(lipgloss.JoinHorizontal(lipgloss.Center, views...))
+	return layout.Center(m.width-10, layout.Flow(m.width-10, lipgloss.Center, views...))
 }
 
 func (m Model) renderConnections() string {
@@ -189,11 +371,16 @@ func (m Model) renderConnections() string {
 	})
 
 	views := []string{}
+	width := layout.Share(m.width-16, len(connections), 3, minSectionWidth, maxSectionWidth)
 	for _, c := range connections {
-		views = append(views, m.renderConnectionDetails(c))
+		views = append(views, m.renderConnectionDetails(c, width))
+	}
+	view := layout.Flow(m.width-16, lipgloss.Center, views...)
+	if warning := m.sectionWarning("", types.SectionConnections, m.width-20); warning != "" {
+		view = lipgloss.JoinVertical(lipgloss.Center, view, warning)
 	}
 
-	return smallSectionStyle.Copy().Width(m.width - 10).Render(lipgloss.JoinHorizontal(lipgloss.Center, views...))
+	return theme.Current.Bordered(smallSectionStyle).Width(m.width - 10).Render(view)
 
 }
 func truncateTo(s string, max int) string {
@@ -213,20 +400,20 @@ func simpleAttachmentView() string {
 `
 }
 
-func (m Model) renderConnectionDetails(conn types.ConnectionConfig) string {
-	lbName := smallSectionStyle.Copy().
-		Width(sectionWidth + 20).
+func (m Model) renderConnectionDetails(conn types.ConnectionConfig, width int) string {
+	lbName := theme.Current.Bordered(smallSectionStyle).
+		Width(width).
 		Height(1).
-		Render(styles.Title.AlignHorizontal(lipgloss.Center).Render(conn.LBName))
+		Render(theme.Current.Title.Copy().AlignHorizontal(lipgloss.Center).Render(conn.LBName))
 
-	tgInfo := getTGNameAndHealth(conn, sectionWidth-3)
-	tgInfo = lipgloss.NewStyle().Height(1).AlignHorizontal(lipgloss.Center).Width(sectionWidth).Render(tgInfo)
+	tgInfo := getTGNameAndHealth(conn, width-3)
+	tgInfo = lipgloss.NewStyle().Height(1).AlignHorizontal(lipgloss.Center).Width(width).Render(tgInfo)
 	return lipgloss.JoinVertical(lipgloss.Center, tgInfo, lbName)
 }
 
 func getTGNameAndHealth(connConfig types.ConnectionConfig, width int) string {
 	tgName := truncateTo(connConfig.TGName, width)
-	tgName = tgNameStyle.Render(tgName)
+	tgName = theme.Current.Info.Render(tgName)
 
 	healths := []string{}
 	azByState := make(map[string][]string)
@@ -244,9 +431,9 @@ func getTGNameAndHealth(connConfig types.ConnectionConfig, width int) string {
 	}
 	slices.Sort(states)
 	for _, state := range states {
-		style := unhealthy
+		style := theme.Current.Warning
 		if state == "healthy" {
-			style = healthy
+			style = theme.Current.OK
 		}
 		slices.Sort(azByState[state])
 		azByState[state] = utils.UniqueStrings(azByState[state])
@@ -256,41 +443,45 @@ func getTGNameAndHealth(connConfig types.ConnectionConfig, width int) string {
 
 }
 
-func (m Model) renderDeploymentDetails(d ecs.Deployment) string {
+// renderDeploymentDetails renders the box of a deployment, width columns
+// wide.
+func (m Model) renderDeploymentDetails(d ecs.Deployment, width int) string {
 	taskCreation := *d.CreatedAt
 	taskDefinition := utils.GetLastItemAfterSplit(*d.TaskDefinition, "/")
 	status := *d.Status
-	taskIds := []table.Row{}
-	for _, task := range m.tasks[*d.Id] {
-		taskIds = append(taskIds, table.Row{utils.GetLastItemAfterSplit(*task.TaskArn, "/"), utils.MapTaskStatusToLabel(*task.LastStatus)})
-	}
-	tableStyles := table.DefaultStyles()
-	tableStyles.Selected = lipgloss.NewStyle()
-	tableStyles.Header = tableStyles.Header.Copy().PaddingLeft(1)
-	taskTable := table.New(
-		table.WithColumns([]table.Column{{Title: "id", Width: 10}, {Title: "status", Width: 30}}),
-		table.WithRows(taskIds),
-		table.WithHeight(len(taskIds)),
-		table.WithFocused(false),
-		table.WithStyles(tableStyles),
-	)
-
-	title := styles.Title.Copy().Padding(0).MarginBottom(0).Render(truncateTo(*d.Id, sectionWidth-2))
+	title := theme.Current.Title.Copy().Padding(0).MarginBottom(0).Render(truncateTo(*d.Id, width-2))
 	if m.showRefreshSpinner {
-		space := sectionWidth - lipgloss.Width(title) - lipgloss.Width(m.refreshSpinner.View())
+		space := width - lipgloss.Width(title) - lipgloss.Width(m.refreshSpinner.View())
 		title = title + strings.Repeat(" ", space) + m.refreshSpinner.View()
 	}
 	title = title + "\n"
-	content := lipgloss.JoinVertical(lipgloss.Left,
+	lines := []string{
 		title,
-		"created "+humanizer.Time(taskCreation),
-		fmt.Sprintf("%s: %s", bold.Render("status"), status),
-		fmt.Sprintf("%s: %s", bold.Render("rollout"), *d.RolloutState),
-		fmt.Sprintf("\n%s: %s", bold.Render("taskdef"), taskDefinition), utils.JoinImageNames(m.images[*d.Id]),
-		bold.Render("tasks:")+"\n"+taskTable.View(),
-	)
-
-	content = smallSectionStyle.Copy().Height(10).Width(sectionWidth).AlignHorizontal(lipgloss.Left).Render(content)
+		"created " + humanizer.Time(taskCreation),
+		fmt.Sprintf("%s: %s", theme.Current.Header.Render("status"), status),
+		fmt.Sprintf("%s: %s", theme.Current.Header.Render("rollout"), *d.RolloutState),
+		fmt.Sprintf("\n%s: %s", theme.Current.Header.Render("taskdef"), taskDefinition), layout.WrapText(utils.JoinImageNames(m.images[*d.Id]), width-smallSectionStyle.GetHorizontalPadding()),
+	}
+	if mismatches := utils.DigestMismatches(m.tasks[*d.Id]); len(mismatches) > 0 {
+		lines = append(lines, theme.Current.Header.Render("⚠ digest drift: ")+truncateTo(strings.Join(mismatches, ", "), width-6))
+	}
+	if warning := m.sectionWarning(*d.Id, types.SectionImages, width-6); warning != "" {
+		lines = append(lines, warning)
+	}
+	lines = append(lines, theme.Current.Header.Render("tasks:")+"\n"+m.tables[*d.Id].View(width-4))
+	if warning := m.sectionWarning(*d.Id, types.SectionTasks, width-6); warning != "" {
+		lines = append(lines, warning)
+	}
+	if warning := m.sectionWarning(*d.Id, types.SectionInstances, width-6); warning != "" {
+		lines = append(lines, warning)
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	style := theme.Current.Bordered(smallSectionStyle)
+	if *d.Id == m.focused {
+		style = theme.Current.Focused(smallSectionStyle)
+	}
+	content = zone.Mark(zone.ID(BoxZone, *d.Id), style.Height(10).Width(width).AlignHorizontal(lipgloss.Left).Render(content))
 	attachment := "\n\n\n"
 	if *d.Status == "PRIMARY" {
 		attachment = simpleAttachmentView()
@@ -0,0 +1,58 @@
+package deployment
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mtyurt/ecstui/internal/fixtures"
+	"github.com/mtyurt/ecstui/internal/golden"
+	"github.com/mtyurt/ecstui/types"
+)
+
+func TestView(t *testing.T) {
+	for _, width := range golden.Widths {
+		for _, scenario := range fixtures.Scenarios() {
+			if scenario.Deployments == nil {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s/%d", scenario.Name, width), func(t *testing.T) {
+				m := New(nil, scenario.Service.Ecs.Deployments, width, 0)
+				loaded, _ := m.Update(StatusMsg(scenario.Deployments))
+				golden.Assert(t, fmt.Sprintf("%s_%d", scenario.Name, width), loaded.View())
+			})
+		}
+	}
+}
+
+func TestViewConnectionsError(t *testing.T) {
+	scenario := fixtures.RollingDeployment()
+	scenario.Deployments.DeploymentConnections = nil
+	scenario.Deployments.ConnectionsErr = errors.New("AccessDeniedException: not authorized to perform elasticloadbalancing:DescribeRules")
+	m := New(nil, scenario.Service.Ecs.Deployments, 120, 0)
+	loaded, _ := m.Update(StatusMsg(scenario.Deployments))
+	golden.Assert(t, "connections_error_120", loaded.View())
+}
+
+func TestViewSectionError(t *testing.T) {
+	scenario := fixtures.RollingDeployment()
+	delete(scenario.Deployments.DeploymentImages, "ecs-svc/2222222222222222222")
+	scenario.Deployments.Errors.Add("ecs-svc/2222222222222222222", types.SectionImages, errors.New("ClientException: Unable to describe task definition."))
+	m := New(nil, scenario.Service.Ecs.Deployments, 120, 0)
+	loaded, _ := m.Update(StatusMsg(scenario.Deployments))
+	golden.Assert(t, "section_error_120", loaded.View())
+}
+
+func TestViewFocusedTable(t *testing.T) {
+	scenario := fixtures.HugeTaskCount()
+	m := New(nil, scenario.Service.Ecs.Deployments, 120, 0)
+	loaded, _ := m.Update(StatusMsg(scenario.Deployments))
+	loaded.CycleFocus(1)
+	// expand, sort by zone and go to the second page
+	for _, k := range []string{"c", "s", "s", "right"} {
+		loaded, _ = loaded.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	golden.Assert(t, "focused_table_120", loaded.View())
+}
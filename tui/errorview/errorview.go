@@ -0,0 +1,171 @@
+package errorview
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+var boxStyle = lipgloss.NewStyle().
+	Padding(1, 2).
+	BorderStyle(lipgloss.RoundedBorder())
+
+// expiredCredentialCodes are AWS error codes returned when the session's
+// credentials are missing, expired or revoked.
+var expiredCredentialCodes = []string{
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"InvalidClientTokenId",
+	"UnrecognizedClientException",
+	"NoCredentialProviders",
+	"SSOProviderInvalidToken",
+}
+
+// Recovery holds what the error screen needs to get a session working again.
+type Recovery struct {
+	// LoginCommand is run with the terminal handed over, e.g. "aws sso login".
+	LoginCommand string
+	// ReloadCredentials makes the AWS session pick up refreshed credentials.
+	ReloadCredentials func() error
+}
+
+// RetryMsg is sent when the user asks to retry the failed call, either
+// directly or after a successful login.
+type RetryMsg struct{}
+
+type loginFinishedMsg struct{ err error }
+
+type Model struct {
+	err       error
+	loginErr  error
+	recovery  Recovery
+	exit      key.Binding
+	width     int
+	loggingIn bool
+}
+
+// New creates an error screen for err. exit is how the parent leaves the
+// screen, e.g. quitting or going back.
+func New(err error, recovery Recovery, exit key.Binding) Model {
+	return Model{
+		err:      err,
+		recovery: recovery,
+		exit:     exit,
+	}
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func retry() tea.Msg {
+	return RetryMsg{}
+}
+
+func (m Model) login() tea.Cmd {
+	args := strings.Fields(m.recovery.LoginCommand)
+	if len(args) == 0 {
+		return func() tea.Msg {
+			return loginFinishedMsg{errors.New("no login command configured")}
+		}
+	}
+	reload := m.recovery.ReloadCredentials
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		if err == nil && reload != nil {
+			err = reload()
+		}
+		return loginFinishedMsg{err}
+	})
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+	case loginFinishedMsg:
+		m.loggingIn = false
+		if msg.err != nil {
+			logger.Printf("login command failed: %v\n", msg.err)
+			m.loginErr = msg.err
+			return m, nil
+		}
+		m.loginErr = nil
+		return m, retry
+	case tea.KeyMsg:
+		if m.loggingIn {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Error.Retry):
+			return m, retry
+		case key.Matches(msg, keys.Map.Error.Login):
+			m.loggingIn = true
+			return m, m.login()
+		}
+	}
+	return m, nil
+}
+
+// IsCredentialError reports whether err looks like missing or expired AWS
+// credentials, in which case logging in again is the likely fix.
+func IsCredentialError(err error) bool {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		if slices.Contains(expiredCredentialCodes, aerr.Code()) {
+			return true
+		}
+		if orig := aerr.OrigErr(); orig != nil {
+			return IsCredentialError(orig)
+		}
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "token has expired")
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{
+		keys.Map.Error.Retry,
+		keys.Describe(keys.Map.Error.Login, fmt.Sprintf("run `%s`", m.recovery.LoginCommand)),
+		m.exit,
+	}
+}
+
+func (m Model) View() string {
+	width := m.width - 10
+	if width < 40 {
+		width = 40
+	}
+	lines := []string{
+		theme.Current.ErrorTitle.Render("Request failed"),
+		"",
+		theme.Current.Text.Render(wordwrap.String(m.err.Error(), width-6)),
+	}
+	if IsCredentialError(m.err) {
+		lines = append(lines, "", theme.Current.Warning.Render(fmt.Sprintf("Your AWS credentials look expired, press %s to log in again.", keys.Map.Error.Login.Help().Key)))
+	}
+	if m.loggingIn {
+		lines = append(lines, "", theme.Current.HelpDesc.Render(fmt.Sprintf("running %q...", m.recovery.LoginCommand)))
+	}
+	if m.loginErr != nil {
+		lines = append(lines, "", theme.Current.Warning.Render(wordwrap.String(fmt.Sprintf("login failed: %v", m.loginErr), width-6)))
+	}
+
+	lines = append(lines, "", keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...))
+
+	return boxStyle.Copy().BorderForeground(theme.Current.Border).Width(width).Render(strings.Join(lines, "\n"))
+}
@@ -5,12 +5,15 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mtyurt/ecstui/logger"
-	"github.com/muesli/reflow/wordwrap"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/layout"
+	"github.com/mtyurt/ecstui/tui/theme"
 )
 
 var (
@@ -25,16 +28,6 @@ var (
 		b.Left = "┤"
 		return titleStyle.Copy().BorderStyle(b)
 	}()
-
-	timestampStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "236", Dark: "248"})
-
-	filterPrompt = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#ECFD65"})
-
-	filterCursor = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#EE6FF8", Dark: "#EE6FF8"})
-
-	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B"))
 )
 
 type Model struct {
@@ -45,28 +38,26 @@ type Model struct {
 	filterEnabled bool
 }
 
+// New creates the events screen, filling width and height.
 func New(title string, width, height int, events []*ecs.ServiceEvent) Model {
-	view := viewport.New(width-10, height)
-	view.SetYOffset(1)
 	filterInput := textinput.New()
 	filterInput.Prompt = "Filter: "
-	filterInput.Width = width - 20 - len(filterInput.Prompt)
 	filterInput.Focus()
 
 	m := Model{
-		eventsView:  view,
-		title:       title + "\t" + helpStyle.Render("Press / to filter"),
+		eventsView:  keys.NewViewport(0, 0),
+		title:       title + "\t" + theme.Current.Subtle.Render(fmt.Sprintf("Press %s to filter", keys.Map.Events.Filter.Help().Key)),
 		events:      events,
 		filterInput: filterInput,
 	}
 
-	m.updateContent()
+	m.SetSize(width, height)
 	return m
 }
 
 func wrapEventMessage(message string, width, padding int) string {
 	wrapPrefix := strings.Repeat(" ", padding)
-	wrapped := wordwrap.String(message, width)
+	wrapped := layout.WrapText(message, width)
 	lines := strings.Split(wrapped, "\n")
 	return strings.Join(lines, "\n"+wrapPrefix)
 }
@@ -80,7 +71,7 @@ func (m *Model) updateContent() {
 		}
 
 		msg := wrapEventMessage(*event.Message, width-30, 24)
-		timestamp := timestampStyle.Render(event.CreatedAt.Format("2006-01-02 15:04:05.000"))
+		timestamp := theme.Current.Subtle.Render(event.CreatedAt.Format("2006-01-02 15:04:05.000"))
 		if m.filterEnabled {
 			msg = highlightOccurencesCaseInsensitive(msg, m.filterInput.Value())
 		}
@@ -109,7 +100,7 @@ func highlightOccurencesCaseInsensitive(a, b string) string {
 		}
 		// Append the original text plus the highlighted occurrence
 		result.WriteString(a[lastIndex : lastIndex+index])
-		result.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render(a[lastIndex+index : lastIndex+index+len(b)]))
+		result.WriteString(theme.Current.Highlight.Render(a[lastIndex+index : lastIndex+index+len(b)]))
 		lastIndex += index + len(b)
 	}
 	// Append any remaining text after the last occurrence
@@ -118,14 +109,27 @@ func highlightOccurencesCaseInsensitive(a, b string) string {
 	return result.String()
 }
 
-func (m Model) SetSize(width, height int) {
-	// m.eventsView.Width = width
-	// m.eventsView.Height = height
-	// m.filterInput.Width = width - 10 - len(m.filterInput.Prompt)
+// SetSize fits the screen into width and height, the margins and the boxed
+// header and footer take 4 columns and 9 lines of them.
+func (m *Model) SetSize(width, height int) {
+	logger.Printf("events set size: %d, %d\n", width, height)
+	m.eventsView.Width = max(width-4, 20)
+	m.eventsView.Height = max(height-9, 1)
+	m.filterInput.Width = max(m.eventsView.Width-10-len(m.filterInput.Prompt), 1)
+	m.updateContent()
 }
 func (m Model) Focused() bool {
 	return !m.filterEnabled
 }
+
+// Help lists the keys of the screen, esc clears the filter while typing one.
+func (m Model) Help() []key.Binding {
+	if m.filterEnabled {
+		return []key.Binding{keys.Describe(keys.Map.Global.Back, "clear filter")}
+	}
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Events.Filter, keys.Map.Global.Back}
+}
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -142,32 +146,20 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	newFilter := false
 	if msg, ok := msg.(tea.KeyMsg); ok {
 		if !m.filterEnabled {
-			switch msg.String() {
-			case "/":
+			if key.Matches(msg, keys.Map.Events.Filter) {
 				m.filterEnabled = true
 				m.filterInput.Focus()
 
 				newFilter = true
 			}
-		} else {
-			switch msg.String() {
-			case "esc":
-				m.filterEnabled = false
-				m.clearFilter()
-			}
+		} else if key.Matches(msg, keys.Map.Global.Back) {
+			m.filterEnabled = false
+			m.clearFilter()
 		}
 	}
 
-	if msg, ok := msg.(tea.WindowSizeMsg); ok {
-		logger.Printf("events set window size: %d, %d\n", msg.Width, msg.Height)
-		m.SetSize(msg.Width, msg.Height)
-		m.eventsView.Width = msg.Width - 10
-		// m.eventsView.Height = msg.Height
-		m.filterInput.Width = msg.Width - 20 - len(m.filterInput.Prompt)
-		m.updateContent()
-	}
-
-	if m.filterEnabled && !newFilter {
+	// the wheel scrolls the events while they are filtered too
+	if _, ok := msg.(tea.MouseMsg); m.filterEnabled && !newFilter && !ok {
 		newFilterInputModel, inputCmd := m.filterInput.Update(msg)
 		m.filterInput = newFilterInputModel
 		cmds = append(cmds, inputCmd)
@@ -203,6 +195,6 @@ func max(a, b int) int {
 	return b
 }
 func (m Model) View() string {
-	return lipgloss.NewStyle().Margin(5, 1).Width(m.eventsView.Width + 2).Height(m.eventsView.Height + 3).Render(
+	return lipgloss.NewStyle().Margin(1, 1).Width(m.eventsView.Width + 2).Height(m.eventsView.Height + 3).Render(
 		fmt.Sprintf("%s\n%s\n%s\n", m.headerView(), m.eventsView.View(), m.footerView()))
 }
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtyurt/ecstui/internal/fixtures"
+	"github.com/mtyurt/ecstui/internal/golden"
+)
+
+func TestView(t *testing.T) {
+	for _, width := range golden.Widths {
+		for _, scenario := range fixtures.Scenarios() {
+			t.Run(fmt.Sprintf("%s/%d", scenario.Name, width), func(t *testing.T) {
+				m := New("staging-api events", width, 20, scenario.Service.Ecs.Events)
+				golden.Assert(t, fmt.Sprintf("%s_%d", scenario.Name, width), m.View())
+			})
+		}
+	}
+}
+
+func TestViewFiltered(t *testing.T) {
+	m := New("staging-api events", 120, 20, fixtures.FailedRollout().Service.Ecs.Events)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "rolling" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	golden.Assert(t, "filtered_120", m.View())
+}
@@ -0,0 +1,227 @@
+package images
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	humanizer "github.com/dustin/go-humanize"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// severities in the order ECR reports them, most severe first
+var severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFORMATIONAL", "UNDEFINED"}
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+)
+
+// Group is a task set or deployment whose images are shown together.
+type Group struct {
+	Label             string
+	TaskDefinitionArn string
+	Tasks             []*ecs.Task
+}
+
+type Model struct {
+	groups   []Group
+	fetcher  types.ImageDetailsFetcher
+	details  [][]types.ImageDetails
+	errs     []error
+	viewport viewport.Model
+	state    sessionState
+	spinner  spinnertui.Model
+	width    int
+	height   int
+}
+
+type loadedMsg struct {
+	details [][]types.ImageDetails
+	errs    []error
+}
+
+func New(fetcher types.ImageDetailsFetcher, groups []Group, width, height int) Model {
+	m := Model{
+		groups:   groups,
+		fetcher:  fetcher,
+		spinner:  spinnertui.New("Loading image details"),
+		viewport: keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.viewport.SetContent(m.renderGroups())
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching image details")
+	defer logger.Println("finished fetching image details")
+	msg := loadedMsg{
+		details: make([][]types.ImageDetails, len(m.groups)),
+		errs:    make([]error, len(m.groups)),
+	}
+	for i, group := range m.groups {
+		msg.details[i], msg.errs[i] = m.fetcher(group.TaskDefinitionArn, group.Tasks)
+	}
+	return msg
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.details = msg.details
+		m.errs = msg.errs
+		m.state = loaded
+		m.viewport.SetContent(m.renderGroups())
+		return m, nil
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderGroups() string {
+	sections := []string{}
+	for i, group := range m.groups {
+		lines := []string{theme.Current.Title.Render(group.Label) + " " + theme.Current.Subtle.Render(utils.GetLastItemAfterSplit(group.TaskDefinitionArn, "/"))}
+		if m.errs[i] != nil {
+			lines = append(lines, theme.Current.Warning.Render("⚠ "+m.errs[i].Error()))
+		}
+		for _, detail := range m.details[i] {
+			lines = append(lines, renderImage(detail)...)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func renderImage(detail types.ImageDetails) []string {
+	lines := []string{
+		"",
+		theme.Current.Header.Render("container " + detail.Container),
+		"  image: " + detail.Image,
+	}
+
+	digests := make([]string, 0, len(detail.Digests))
+	for digest := range detail.Digests {
+		digests = append(digests, digest)
+	}
+	// most used digest first, the odd ones out are the interesting ones
+	slices.SortFunc(digests, func(i, j string) int {
+		if c := len(detail.Digests[j]) - len(detail.Digests[i]); c != 0 {
+			return c
+		}
+		return strings.Compare(i, j)
+	})
+	if len(digests) > 1 {
+		lines = append(lines, theme.Current.Warning.Render(fmt.Sprintf("  ⚠ tasks run %d different digests of this image", len(digests))))
+	}
+	ecrByDigest := make(map[string]types.ECRImageDetails)
+	for _, image := range detail.ECR {
+		ecrByDigest[image.Digest] = image
+	}
+	for _, digest := range digests {
+		taskIDs := detail.Digests[digest]
+		lines = append(lines, fmt.Sprintf("  %s %s", digest, theme.Current.Subtle.Render(fmt.Sprintf("%d task(s): %s", len(taskIDs), strings.Join(taskIDs, ", ")))))
+		if image, ok := ecrByDigest[digest]; ok {
+			lines = append(lines, renderECR(image)...)
+			delete(ecrByDigest, digest)
+		}
+	}
+	// images resolved from the tag because no task reported a digest yet
+	for _, image := range detail.ECR {
+		if _, ok := ecrByDigest[image.Digest]; ok {
+			lines = append(lines, fmt.Sprintf("  %s %s", image.Digest, theme.Current.Subtle.Render("not running")))
+			lines = append(lines, renderECR(image)...)
+		}
+	}
+	if detail.ECRErr != nil {
+		lines = append(lines, theme.Current.Warning.Render("  ⚠ ecr: "+strings.SplitN(detail.ECRErr.Error(), "\n", 2)[0]))
+	}
+	return lines
+}
+
+func renderECR(image types.ECRImageDetails) []string {
+	if image.Missing {
+		return []string{theme.Current.Warning.Render("    ⚠ no longer in the repository")}
+	}
+	lines := []string{
+		theme.Current.Subtle.Render(fmt.Sprintf("    pushed %s (%s), %s, tags: %s",
+			image.PushedAt.Local().Format("2006-01-02 15:04:05"),
+			humanizer.Time(image.PushedAt),
+			humanizer.Bytes(uint64(image.SizeBytes)),
+			strings.Join(image.Tags, ", "))),
+	}
+
+	scan := "    scan: " + image.ScanStatus
+	counts := []string{}
+	for _, severity := range severities {
+		count, ok := image.Findings[severity]
+		if !ok || count == 0 {
+			continue
+		}
+		style := theme.Current.Warning
+		if severity == "CRITICAL" || severity == "HIGH" {
+			style = theme.Current.Failed
+		}
+		counts = append(counts, style.Render(fmt.Sprintf("%s %d", severity, count)))
+	}
+	if len(counts) > 0 {
+		scan = scan + ", " + strings.Join(counts, ", ")
+	} else if image.ScanStatus == "COMPLETE" {
+		scan = scan + ", " + theme.Current.OK.Render("no findings")
+	}
+	return append(lines, scan)
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	if m.state == initial {
+		return m.spinner.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render("images"), "", m.viewport.View(), "", m.footerView())
+}
@@ -0,0 +1,272 @@
+package instances
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/tasks"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+)
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+type Model struct {
+	cluster          string
+	instancesFetcher types.ContainerInstancesFetcher
+	tasksFetcher     types.InstanceTasksFetcher
+	// focusArn is selected once the instances are loaded, when the view is
+	// opened from a task.
+	focusArn      string
+	instances     []*ecs.ContainerInstance
+	table         table.Model
+	tasksView     *tasks.Model
+	state         sessionState
+	err           error
+	spinner       spinnertui.Model
+	width, height int
+}
+
+type loadedMsg []*ecs.ContainerInstance
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+// New lists the container instances of the cluster. focusArn, if set, is
+// selected and its tasks are opened once the list is loaded.
+func New(cluster string, instancesFetcher types.ContainerInstancesFetcher, tasksFetcher types.InstanceTasksFetcher, focusArn string, width, height int) Model {
+	m := Model{
+		cluster:          cluster,
+		instancesFetcher: instancesFetcher,
+		tasksFetcher:     tasksFetcher,
+		focusArn:         focusArn,
+		table:            keys.NewTable(table.WithFocused(true), table.WithStyles(theme.Current.Table())),
+		spinner:          spinnertui.New(fmt.Sprintf("Loading %s container instances", cluster)),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.table.SetColumns([]table.Column{
+		{Title: "instance", Width: 20},
+		{Title: "status", Width: 10},
+		{Title: "agent", Width: 16},
+		{Title: "type", Width: 12},
+		{Title: "zone", Width: 12},
+		{Title: "cpu free", Width: 12},
+		{Title: "memory free", Width: 14},
+		{Title: "ports used", Width: 10},
+		{Title: "tasks", Width: max(width-20-10-16-12-12-12-14-10-16, 10)},
+	})
+	m.table.SetWidth(width)
+	m.table.SetHeight(max(height-6, 3))
+	if m.tasksView != nil {
+		m.tasksView.SetSize(width, height)
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching container instances of", m.cluster)
+	defer logger.Println("finished fetching container instances of", m.cluster)
+	instances, err := m.instancesFetcher(m.cluster)
+	if err != nil {
+		return errMsg{err}
+	}
+	return loadedMsg(instances)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view, it closes the tasks of
+// an instance first.
+func (m Model) Focused() bool {
+	return m.tasksView == nil
+}
+
+func (m *Model) openTasks(instance *ecs.ContainerInstance) tea.Cmd {
+	cluster, arn, fetcher := m.cluster, aws.StringValue(instance.ContainerInstanceArn), m.tasksFetcher
+	title := "tasks on " + aws.StringValue(instance.Ec2InstanceId)
+	view := tasks.New(title, func() ([]*ecs.Task, error) { return fetcher(cluster, arn) }, false, m.width, m.height)
+	m.tasksView = &view
+	return view.Init()
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.instances = msg
+		m.state = loaded
+		m.updateRows()
+		if m.focusArn != "" {
+			for i, instance := range m.instances {
+				if aws.StringValue(instance.ContainerInstanceArn) == m.focusArn {
+					m.table.SetCursor(i)
+					m.focusArn = ""
+					return m, m.openTasks(instance)
+				}
+			}
+		}
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if m.tasksView != nil {
+			if key.Matches(msg, keys.Map.Global.Back) && m.tasksView.Focused() {
+				m.tasksView = nil
+				return m, nil
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Instances.Tasks):
+			if m.state == loaded && len(m.instances) > 0 {
+				return m, m.openTasks(m.instances[m.table.Cursor()])
+			}
+		case key.Matches(msg, keys.Map.Common.Reload):
+			if m.state != initial {
+				m.state = initial
+				return m, m.Init()
+			}
+		}
+	}
+
+	if m.tasksView != nil {
+		tasksView, cmd := m.tasksView.Update(msg)
+		m.tasksView = &tasksView
+		return m, cmd
+	}
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.table, cmd = m.table.Update(msg)
+	}
+	return m, cmd
+}
+
+func resourceValue(resources []*ecs.Resource, name string) int64 {
+	for _, r := range resources {
+		if aws.StringValue(r.Name) == name {
+			return aws.Int64Value(r.IntegerValue)
+		}
+	}
+	return 0
+}
+
+func resourceSet(resources []*ecs.Resource, name string) []*string {
+	for _, r := range resources {
+		if aws.StringValue(r.Name) == name {
+			return r.StringSetValue
+		}
+	}
+	return nil
+}
+
+func attribute(instance *ecs.ContainerInstance, name string) string {
+	for _, a := range instance.Attributes {
+		if aws.StringValue(a.Name) == name {
+			return aws.StringValue(a.Value)
+		}
+	}
+	return ""
+}
+
+func (m *Model) updateRows() {
+	rows := make([]table.Row, 0, len(m.instances))
+	for _, instance := range m.instances {
+		agent := "disconnected"
+		if aws.BoolValue(instance.AgentConnected) {
+			agent = "connected"
+		}
+		if instance.VersionInfo != nil {
+			agent = agent + " " + aws.StringValue(instance.VersionInfo.AgentVersion)
+		}
+		// the remaining PORTS set lists the reserved ports plus the ones in
+		// use by tasks
+		portsUsed := len(resourceSet(instance.RemainingResources, "PORTS")) - len(resourceSet(instance.RegisteredResources, "PORTS"))
+		rows = append(rows, table.Row{
+			aws.StringValue(instance.Ec2InstanceId),
+			aws.StringValue(instance.Status),
+			agent,
+			attribute(instance, "ecs.instance-type"),
+			attribute(instance, "ecs.availability-zone"),
+			fmt.Sprintf("%d/%d", resourceValue(instance.RemainingResources, "CPU"), resourceValue(instance.RegisteredResources, "CPU")),
+			fmt.Sprintf("%d/%d", resourceValue(instance.RemainingResources, "MEMORY"), resourceValue(instance.RegisteredResources, "MEMORY")),
+			fmt.Sprintf("%d", max(portsUsed, 0)),
+			fmt.Sprintf("%d running, %d pending", aws.Int64Value(instance.RunningTasksCount), aws.Int64Value(instance.PendingTasksCount)),
+		})
+	}
+	m.table.SetRows(rows)
+	m.table.SetHeight(max(m.height-6-len(m.warnings()), 3))
+}
+
+// warnings lists instances that cannot take new tasks.
+func (m Model) warnings() []string {
+	warnings := []string{}
+	for _, instance := range m.instances {
+		id := aws.StringValue(instance.Ec2InstanceId)
+		if !aws.BoolValue(instance.AgentConnected) {
+			warnings = append(warnings, theme.Current.Warning.Render("⚠ agent of "+id+" is disconnected"))
+		}
+		if aws.StringValue(instance.Status) == ecs.ContainerInstanceStatusDraining {
+			warnings = append(warnings, theme.Current.Warning.Render("⚠ "+id+" is draining"))
+		}
+	}
+	return warnings
+}
+
+// Help lists the keys of the instance list, or of the open tasks of an
+// instance.
+func (m Model) Help() []key.Binding {
+	if m.tasksView != nil {
+		return m.tasksView.Help()
+	}
+	return []key.Binding{keys.Map.Common.Select(), keys.Map.Instances.Tasks, keys.Map.Common.Reload, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	if m.tasksView != nil {
+		return m.tasksView.View()
+	}
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	if len(m.instances) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render(m.cluster), "", theme.Current.Subtle.Render("no container instances, the cluster runs on Fargate only"), "", m.footerView())
+	}
+	header := theme.Current.Title.Render(m.cluster) + " " + theme.Current.Subtle.Render(fmt.Sprintf("%d container instances", len(m.instances)))
+	rows := []string{header, ""}
+	rows = append(rows, m.warnings()...)
+	rows = append(rows, m.table.View(), "", m.footerView())
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,418 @@
+// Package keys holds the key bindings of every screen. Screens match keys
+// and build their help from the same bindings, so overriding one from the
+// config file changes both.
+package keys
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Global keys work on every screen.
+type Global struct {
+	ForceQuit key.Binding
+	// Quit leaves the program from the service list and its error screen.
+	Quit key.Binding
+	Back key.Binding
+	Help key.Binding
+}
+
+// Common keys are shared by the screens opened from the service screen. Up
+// and Down also move their viewports and tables, see NewViewport and
+// NewTable.
+type Common struct {
+	Reload key.Binding
+	Up     key.Binding
+	Down   key.Binding
+}
+
+// Scroll describes Up and Down on a screen that scrolls.
+func (c Common) Scroll() key.Binding {
+	return c.upDown("scroll")
+}
+
+// Select describes Up and Down on a screen that moves a selection.
+func (c Common) Select() key.Binding {
+	return c.upDown("select")
+}
+
+func (c Common) upDown(desc string) key.Binding {
+	return pair(c.Up, c.Down, desc)
+}
+
+// pair describes two bindings as one, e.g. "↑/↓ scroll".
+func pair(a, b key.Binding, desc string) key.Binding {
+	return key.NewBinding(
+		key.WithKeys(append(a.Keys(), b.Keys()...)...),
+		key.WithHelp(a.Help().Key+"/"+b.Help().Key, desc),
+	)
+}
+
+// NewViewport creates a viewport scrolled with Common.Up and Common.Down.
+func NewViewport(width, height int) viewport.Model {
+	vp := viewport.New(width, height)
+	vp.KeyMap.Up, vp.KeyMap.Down = Map.Common.Up, Map.Common.Down
+	return vp
+}
+
+// NewTable creates a table moving its cursor with Common.Up and Common.Down.
+func NewTable(opts ...table.Option) table.Model {
+	keyMap := table.DefaultKeyMap()
+	keyMap.LineUp, keyMap.LineDown = Map.Common.Up, Map.Common.Down
+	return table.New(append(opts, table.WithKeyMap(keyMap))...)
+}
+
+// Service keys open the screens of a service from its overview.
+type Service struct {
+	AutoRefresh    key.Binding
+	Refresh        key.Binding
+	Events         key.Binding
+	TaskDefinition key.Binding
+	Diff           key.Binding
+	Revisions      key.Binding
+	Images         key.Binding
+	Scaling        key.Binding
+	Placement      key.Binding
+	Tasks          key.Binding
+	Instances      key.Binding
+	Network        key.Binding
+	Targets        key.Binding
+	Rollback       key.Binding
+	Actions        key.Binding
+}
+
+// Sections keys move the focus between the sections of the service screen
+// and open the focused one in its own screen.
+type Sections struct {
+	Next key.Binding
+	Prev key.Binding
+	Open key.Binding
+}
+
+// Cycle describes Next and Prev.
+func (s Sections) Cycle() key.Binding {
+	return pair(s.Prev, s.Next, "focus section")
+}
+
+// TaskTable keys pick a task set or deployment box on the service screen and
+// work its task table. Common.Up and Common.Down move the table's cursor.
+type TaskTable struct {
+	NextBox  key.Binding
+	PrevBox  key.Binding
+	NextPage key.Binding
+	PrevPage key.Binding
+	Sort     key.Binding
+	Collapse key.Binding
+}
+
+// Boxes describes NextBox and PrevBox.
+func (t TaskTable) Boxes() key.Binding {
+	return pair(t.PrevBox, t.NextBox, "focus task set")
+}
+
+// Pages describes NextPage and PrevPage.
+func (t TaskTable) Pages() key.Binding {
+	return pair(t.PrevPage, t.NextPage, "page")
+}
+
+type List struct {
+	Open key.Binding
+}
+
+type Events struct {
+	Filter key.Binding
+}
+
+type Tasks struct {
+	Detail   key.Binding
+	Instance key.Binding
+	Stop     key.Binding
+	Exec     key.Binding
+}
+
+type Instances struct {
+	Tasks key.Binding
+}
+
+type TaskDef struct {
+	NextRight   key.Binding
+	NextLeft    key.Binding
+	ChangesOnly key.Binding
+}
+
+type Revisions struct {
+	View key.Binding
+	Mark key.Binding
+	Diff key.Binding
+}
+
+type Rollback struct {
+	Preview key.Binding
+}
+
+type Actions struct {
+	Choose  key.Binding
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+type Error struct {
+	Retry key.Binding
+	Login key.Binding
+}
+
+type KeyMap struct {
+	Global    Global
+	Common    Common
+	Service   Service
+	Sections  Sections
+	TaskTable TaskTable
+	List      List
+	Events    Events
+	Tasks     Tasks
+	Instances Instances
+	TaskDef   TaskDef
+	Revisions Revisions
+	Rollback  Rollback
+	Actions   Actions
+	Error     Error
+}
+
+// Map is the key map in use, the defaults with the config file's overrides
+// applied.
+var Map = Default()
+
+// ctrl binds ctrl+<letter> and, for terminals reporting it, ctrl+shift+<letter>.
+func ctrl(letter, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys("ctrl+"+letter, "ctrl+shift+"+letter), key.WithHelp("ctrl+"+letter, desc))
+}
+
+func bind(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], desc))
+}
+
+func Default() KeyMap {
+	return KeyMap{
+		Global: Global{
+			ForceQuit: bind("quit", "ctrl+c"),
+			Quit:      bind("quit", "q"),
+			Back:      bind("back", "esc"),
+			Help:      bind("help", "?"),
+		},
+		Common: Common{
+			Reload: bind("reload", "r"),
+			Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑", "up")),
+			Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓", "down")),
+		},
+		Service: Service{
+			AutoRefresh:    ctrl("t", "auto refresh"),
+			Refresh:        ctrl("r", "manual refresh"),
+			Events:         ctrl("e", "events"),
+			TaskDefinition: ctrl("f", "task definition"),
+			Diff:           ctrl("d", "task definition diff"),
+			Revisions:      ctrl("v", "revisions"),
+			Images:         ctrl("g", "images"),
+			Scaling:        ctrl("a", "auto scaling"),
+			Placement:      ctrl("p", "placement"),
+			Tasks:          ctrl("k", "tasks"),
+			Instances:      ctrl("n", "container instances"),
+			Network:        ctrl("w", "network"),
+			Targets:        ctrl("b", "targets"),
+			Rollback:       ctrl("o", "roll back"),
+			Actions:        ctrl("x", "actions"),
+		},
+		Sections: Sections{
+			Next: bind("next section", "tab"),
+			Prev: bind("previous section", "shift+tab"),
+			Open: bind("open", "enter"),
+		},
+		TaskTable: TaskTable{
+			NextBox:  bind("next task set", "]"),
+			PrevBox:  bind("previous task set", "["),
+			NextPage: bind("next page", "right", "pgdown"),
+			PrevPage: bind("previous page", "left", "pgup"),
+			Sort:     bind("sort", "s"),
+			Collapse: bind("collapse", "c"),
+		},
+		List: List{
+			Open: bind("open", "enter"),
+		},
+		Events: Events{
+			Filter: bind("filter", "/"),
+		},
+		Tasks: Tasks{
+			Detail:   bind("detail", "enter"),
+			Instance: bind("container instance", "i"),
+			Stop:     bind("stop task", "s"),
+			Exec:     bind("exec", "x"),
+		},
+		Instances: Instances{
+			Tasks: bind("tasks", "enter"),
+		},
+		TaskDef: TaskDef{
+			NextRight:   bind("next right", "tab"),
+			NextLeft:    bind("next left", "shift+tab"),
+			ChangesOnly: bind("changes only", "c"),
+		},
+		Revisions: Revisions{
+			View: bind("view", "enter"),
+			Mark: key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark")),
+			Diff: bind("diff with marked or in use", "d"),
+		},
+		Rollback: Rollback{
+			Preview: bind("preview", "enter"),
+		},
+		Actions: Actions{
+			Choose:  bind("choose", "enter"),
+			Confirm: bind("run", "y"),
+			Cancel:  bind("cancel", "n"),
+		},
+		Error: Error{
+			Retry: bind("retry", "r"),
+			Login: bind("log in", "l"),
+		},
+	}
+}
+
+// bindings names every binding that can be overridden, as "<screen>.<action>"
+// in lower camel case.
+func (k *KeyMap) bindings() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"global.forceQuit":       &k.Global.ForceQuit,
+		"global.quit":            &k.Global.Quit,
+		"global.back":            &k.Global.Back,
+		"global.help":            &k.Global.Help,
+		"common.reload":          &k.Common.Reload,
+		"common.up":              &k.Common.Up,
+		"common.down":            &k.Common.Down,
+		"service.autoRefresh":    &k.Service.AutoRefresh,
+		"service.refresh":        &k.Service.Refresh,
+		"service.events":         &k.Service.Events,
+		"service.taskDefinition": &k.Service.TaskDefinition,
+		"service.diff":           &k.Service.Diff,
+		"service.revisions":      &k.Service.Revisions,
+		"service.images":         &k.Service.Images,
+		"service.scaling":        &k.Service.Scaling,
+		"service.placement":      &k.Service.Placement,
+		"service.tasks":          &k.Service.Tasks,
+		"service.instances":      &k.Service.Instances,
+		"service.network":        &k.Service.Network,
+		"service.targets":        &k.Service.Targets,
+		"service.rollback":       &k.Service.Rollback,
+		"service.actions":        &k.Service.Actions,
+		"sections.next":          &k.Sections.Next,
+		"sections.prev":          &k.Sections.Prev,
+		"sections.open":          &k.Sections.Open,
+		"taskTable.nextBox":      &k.TaskTable.NextBox,
+		"taskTable.prevBox":      &k.TaskTable.PrevBox,
+		"taskTable.nextPage":     &k.TaskTable.NextPage,
+		"taskTable.prevPage":     &k.TaskTable.PrevPage,
+		"taskTable.sort":         &k.TaskTable.Sort,
+		"taskTable.collapse":     &k.TaskTable.Collapse,
+		"list.open":              &k.List.Open,
+		"events.filter":          &k.Events.Filter,
+		"tasks.detail":           &k.Tasks.Detail,
+		"tasks.instance":         &k.Tasks.Instance,
+		"tasks.stop":             &k.Tasks.Stop,
+		"tasks.exec":             &k.Tasks.Exec,
+		"instances.tasks":        &k.Instances.Tasks,
+		"taskDef.nextRight":      &k.TaskDef.NextRight,
+		"taskDef.nextLeft":       &k.TaskDef.NextLeft,
+		"taskDef.changesOnly":    &k.TaskDef.ChangesOnly,
+		"revisions.view":         &k.Revisions.View,
+		"revisions.mark":         &k.Revisions.Mark,
+		"revisions.diff":         &k.Revisions.Diff,
+		"rollback.preview":       &k.Rollback.Preview,
+		"actions.choose":         &k.Actions.Choose,
+		"actions.confirm":        &k.Actions.Confirm,
+		"actions.cancel":         &k.Actions.Cancel,
+		"error.retry":            &k.Error.Retry,
+		"error.login":            &k.Error.Login,
+	}
+}
+
+// Names lists the names Apply accepts, sorted.
+func Names() []string {
+	k := Default()
+	names := []string{}
+	for name := range k.bindings() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Apply replaces the keys of the named bindings, e.g. "service.events" with
+// ["e"]. The first key is the one shown in help. An empty list unbinds the
+// action.
+func (k *KeyMap) Apply(overrides map[string][]string) error {
+	bindings := k.bindings()
+	unknown := []string{}
+	for name, keys := range overrides {
+		binding, ok := bindings[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if len(keys) == 0 {
+			binding.SetEnabled(false)
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(keys[0], binding.Help().Desc)
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown key bindings %s, the known ones are %s", strings.Join(unknown, ", "), strings.Join(Names(), ", "))
+	}
+	return nil
+}
+
+// Describe returns binding with desc as its help, for a key whose meaning
+// depends on the screen, e.g. esc cancelling rather than going back.
+func Describe(binding key.Binding, desc string) key.Binding {
+	binding.SetHelp(binding.Help().Key, desc)
+	return binding
+}
+
+// Clickable reports whether the help of binding names one of its keys, which
+// Press can then send. Pairs like "↑/↓ scroll" name none.
+func Clickable(binding key.Binding) bool {
+	return binding.Enabled() && slices.Contains(binding.Keys(), binding.Help().Key)
+}
+
+// Press returns the message of pressing k, a key the way bindings name it,
+// e.g. "ctrl+e", "esc" or "s", to act on a binding clicked in a footer.
+func Press(k string) tea.KeyMsg {
+	// the named keys are the control characters and the negative key types
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if t != tea.KeyRunes && (tea.Key{Type: t}).String() == k {
+			return tea.KeyMsg{Type: t}
+		}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// Render lays out the help of the enabled bindings in one line, the way
+// screen footers show them.
+func Render(keyStyle, descStyle lipgloss.Style, bindings ...key.Binding) string {
+	return strings.Join(Fields(keyStyle, descStyle, bindings...), " • ")
+}
+
+// Fields renders the help of each enabled binding.
+func Fields(keyStyle, descStyle lipgloss.Style, bindings ...key.Binding) []string {
+	fields := []string{}
+	for _, b := range bindings {
+		if b.Enabled() {
+			fields = append(fields, fmt.Sprintf("%s %s", keyStyle.Render(b.Help().Key), descStyle.Render(b.Help().Desc)))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,61 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestApply(t *testing.T) {
+	k := Default()
+	err := k.Apply(map[string][]string{
+		"service.events": {"e", "ctrl+e"},
+		"tasks.exec":     {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, k.Service.Events) {
+		t.Error("service.events does not match e")
+	}
+	if k.Service.Events.Help().Key != "e" || k.Service.Events.Help().Desc != "events" {
+		t.Errorf("service.events help is %+v", k.Service.Events.Help())
+	}
+	if k.Tasks.Exec.Enabled() {
+		t.Error("tasks.exec is still enabled")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, Default().Service.Events) {
+		t.Error("the defaults changed")
+	}
+}
+
+func TestApplyUnknown(t *testing.T) {
+	k := Default()
+	err := k.Apply(map[string][]string{"service.event": {"e"}, "service.diff": {"d"}})
+	if err == nil {
+		t.Fatal("no error for an unknown binding")
+	}
+	if !strings.Contains(err.Error(), "unknown key bindings service.event,") {
+		t.Errorf("error does not name the unknown binding: %v", err)
+	}
+	if !strings.Contains(err.Error(), "service.events") {
+		t.Errorf("error does not list the known bindings: %v", err)
+	}
+}
+
+func TestPress(t *testing.T) {
+	k := Default()
+	for _, binding := range []key.Binding{k.Service.Events, k.Global.Back, k.Sections.Open, k.TaskTable.Sort, k.Events.Filter} {
+		if !Clickable(binding) {
+			t.Errorf("%q is not clickable", binding.Help().Key)
+		}
+		if msg := Press(binding.Help().Key); !key.Matches(msg, binding) {
+			t.Errorf("pressing %q sends %q", binding.Help().Key, msg)
+		}
+	}
+	if Clickable(k.Common.Scroll()) {
+		t.Error("↑/↓ is clickable")
+	}
+}
@@ -0,0 +1,118 @@
+// Package layout arranges the boxes of a screen for the terminal's width:
+// side by side while they fit, wrapped into rows or stacked when they do not.
+package layout
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Breakpoint is the width under which screens stack their sections instead
+// of putting them side by side.
+const Breakpoint = 100
+
+// Stacked reports whether a screen of width stacks its sections.
+func Stacked(width int) bool {
+	return width < Breakpoint
+}
+
+// Share splits width between n boxes which take extra columns each for
+// their border and margin, and returns the width of one box, kept between
+// min and max. Boxes that do not fit at min wrap, see Flow.
+func Share(width, n, extra, min, max int) int {
+	if n < 1 {
+		n = 1
+	}
+	w := width/n - extra
+	if w > max {
+		w = max
+	}
+	if w < min {
+		w = min
+	}
+	return w
+}
+
+// Flow joins boxes left to right, aligned on pos, and starts a new row
+// whenever the next box would overflow width. Rows are centered.
+func Flow(width int, pos lipgloss.Position, boxes ...string) string {
+	rows := []string{}
+	for _, row := range Wrap(width, boxes...) {
+		rows = append(rows, lipgloss.JoinHorizontal(pos, row...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, rows...)
+}
+
+// Wrap groups boxes into the rows Flow lays them out in.
+func Wrap(width int, boxes ...string) [][]string {
+	rows := [][]string{}
+	row := []string{}
+	rowWidth := 0
+	for _, box := range boxes {
+		w := lipgloss.Width(box)
+		if len(row) > 0 && rowWidth+w > width {
+			rows = append(rows, row)
+			row, rowWidth = nil, 0
+		}
+		row = append(row, box)
+		rowWidth += w
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Center centers the block s in width, shifting all its lines alike. Unlike
+// rendering s with a centered style of that width it does not rewrap s,
+// which drops the trailing spaces of the last line only and so shifts it
+// off the lines above.
+func Center(width int, s string) string {
+	return lipgloss.PlaceHorizontal(width, lipgloss.Center, s)
+}
+
+// WrapText wraps plain text at spaces to width. Words wider than width, like
+// ARNs, fill the line they start on and break after the last '/', ':', '.'
+// or '-' that fits, or at width if none does.
+func WrapText(s string, width int) string {
+	width = max(width, 1)
+	lines := []string{}
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+			// words too wide for any line start on this one
+			case lipgloss.Width(line)+1+lipgloss.Width(word) <= width, lipgloss.Width(word) > width:
+				line += " "
+			default:
+				lines = append(lines, line)
+				line = ""
+			}
+			line += word
+			for lipgloss.Width(line) > width {
+				head, tail := breakWord(line, width)
+				lines = append(lines, head)
+				line = tail
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// breakWord splits the head of word that fits width off the rest.
+func breakWord(word string, width int) (string, string) {
+	runes := []rune(word)
+	end, w := 0, 0
+	for end < len(runes) && w+lipgloss.Width(string(runes[end])) <= width {
+		w += lipgloss.Width(string(runes[end]))
+		end++
+	}
+	end = max(end, 1)
+	if i := strings.LastIndexAny(string(runes[:end]), "/:.-"); i > 0 {
+		end = len([]rune(string(runes[:end])[:i+1]))
+	}
+	return string(runes[:end]), string(runes[end:])
+}
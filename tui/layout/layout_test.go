@@ -0,0 +1,71 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestShare(t *testing.T) {
+	cases := []struct {
+		width, n, want int
+	}{
+		{116, 3, 35},
+		{300, 3, 60},
+		{80, 3, 30},
+		{80, 0, 60},
+	}
+	for _, c := range cases {
+		if got := Share(c.width, c.n, 3, 30, 60); got != c.want {
+			t.Errorf("Share(%d, %d) = %d, want %d", c.width, c.n, got, c.want)
+		}
+	}
+}
+
+func TestFlow(t *testing.T) {
+	box := strings.Repeat("x", 30)
+	if got := lipgloss.Height(Flow(100, lipgloss.Top, box, box, box)); got != 1 {
+		t.Errorf("three boxes in 100 columns take %d rows, want 1", got)
+	}
+	got := Flow(70, lipgloss.Top, box, box, box)
+	if lipgloss.Height(got) != 2 {
+		t.Errorf("three boxes in 70 columns take %d rows, want 2", lipgloss.Height(got))
+	}
+	if lipgloss.Width(got) > 70 {
+		t.Errorf("flowed boxes are %d columns wide, more than 70", lipgloss.Width(got))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"has reached a steady state.", 12, "has reached\na steady\nstate."},
+		{"(target-group arn:aws:elbv2:targetgroup/staging-api-tg/7f0c)", 30, "(target-group arn:aws:elbv2:\ntargetgroup/staging-api-tg/\n7f0c)"},
+		{"- 139007003299.dkr.ecr.me-central-1.amazonaws.com/api:442", 20, "- 139007003299.dkr.\necr.me-central-1.\namazonaws.com/api:\n442"},
+		{"0123456789abcdef", 6, "012345\n6789ab\ncdef"},
+		{"two\n\nparagraphs", 20, "two\n\nparagraphs"},
+	}
+	for _, c := range cases {
+		got := WrapText(c.text, c.width)
+		if got != c.want {
+			t.Errorf("WrapText(%q, %d) =\n%s\nwant\n%s", c.text, c.width, got, c.want)
+		}
+		for _, line := range strings.Split(got, "\n") {
+			if lipgloss.Width(line) > c.width {
+				t.Errorf("WrapText(%q, %d) has a line of %d columns", c.text, c.width, lipgloss.Width(line))
+			}
+		}
+	}
+}
+
+func TestCenter(t *testing.T) {
+	// the last line ends in a margin like a box rendered with one
+	got := Center(10, "┌──┐ \n└──┘ ")
+	if want := "  ┌──┐    \n  └──┘    "; got != want {
+		t.Errorf("Center = %q, want %q", got, want)
+	}
+}
@@ -1,10 +1,17 @@
 package list
 
 import (
+	"io"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
 )
 
 type ListItem struct {
@@ -17,6 +24,22 @@ func NewListItem(service, cluster, serviceArn string) ListItem {
 
 var docStyle = lipgloss.NewStyle().Margin(1, 2)
 
+// itemZone is the kind of the zone each listed service is marked with, keyed
+// by its index among the listed items.
+const itemZone = "service"
+
+// itemDelegate marks the items the default delegate renders so a click finds
+// them.
+type itemDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	var b strings.Builder
+	d.DefaultDelegate.Render(&b, m, index, item)
+	io.WriteString(w, zone.Mark(zone.ID(itemZone, strconv.Itoa(index)), b.String()))
+}
+
 func (i ListItem) Title() string       { return i.service }
 func (i ListItem) Description() string { return i.cluster }
 func (i ListItem) FilterValue() string { return i.service }
@@ -29,12 +52,21 @@ type Model struct {
 }
 
 func New() Model {
-	list := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles = theme.Current.ListItems()
+	list := list.New([]list.Item{}, itemDelegate{delegate}, 0, 0)
 	list.Title = "ECS Services"
+	list.Styles = theme.Current.List()
+	// the filter input takes its styles when the list is created
+	list.FilterInput.PromptStyle = list.Styles.FilterPrompt
+	list.FilterInput.Cursor.Style = list.Styles.FilterCursor
+	list.Help.Styles = theme.Current.Help()
 	list.SetFilteringEnabled(true)
-	list.KeyMap.Quit = key.NewBinding(
-		key.WithKeys("q"),
-		key.WithHelp("q", "quit"))
+	list.KeyMap.Quit = keys.Map.Global.Quit
+	list.KeyMap.ForceQuit = keys.Map.Global.ForceQuit
+	list.KeyMap.ShowFullHelp = keys.Describe(keys.Map.Global.Help, "more")
+	list.KeyMap.CloseFullHelp = keys.Describe(keys.Map.Global.Help, "close help")
+	list.AdditionalFullHelpKeys = func() []key.Binding { return []key.Binding{keys.Map.List.Open} }
 	return Model{list}
 }
 func (m Model) Init() tea.Cmd {
@@ -42,11 +74,22 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	switch msg.(type) {
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if m.IsFiltering() {
 			break
 		}
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.list.CursorUp()
+		case tea.MouseButtonWheelDown:
+			m.list.CursorDown()
+		}
+		return m, nil
 	}
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
@@ -69,10 +112,27 @@ func (m *Model) SetItems(services []ListItem) {
 	m.list.SetItems(items)
 }
 
+// Click selects the service at x, y of the view, false if there is none.
+func (m *Model) Click(x, y int) bool {
+	key, ok := zone.Scan(m.View()).At(x, y).Key(itemZone)
+	if !ok {
+		return false
+	}
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return false
+	}
+	m.list.Select(index)
+	return true
+}
+
 func (m *Model) IsFiltering() bool {
 	return m.list.FilterState() == list.Filtering
 }
 
-func (m *Model) GetSelectedServiceArn() ListItem {
-	return m.list.SelectedItem().(ListItem)
+// GetSelectedServiceArn returns the selected service, or false if the list
+// is empty or nothing matches the filter.
+func (m *Model) GetSelectedServiceArn() (ListItem, bool) {
+	item, ok := m.list.SelectedItem().(ListItem)
+	return item, ok
 }
@@ -0,0 +1,75 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mtyurt/ecstui/internal/golden"
+	"github.com/mtyurt/ecstui/tui/zone"
+)
+
+func services() []ListItem {
+	items := []ListItem{}
+	for _, cluster := range []string{"app-cluster-production", "app-cluster-staging"} {
+		for _, service := range []string{"api", "worker", "scheduler", "web"} {
+			name := fmt.Sprintf("%s-%s", cluster[len("app-cluster-"):], service)
+			items = append(items, NewListItem(name, cluster, fmt.Sprintf("arn:aws:ecs:me-central-1:139007003299:service/%s/%s", cluster, name)))
+		}
+	}
+	return items
+}
+
+func TestView(t *testing.T) {
+	for _, width := range golden.Widths {
+		t.Run(fmt.Sprint(width), func(t *testing.T) {
+			m := New()
+			m.SetSize(width, 30)
+			m.SetItems(services())
+			golden.Assert(t, fmt.Sprintf("services_%d", width), m.View())
+		})
+	}
+}
+
+func TestViewEmpty(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	golden.Assert(t, "empty_120", m.View())
+}
+
+func TestViewFiltered(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.SetItems(services())
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "work" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	golden.Assert(t, "filtering_120", m.View())
+}
+
+func TestClick(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.SetItems(services())
+	y := -1
+	for i, line := range strings.Split(zone.Clean(m.View()), "\n") {
+		if strings.Contains(line, "production-scheduler") {
+			y = i
+		}
+	}
+	if !m.Click(6, y) {
+		t.Fatalf("no service at line %d", y)
+	}
+	if item, _ := m.GetSelectedServiceArn(); item.Service() != "production-scheduler" {
+		t.Errorf("selected %q, want production-scheduler", item.Service())
+	}
+	if m.Click(6, 0) {
+		t.Error("clicked a service on the title margin")
+	}
+	m, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if item, _ := m.GetSelectedServiceArn(); item.Service() != "production-web" {
+		t.Errorf("wheel selected %q, want production-web", item.Service())
+	}
+}
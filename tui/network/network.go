@@ -0,0 +1,346 @@
+package network
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+)
+
+// lowFreeIPs is the number of free addresses under which a subnet is
+// flagged, awsvpc tasks need one address each.
+const lowFreeIPs = 10
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+type Model struct {
+	ecsService *ecs.Service
+	fetcher    types.NetworkDetailsFetcher
+	details    *types.NetworkDetails
+	viewport   viewport.Model
+	state      sessionState
+	err        error
+	spinner    spinnertui.Model
+	width      int
+	height     int
+}
+
+type loadedMsg *types.NetworkDetails
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+func New(fetcher types.NetworkDetailsFetcher, ecsService *ecs.Service, width, height int) Model {
+	m := Model{
+		ecsService: ecsService,
+		fetcher:    fetcher,
+		spinner:    spinnertui.New("Loading network details"),
+		viewport:   keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.viewport.SetContent(m.renderDetails())
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching network details")
+	defer logger.Println("finished fetching network details")
+	details, err := m.fetcher(m.ecsService)
+	if err != nil {
+		return errMsg{err}
+	}
+	return loadedMsg(details)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.details = msg
+		m.state = loaded
+		m.viewport.SetContent(m.renderDetails())
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Map.Common.Reload) && m.state != initial {
+			m.state = initial
+			return m, m.Init()
+		}
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderDetails() string {
+	lines := []string{}
+	for _, sectionErr := range m.details.Errors {
+		lines = append(lines, theme.Current.Warning.Render(fmt.Sprintf("⚠ %s: %s", sectionErr.Section, strings.SplitN(sectionErr.Err.Error(), "\n", 2)[0])))
+	}
+	lines = append(lines, m.renderVpc()...)
+	lines = append(lines, "", theme.Current.Header.Render("service discovery"))
+	if len(m.details.Registries) == 0 {
+		lines = append(lines, theme.Current.Subtle.Render("  no service registries"))
+	}
+	for _, registry := range m.details.Registries {
+		lines = append(lines, renderRegistry(registry)...)
+	}
+	lines = append(lines, "", theme.Current.Header.Render("service connect"))
+	lines = append(lines, m.renderServiceConnect()...)
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderVpc() []string {
+	nc := m.ecsService.NetworkConfiguration
+	if nc == nil || nc.AwsvpcConfiguration == nil {
+		return []string{theme.Current.Header.Render("network"), theme.Current.Subtle.Render("  no awsvpc configuration, tasks use the network mode of their task definition")}
+	}
+	vpc := nc.AwsvpcConfiguration
+	lines := []string{
+		theme.Current.Header.Render("awsvpc"),
+		fmt.Sprintf("  public ip: %s", aws.StringValue(vpc.AssignPublicIp)),
+		"  subnets",
+	}
+	subnets := make(map[string]*ec2.Subnet)
+	for _, subnet := range m.details.Subnets {
+		subnets[aws.StringValue(subnet.SubnetId)] = subnet
+	}
+	for _, id := range aws.StringValueSlice(vpc.Subnets) {
+		subnet, ok := subnets[id]
+		if !ok {
+			lines = append(lines, "    "+id)
+			continue
+		}
+		line := fmt.Sprintf("    %s %s %s %s", id, aws.StringValue(subnet.AvailabilityZone), aws.StringValue(subnet.CidrBlock), theme.Current.Subtle.Render(nameTag(subnet.Tags)))
+		free := aws.Int64Value(subnet.AvailableIpAddressCount)
+		if free < lowFreeIPs {
+			line = line + " " + theme.Current.Warning.Render(fmt.Sprintf("⚠ %d free IPs", free))
+		} else {
+			line = line + " " + theme.Current.Subtle.Render(fmt.Sprintf("%d free IPs", free))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "  security groups")
+	groups := make(map[string]*ec2.SecurityGroup)
+	for _, group := range m.details.SecurityGroups {
+		groups[aws.StringValue(group.GroupId)] = group
+	}
+	for _, id := range aws.StringValueSlice(vpc.SecurityGroups) {
+		group, ok := groups[id]
+		if !ok {
+			lines = append(lines, "    "+id)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("    %s %s %s", id, aws.StringValue(group.GroupName), theme.Current.Subtle.Render(aws.StringValue(group.Description))))
+		for _, permission := range group.IpPermissions {
+			lines = append(lines, theme.Current.Subtle.Render("      in  "+describePermission(permission)))
+		}
+		for _, permission := range group.IpPermissionsEgress {
+			lines = append(lines, theme.Current.Subtle.Render("      out "+describePermission(permission)))
+		}
+	}
+	return lines
+}
+
+func nameTag(tags []*ec2.Tag) string {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == "Name" {
+			return aws.StringValue(tag.Value)
+		}
+	}
+	return ""
+}
+
+// describePermission renders a security group rule, e.g. "tcp 443 from
+// 10.0.0.0/16, sg-123".
+func describePermission(p *ec2.IpPermission) string {
+	protocol := aws.StringValue(p.IpProtocol)
+	ports := ""
+	switch {
+	case protocol == "-1":
+		protocol = "all"
+	case aws.Int64Value(p.FromPort) == aws.Int64Value(p.ToPort):
+		ports = fmt.Sprintf(" %d", aws.Int64Value(p.FromPort))
+	default:
+		ports = fmt.Sprintf(" %d-%d", aws.Int64Value(p.FromPort), aws.Int64Value(p.ToPort))
+	}
+	peers := []string{}
+	for _, r := range p.IpRanges {
+		peers = append(peers, aws.StringValue(r.CidrIp))
+	}
+	for _, r := range p.Ipv6Ranges {
+		peers = append(peers, aws.StringValue(r.CidrIpv6))
+	}
+	for _, g := range p.UserIdGroupPairs {
+		peers = append(peers, aws.StringValue(g.GroupId))
+	}
+	for _, l := range p.PrefixListIds {
+		peers = append(peers, aws.StringValue(l.PrefixListId))
+	}
+	return fmt.Sprintf("%s%s %s", protocol, ports, strings.Join(peers, ", "))
+}
+
+func renderRegistry(registry types.ServiceRegistryDetails) []string {
+	lines := []string{}
+	if registry.Service == nil {
+		lines = append(lines, "  "+registry.RegistryArn)
+	} else {
+		name := aws.StringValue(registry.Service.Name)
+		if registry.Namespace != nil {
+			name = name + "." + aws.StringValue(registry.Namespace.Name)
+			name = name + " " + theme.Current.Subtle.Render(aws.StringValue(registry.Namespace.Type))
+		}
+		lines = append(lines, "  "+name)
+		if dns := registry.Service.DnsConfig; dns != nil {
+			records := []string{}
+			for _, record := range dns.DnsRecords {
+				records = append(records, fmt.Sprintf("%s ttl %ds", aws.StringValue(record.Type), aws.Int64Value(record.TTL)))
+			}
+			lines = append(lines, theme.Current.Subtle.Render(fmt.Sprintf("    dns %s, routing %s", strings.Join(records, ", "), aws.StringValue(dns.RoutingPolicy))))
+		}
+	}
+	if registry.Service != nil && len(registry.Instances) == 0 {
+		lines = append(lines, theme.Current.Warning.Render("    ⚠ no registered instances"))
+	}
+	instances := slices.Clone(registry.Instances)
+	slices.SortFunc(instances, func(i, j *servicediscovery.InstanceSummary) int {
+		return strings.Compare(aws.StringValue(i.Id), aws.StringValue(j.Id))
+	})
+	for _, instance := range instances {
+		id := aws.StringValue(instance.Id)
+		address := aws.StringValue(instance.Attributes["AWS_INSTANCE_IPV4"])
+		if port := aws.StringValue(instance.Attributes["AWS_INSTANCE_PORT"]); port != "" {
+			address = address + ":" + port
+		}
+		health, ok := registry.Health[id]
+		switch {
+		case !ok:
+			health = theme.Current.Subtle.Render("no health status")
+		case health == servicediscovery.HealthStatusHealthy:
+			health = theme.Current.OK.Render(health)
+		case health == servicediscovery.HealthStatusUnhealthy:
+			health = theme.Current.Failed.Render(health)
+		default:
+			health = theme.Current.Warning.Render(health)
+		}
+		lines = append(lines, fmt.Sprintf("    %s %s %s", id, address, health))
+	}
+	return lines
+}
+
+// serviceConnect returns the service connect configuration of the primary
+// deployment, the one new tasks are started with.
+func (m Model) serviceConnect() (*ecs.ServiceConnectConfiguration, []*ecs.ServiceConnectServiceResource) {
+	for _, d := range m.ecsService.Deployments {
+		if aws.StringValue(d.Status) == "PRIMARY" {
+			return d.ServiceConnectConfiguration, d.ServiceConnectResources
+		}
+	}
+	return nil, nil
+}
+
+func (m Model) renderServiceConnect() []string {
+	config, resources := m.serviceConnect()
+	if config == nil || !aws.BoolValue(config.Enabled) {
+		return []string{theme.Current.Subtle.Render("  not enabled")}
+	}
+	lines := []string{"  namespace " + aws.StringValue(config.Namespace)}
+	discoveryArns := make(map[string]string)
+	for _, resource := range resources {
+		discoveryArns[aws.StringValue(resource.DiscoveryName)] = aws.StringValue(resource.DiscoveryArn)
+	}
+	if len(config.Services) == 0 {
+		lines = append(lines, theme.Current.Subtle.Render("  client only, no endpoints published"))
+	}
+	for _, service := range config.Services {
+		discoveryName := aws.StringValue(service.DiscoveryName)
+		if discoveryName == "" {
+			discoveryName = aws.StringValue(service.PortName)
+		}
+		line := fmt.Sprintf("  port %s as %s", aws.StringValue(service.PortName), discoveryName)
+		if service.IngressPortOverride != nil {
+			line = line + fmt.Sprintf(", ingress port %d", *service.IngressPortOverride)
+		}
+		lines = append(lines, line)
+		for _, alias := range service.ClientAliases {
+			dnsName := aws.StringValue(alias.DnsName)
+			if dnsName == "" {
+				dnsName = discoveryName
+			}
+			lines = append(lines, fmt.Sprintf("    alias %s:%d", dnsName, aws.Int64Value(alias.Port)))
+		}
+		if arn, ok := discoveryArns[discoveryName]; ok {
+			lines = append(lines, theme.Current.Subtle.Render("    "+arn))
+		}
+	}
+	if lc := config.LogConfiguration; lc != nil {
+		lines = append(lines, theme.Current.Subtle.Render("  proxy log driver "+aws.StringValue(lc.LogDriver)))
+	}
+	return lines
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Common.Reload, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render("network")+" "+theme.Current.Subtle.Render(aws.StringValue(m.ecsService.ServiceName)), "", m.viewport.View(), "", m.footerView())
+}
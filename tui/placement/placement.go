@@ -0,0 +1,302 @@
+package placement
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// imbalanceTolerance is how many tasks a provider or zone may be off by
+// before it is highlighted, rounding alone makes off-by-one common.
+const imbalanceTolerance = 1
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+type Model struct {
+	cluster, service string
+	ecsService       *ecs.Service
+	fetcher          types.PlacementDetailsFetcher
+	details          *types.PlacementDetails
+	viewport         viewport.Model
+	state            sessionState
+	err              error
+	spinner          spinnertui.Model
+	width            int
+	height           int
+}
+
+type loadedMsg *types.PlacementDetails
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+func New(fetcher types.PlacementDetailsFetcher, cluster string, ecsService *ecs.Service, width, height int) Model {
+	m := Model{
+		cluster:    cluster,
+		service:    aws.StringValue(ecsService.ServiceName),
+		ecsService: ecsService,
+		fetcher:    fetcher,
+		spinner:    spinnertui.New("Loading placement details"),
+		viewport:   keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.viewport.SetContent(m.renderDetails())
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching placement details")
+	defer logger.Println("finished fetching placement details")
+	details, err := m.fetcher(m.cluster, m.service)
+	if err != nil {
+		return errMsg{err}
+	}
+	return loadedMsg(details)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.details = msg
+		m.state = loaded
+		m.viewport.SetContent(m.renderDetails())
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Map.Common.Reload) && m.state != initial {
+			m.state = initial
+			return m, m.Init()
+		}
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+// strategy is the capacity provider strategy in effect: the service's own,
+// otherwise the cluster default unless the service uses a launch type.
+func (m Model) strategy() ([]*ecs.CapacityProviderStrategyItem, string) {
+	if len(m.ecsService.CapacityProviderStrategy) > 0 {
+		return m.ecsService.CapacityProviderStrategy, "service"
+	}
+	if m.ecsService.LaunchType == nil && m.details.Cluster != nil && len(m.details.Cluster.DefaultCapacityProviderStrategy) > 0 {
+		return m.details.Cluster.DefaultCapacityProviderStrategy, "cluster default"
+	}
+	return nil, ""
+}
+
+func (m Model) renderDetails() string {
+	d := m.details
+	lines := []string{}
+	for _, sectionErr := range d.Errors {
+		lines = append(lines, theme.Current.Warning.Render(fmt.Sprintf("⚠ %s: %s", sectionErr.Section, strings.SplitN(sectionErr.Err.Error(), "\n", 2)[0])))
+	}
+
+	running := make(map[string]int)
+	zones := make(map[string]int)
+	for _, task := range d.Tasks {
+		if aws.StringValue(task.LastStatus) != ecs.DesiredStatusRunning {
+			continue
+		}
+		running[utils.TaskCapacity(task)]++
+		zones[aws.StringValue(task.AvailabilityZone)]++
+	}
+	total := 0
+	for _, count := range running {
+		total += count
+	}
+
+	strategy, source := m.strategy()
+	if strategy != nil {
+		lines = append(lines, theme.Current.Header.Render("capacity provider strategy")+" "+theme.Current.Subtle.Render(source))
+		lines = append(lines, m.renderStrategy(strategy, running, total)...)
+	} else {
+		lines = append(lines, theme.Current.Header.Render("launch type")+" "+aws.StringValue(m.ecsService.LaunchType))
+		lines = append(lines, renderCounts(running)...)
+	}
+
+	lines = append(lines, "", theme.Current.Header.Render("availability zones")+" "+theme.Current.Subtle.Render(fmt.Sprintf("%d running tasks", total)))
+	lines = append(lines, renderZones(zones)...)
+
+	lines = append(lines, "", theme.Current.Header.Render("cluster capacity providers"))
+	if len(d.CapacityProviders) == 0 {
+		lines = append(lines, theme.Current.Subtle.Render("  none"))
+	}
+	for _, provider := range d.CapacityProviders {
+		lines = append(lines, renderCapacityProvider(provider)...)
+	}
+
+	lines = append(lines, "", theme.Current.Header.Render("placement"))
+	if len(m.ecsService.PlacementConstraints) == 0 && len(m.ecsService.PlacementStrategy) == 0 {
+		lines = append(lines, theme.Current.Subtle.Render("  no constraints or strategies"))
+	}
+	for _, c := range m.ecsService.PlacementConstraints {
+		constraint := "  constraint " + aws.StringValue(c.Type)
+		if c.Expression != nil {
+			constraint = constraint + " " + *c.Expression
+		}
+		lines = append(lines, constraint)
+	}
+	for _, s := range m.ecsService.PlacementStrategy {
+		strategy := "  strategy " + aws.StringValue(s.Type)
+		if s.Field != nil {
+			strategy = strategy + " " + *s.Field
+		}
+		lines = append(lines, strategy)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) renderStrategy(strategy []*ecs.CapacityProviderStrategyItem, running map[string]int, total int) []string {
+	expected := utils.ExpectedDistribution(strategy, total)
+	lines := []string{}
+	for _, item := range strategy {
+		provider := aws.StringValue(item.CapacityProvider)
+		line := fmt.Sprintf("  %-24s base %-3d weight %-3d expected %-3d running %-3d",
+			provider, aws.Int64Value(item.Base), aws.Int64Value(item.Weight), expected[provider], running[provider])
+		if abs(expected[provider]-running[provider]) > imbalanceTolerance {
+			line = theme.Current.Warning.Render(line + " ⚠ imbalanced")
+		}
+		lines = append(lines, line)
+	}
+	// tasks placed on providers that are not part of the strategy, e.g.
+	// left over from a previous strategy
+	for _, provider := range sortedKeys(running) {
+		if _, ok := expected[provider]; !ok {
+			lines = append(lines, theme.Current.Warning.Render(fmt.Sprintf("  %-24s not in strategy, running %d", provider, running[provider])))
+		}
+	}
+	return lines
+}
+
+func renderCounts(counts map[string]int) []string {
+	lines := []string{}
+	for _, key := range sortedKeys(counts) {
+		lines = append(lines, fmt.Sprintf("  %-24s running %d", key, counts[key]))
+	}
+	return lines
+}
+
+func renderZones(zones map[string]int) []string {
+	if len(zones) == 0 {
+		return []string{theme.Current.Subtle.Render("  no running tasks")}
+	}
+	least, most := -1, 0
+	for _, count := range zones {
+		if least == -1 || count < least {
+			least = count
+		}
+		most = max(most, count)
+	}
+	lines := renderCounts(zones)
+	if most-least > imbalanceTolerance {
+		lines = append(lines, theme.Current.Warning.Render(fmt.Sprintf("  ⚠ tasks are unevenly spread, %d to %d per zone", least, most)))
+	}
+	return lines
+}
+
+func renderCapacityProvider(provider *ecs.CapacityProvider) []string {
+	status := aws.StringValue(provider.Status)
+	if status == ecs.CapacityProviderStatusActive {
+		status = theme.Current.OK.Render(status)
+	} else {
+		status = theme.Current.Warning.Render(status)
+	}
+	lines := []string{fmt.Sprintf("  %s %s", aws.StringValue(provider.Name), status)}
+	asg := provider.AutoScalingGroupProvider
+	if asg == nil {
+		return lines
+	}
+	lines = append(lines, theme.Current.Subtle.Render("    asg "+utils.GetLastItemAfterSplit(aws.StringValue(asg.AutoScalingGroupArn), "/")))
+	if ms := asg.ManagedScaling; ms != nil {
+		scaling := fmt.Sprintf("    managed scaling %s", aws.StringValue(ms.Status))
+		if aws.StringValue(ms.Status) == ecs.ManagedScalingStatusEnabled {
+			scaling = scaling + fmt.Sprintf(", target capacity %d%%, step %d-%d", aws.Int64Value(ms.TargetCapacity), aws.Int64Value(ms.MinimumScalingStepSize), aws.Int64Value(ms.MaximumScalingStepSize))
+		}
+		lines = append(lines, theme.Current.Subtle.Render(scaling))
+	}
+	lines = append(lines, theme.Current.Subtle.Render("    managed termination protection "+aws.StringValue(asg.ManagedTerminationProtection)))
+	return lines
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Common.Reload, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render("placement")+" "+theme.Current.Subtle.Render(m.service), "", m.viewport.View(), "", m.footerView())
+}
@@ -0,0 +1,334 @@
+package revisions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/taskdef"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// detailWindow is how many rows around the cursor get their task definition
+// described. Families can have thousands of revisions, so details are loaded
+// lazily while scrolling instead of up front.
+const detailWindow = 25
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+type Model struct {
+	family           string
+	revisionsFetcher types.TaskDefinitionRevisionsFetcher
+	taskDefFetcher   types.TaskDefinitionFetcher
+	inUse            map[string][]string
+	arns             []string
+	details          map[string]*ecs.TaskDefinition
+	detailErrs       map[string]error
+	pending          map[string]bool
+	marked           string
+	table            table.Model
+	detail           *taskdef.Model
+	state            sessionState
+	err              error
+	spinner          spinnertui.Model
+	width, height    int
+}
+
+type revisionsMsg []string
+
+type detailsMsg struct {
+	definitions map[string]*ecs.TaskDefinition
+	errs        map[string]error
+}
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+// New lists the revisions of family. inUse are the task definitions running
+// in the service's deployments or task sets, shown next to their revision.
+func New(family string, revisionsFetcher types.TaskDefinitionRevisionsFetcher, taskDefFetcher types.TaskDefinitionFetcher, inUse []taskdef.Candidate, width, height int) Model {
+	inUseByArn := make(map[string][]string)
+	for _, c := range inUse {
+		inUseByArn[c.Arn] = append(inUseByArn[c.Arn], c.Label)
+	}
+
+	m := Model{
+		family:           family,
+		revisionsFetcher: revisionsFetcher,
+		taskDefFetcher:   taskDefFetcher,
+		inUse:            inUseByArn,
+		details:          make(map[string]*ecs.TaskDefinition),
+		detailErrs:       make(map[string]error),
+		pending:          make(map[string]bool),
+		table:            keys.NewTable(table.WithFocused(true), table.WithStyles(theme.Current.Table())),
+		spinner:          spinnertui.New(fmt.Sprintf("Loading %s revisions", family)),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	fixed := 3 + 8 + 10 + 20 + 8 // spaces, revision, status, registered, padding
+	usedBy := 30
+	images := max(width-fixed-usedBy, 20)
+	m.table.SetColumns([]table.Column{
+		{Title: "revision", Width: 8},
+		{Title: "status", Width: 10},
+		{Title: "registered", Width: 20},
+		{Title: "images", Width: images},
+		{Title: "used by", Width: usedBy},
+	})
+	m.table.SetWidth(width)
+	m.table.SetHeight(max(height-6, 3))
+	if m.detail != nil {
+		m.detail.SetSize(width, height)
+	}
+}
+
+func (m Model) fetchRevisions() tea.Msg {
+	logger.Println("started fetching task definition revisions of", m.family)
+	defer logger.Println("finished fetching task definition revisions of", m.family)
+	arns, err := m.revisionsFetcher(m.family)
+	if err != nil {
+		return errMsg{err}
+	}
+	return revisionsMsg(arns)
+}
+
+func (m Model) fetchDetails(arns []string) tea.Cmd {
+	fetcher := m.taskDefFetcher
+	return func() tea.Msg {
+		msg := detailsMsg{definitions: make(map[string]*ecs.TaskDefinition), errs: make(map[string]error)}
+		for _, arn := range arns {
+			td, err := fetcher(arn)
+			if err != nil {
+				msg.errs[arn] = err
+				continue
+			}
+			msg.definitions[arn] = td
+		}
+		return msg
+	}
+}
+
+// ensureDetails requests the task definitions around the cursor that have
+// not been described yet.
+func (m *Model) ensureDetails() tea.Cmd {
+	from := max(m.table.Cursor()-detailWindow/2, 0)
+	to := min(from+detailWindow, len(m.arns))
+	missing := []string{}
+	for _, arn := range m.arns[from:to] {
+		if _, ok := m.details[arn]; ok || m.pending[arn] || m.detailErrs[arn] != nil {
+			continue
+		}
+		m.pending[arn] = true
+		missing = append(missing, arn)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return m.fetchDetails(missing)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetchRevisions, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view, it closes an open task
+// definition or diff first.
+func (m Model) Focused() bool {
+	return m.detail == nil
+}
+
+func (m Model) selectedArn() string {
+	if len(m.arns) == 0 {
+		return ""
+	}
+	return m.arns[m.table.Cursor()]
+}
+
+func (m Model) label(arn string) string {
+	_, revision := utils.SplitTaskDefinitionArn(arn)
+	label := fmt.Sprintf("revision %d", revision)
+	if users, ok := m.inUse[arn]; ok {
+		label = label + " (" + strings.Join(users, ", ") + ")"
+	}
+	return label
+}
+
+// diffTarget is compared with the selected revision: the marked revision if
+// any, otherwise the newest revision in use by the service.
+func (m Model) diffTarget() string {
+	if m.marked != "" {
+		return m.marked
+	}
+	for _, arn := range m.arns {
+		if _, ok := m.inUse[arn]; ok {
+			return arn
+		}
+	}
+	return ""
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case revisionsMsg:
+		m.arns = msg
+		m.state = loaded
+		m.updateRows()
+		return m, m.ensureDetails()
+	case detailsMsg:
+		for arn, td := range msg.definitions {
+			m.details[arn] = td
+			delete(m.pending, arn)
+		}
+		for arn, err := range msg.errs {
+			m.detailErrs[arn] = err
+			delete(m.pending, arn)
+		}
+		m.updateRows()
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if m.detail != nil {
+			if key.Matches(msg, keys.Map.Global.Back) && m.detail.Focused() {
+				m.detail = nil
+				return m, nil
+			}
+			break
+		}
+		if m.state != loaded || len(m.arns) == 0 {
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Revisions.Mark):
+			if m.marked == m.selectedArn() {
+				m.marked = ""
+			} else {
+				m.marked = m.selectedArn()
+			}
+			m.updateRows()
+			return m, nil
+		case key.Matches(msg, keys.Map.Revisions.View):
+			view := taskdef.NewViewer(m.taskDefFetcher, taskdef.Candidate{Label: m.label(m.selectedArn()), Arn: m.selectedArn()}, m.width, m.height)
+			m.detail = &view
+			return m, view.Init()
+		case key.Matches(msg, keys.Map.Revisions.Diff):
+			target := m.diffTarget()
+			if target == "" || target == m.selectedArn() {
+				return m, nil
+			}
+			// the older revision goes to the left
+			left, right := target, m.selectedArn()
+			_, selectedRevision := utils.SplitTaskDefinitionArn(right)
+			_, targetRevision := utils.SplitTaskDefinitionArn(left)
+			if selectedRevision < targetRevision {
+				left, right = right, left
+			}
+			candidates := []taskdef.Candidate{
+				{Label: m.label(left), Arn: left},
+				{Label: m.label(right), Arn: right},
+			}
+			view := taskdef.NewDiff(m.taskDefFetcher, candidates, m.width, m.height)
+			m.detail = &view
+			return m, view.Init()
+		}
+	}
+
+	if m.detail != nil {
+		detail, cmd := m.detail.Update(msg)
+		m.detail = &detail
+		return m, cmd
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+	case loaded:
+		m.table, cmd = m.table.Update(msg)
+		cmds = append(cmds, cmd, m.ensureDetails())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) updateRows() {
+	rows := make([]table.Row, 0, len(m.arns))
+	for _, arn := range m.arns {
+		_, revision := utils.SplitTaskDefinitionArn(arn)
+		revisionCol := fmt.Sprintf("%d", revision)
+		if arn == m.marked {
+			revisionCol = "* " + revisionCol
+		}
+		status, registered, images := "…", "…", "…"
+		if td, ok := m.details[arn]; ok {
+			status = aws.StringValue(td.Status)
+			if td.RegisteredAt != nil {
+				registered = td.RegisteredAt.Local().Format("2006-01-02 15:04:05")
+			}
+			names := []string{}
+			for _, c := range td.ContainerDefinitions {
+				names = append(names, utils.GetLastItemAfterSplit(aws.StringValue(c.Image), "/"))
+			}
+			images = strings.Join(names, ", ")
+		} else if err, ok := m.detailErrs[arn]; ok {
+			images = "⚠ " + strings.SplitN(err.Error(), "\n", 2)[0]
+		}
+		rows = append(rows, table.Row{revisionCol, status, registered, images, strings.Join(m.inUse[arn], ", ")})
+	}
+	m.table.SetRows(rows)
+}
+
+// Help lists the keys of the revision list, or of the open revision or diff.
+func (m Model) Help() []key.Binding {
+	if m.detail != nil {
+		return m.detail.Help()
+	}
+	revisions := keys.Map.Revisions
+	return []key.Binding{keys.Map.Common.Select(), revisions.View, revisions.Mark, revisions.Diff, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	if m.detail != nil {
+		return m.detail.View()
+	}
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return m.err.Error()
+	}
+	header := theme.Current.Title.Render(m.family) + " " + theme.Current.Subtle.Render(fmt.Sprintf("%d revisions", len(m.arns)))
+	if m.marked != "" {
+		header = header + " " + theme.Current.OK.Render("marked "+utils.GetLastItemAfterSplit(m.marked, "/"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", m.table.View(), "", m.footerView())
+}
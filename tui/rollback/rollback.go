@@ -0,0 +1,310 @@
+package rollback
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/audit"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/actions"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/taskdef"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// historyLimit is how many revisions of the family are offered besides the
+// ones in use, older revisions are rarely a rollback target.
+const historyLimit = 30
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+	preparing
+)
+
+type choice struct {
+	arn    string
+	labels []string
+}
+
+type Model struct {
+	revisionsFetcher types.TaskDefinitionRevisionsFetcher
+	taskDefFetcher   types.TaskDefinitionFetcher
+	writers          actions.Writers
+	log              *audit.Log
+	service          *ecs.Service
+	inUse            []taskdef.Candidate
+	choices          []choice
+	cursor           int
+	confirm          *actions.Model
+	state            sessionState
+	err              error
+	spinner          spinnertui.Model
+	width, height    int
+}
+
+type revisionsMsg []string
+
+type preparedMsg actions.Request
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+// New offers the task definitions the service can be rolled back to: the ones
+// running in its deployments or task sets first, then the revision history of
+// the family.
+func New(revisionsFetcher types.TaskDefinitionRevisionsFetcher, taskDefFetcher types.TaskDefinitionFetcher, writers actions.Writers, log *audit.Log, service *ecs.Service, inUse []taskdef.Candidate, width, height int) Model {
+	m := Model{
+		revisionsFetcher: revisionsFetcher,
+		taskDefFetcher:   taskDefFetcher,
+		writers:          writers,
+		log:              log,
+		service:          service,
+		inUse:            inUse,
+		spinner:          spinnertui.New("Loading task definition revisions"),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	if m.confirm != nil {
+		m.confirm.SetSize(width, height)
+	}
+}
+
+func (m Model) current() string {
+	return aws.StringValue(m.service.TaskDefinition)
+}
+
+func (m Model) fetchRevisions() tea.Msg {
+	family, _ := utils.SplitTaskDefinitionArn(m.current())
+	logger.Println("started fetching rollback revisions of", family)
+	defer logger.Println("finished fetching rollback revisions of", family)
+	arns, err := m.revisionsFetcher(family)
+	if err != nil {
+		return errMsg{err}
+	}
+	return revisionsMsg(arns)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetchRevisions, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view, it cancels an open
+// confirmation first.
+func (m Model) Focused() bool {
+	return m.confirm == nil && m.state != preparing
+}
+
+func (m *Model) setChoices(history []string) {
+	byArn := make(map[string]int)
+	m.choices = []choice{}
+	add := func(arn, label string) {
+		if arn == m.current() {
+			return
+		}
+		i, ok := byArn[arn]
+		if !ok {
+			i = len(m.choices)
+			byArn[arn] = i
+			m.choices = append(m.choices, choice{arn: arn})
+		}
+		if label != "" {
+			m.choices[i].labels = append(m.choices[i].labels, label)
+		}
+	}
+	for _, c := range m.inUse {
+		add(c.Arn, c.Label)
+	}
+	for i, arn := range history {
+		if i >= historyLimit {
+			break
+		}
+		add(arn, "")
+	}
+}
+
+// prepare describes the current and the chosen task definition and builds
+// the UpdateService request with their diff as the dry run.
+func (m Model) prepare(arn string) tea.Cmd {
+	return func() tea.Msg {
+		current, err := m.taskDefFetcher(m.current())
+		if err != nil {
+			return errMsg{err}
+		}
+		target, err := m.taskDefFetcher(arn)
+		if err != nil {
+			return errMsg{err}
+		}
+		preview := diffPreview(taskdef.Describe(current), taskdef.Describe(target))
+		if aws.StringValue(target.Status) == ecs.TaskDefinitionStatusInactive {
+			preview = append(preview, theme.Current.Warning.Render("  ⚠ the revision is INACTIVE, ECS does not update services to inactive task definitions"))
+		}
+		input := &ecs.UpdateServiceInput{
+			Cluster:        m.service.ClusterArn,
+			Service:        m.service.ServiceName,
+			TaskDefinition: aws.String(arn),
+		}
+		writers := m.writers
+		return preparedMsg(actions.Request{
+			Title:           "roll back to " + utils.GetLastItemAfterSplit(arn, "/"),
+			Operation:       "ecs:UpdateService",
+			Input:           input,
+			Preview:         preview,
+			Run:             func() error { return writers.UpdateService(input) },
+			WatchDeployment: true,
+		})
+	}
+}
+
+// diffPreview lists the lines that change from current to target, each
+// under the container or section header it belongs to.
+func diffPreview(current, target []string) []string {
+	lines := []string{}
+	header, shown := "", ""
+	for _, line := range utils.DiffLines(current, target) {
+		if line.Op == utils.DiffEqual {
+			if strings.HasPrefix(line.Text, "container ") || line.Text == "volumes" {
+				header = line.Text
+			}
+			continue
+		}
+		if header != "" && header != shown {
+			lines = append(lines, theme.Current.Subtle.Render("  "+header))
+			shown = header
+		}
+		if line.Op == utils.DiffDelete {
+			lines = append(lines, theme.Current.Failed.Render("  - "+line.Text))
+		} else {
+			lines = append(lines, theme.Current.OK.Render("  + "+line.Text))
+		}
+	}
+	if len(lines) == 0 {
+		return []string{theme.Current.Subtle.Render("  no differences, the tasks are replaced with identical ones")}
+	}
+	return lines
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case revisionsMsg:
+		m.setChoices(msg)
+		m.state = loaded
+		return m, nil
+	case preparedMsg:
+		confirm := actions.NewConfirm(actions.Request(msg), m.log, m.service, m.width, m.height)
+		m.confirm = &confirm
+		m.state = loaded
+		return m, confirm.Init()
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if m.confirm != nil {
+			if key.Matches(msg, keys.Map.Global.Back) && m.confirm.Focused() {
+				m.confirm = nil
+				return m, nil
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Common.Up):
+			m.cursor = max(m.cursor-1, 0)
+		case key.Matches(msg, keys.Map.Common.Down):
+			m.cursor = min(m.cursor+1, len(m.choices)-1)
+		case key.Matches(msg, keys.Map.Rollback.Preview):
+			if m.state == loaded && len(m.choices) > 0 {
+				m.state = preparing
+				return m, tea.Batch(m.prepare(m.choices[m.cursor].arn), m.spinner.SpinnerTick())
+			}
+		case key.Matches(msg, keys.Map.Error.Retry):
+			if m.state == failed {
+				m.state = initial
+				return m, m.Init()
+			}
+		}
+		return m, nil
+	}
+
+	if m.confirm != nil {
+		confirm, cmd := m.confirm.Update(msg)
+		m.confirm = &confirm
+		return m, cmd
+	}
+	if m.state == initial || m.state == preparing {
+		m.spinner, cmd = m.spinner.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderChoices() []string {
+	lines := []string{}
+	for i, c := range m.choices {
+		name, suffix := utils.GetLastItemAfterSplit(c.arn, "/"), ""
+		if len(c.labels) > 0 {
+			suffix = " " + theme.Current.OK.Render(strings.Join(c.labels, ", "))
+		}
+		if i == m.cursor {
+			lines = append(lines, theme.Current.Selected.Render("> "+name)+suffix)
+		} else {
+			lines = append(lines, "  "+name+suffix)
+		}
+	}
+	return lines
+}
+
+// Help lists the keys of the revision choice, or of the open preview.
+func (m Model) Help() []key.Binding {
+	switch {
+	case m.confirm != nil:
+		return m.confirm.Help()
+	case m.state == failed:
+		return []key.Binding{keys.Map.Error.Retry, keys.Map.Global.Back}
+	}
+	return []key.Binding{keys.Map.Common.Select(), keys.Map.Rollback.Preview, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	if m.confirm != nil {
+		return m.confirm.View()
+	}
+	switch m.state {
+	case initial, preparing:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	header := theme.Current.Title.Render("roll back") + " " + theme.Current.Subtle.Render("running "+utils.GetLastItemAfterSplit(m.current(), "/"))
+	lines := []string{header, ""}
+	if len(m.choices) == 0 {
+		lines = append(lines, theme.Current.Subtle.Render("no other revision to roll back to"))
+	}
+	// keep the cursor on screen, families can have many revisions
+	choices := m.renderChoices()
+	visible := max(m.height-6, 3)
+	start := max(0, min(m.cursor-visible/2, len(choices)-visible))
+	lines = append(lines, choices[start:min(start+visible, len(choices))]...)
+	lines = append(lines, "", m.footerView())
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
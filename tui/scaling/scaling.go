@@ -0,0 +1,343 @@
+package scaling
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	autoscaling "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	humanizer "github.com/dustin/go-humanize"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+)
+
+var comparisonOperators = map[string]string{
+	cloudwatch.ComparisonOperatorGreaterThanOrEqualToThreshold: ">=",
+	cloudwatch.ComparisonOperatorGreaterThanThreshold:          ">",
+	cloudwatch.ComparisonOperatorLessThanThreshold:             "<",
+	cloudwatch.ComparisonOperatorLessThanOrEqualToThreshold:    "<=",
+}
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+type Model struct {
+	cluster, service string
+	fetcher          types.ScalingDetailsFetcher
+	details          *types.ScalingDetails
+	viewport         viewport.Model
+	state            sessionState
+	err              error
+	spinner          spinnertui.Model
+	width            int
+	height           int
+}
+
+type loadedMsg *types.ScalingDetails
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+func New(fetcher types.ScalingDetailsFetcher, cluster, service string, width, height int) Model {
+	m := Model{
+		cluster:  cluster,
+		service:  service,
+		fetcher:  fetcher,
+		spinner:  spinnertui.New("Loading auto scaling details"),
+		viewport: keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.viewport.SetContent(m.renderDetails())
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching auto scaling details")
+	defer logger.Println("finished fetching auto scaling details")
+	details, err := m.fetcher(m.cluster, m.service)
+	if err != nil {
+		return errMsg{err}
+	}
+	return loadedMsg(details)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.details = msg
+		m.state = loaded
+		m.viewport.SetContent(m.renderDetails())
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Map.Common.Reload) && m.state != initial {
+			m.state = initial
+			return m, m.Init()
+		}
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderDetails() string {
+	d := m.details
+	if d.Target == nil {
+		return theme.Current.Subtle.Render("the service is not registered as a scalable target")
+	}
+	lines := []string{theme.Current.Header.Render("target")}
+	lines = append(lines, fmt.Sprintf("  min %d, max %d", aws.Int64Value(d.Target.MinCapacity), aws.Int64Value(d.Target.MaxCapacity)))
+	if s := d.Target.SuspendedState; s != nil {
+		suspended := []string{}
+		if aws.BoolValue(s.DynamicScalingInSuspended) {
+			suspended = append(suspended, "scale in")
+		}
+		if aws.BoolValue(s.DynamicScalingOutSuspended) {
+			suspended = append(suspended, "scale out")
+		}
+		if aws.BoolValue(s.ScheduledScalingSuspended) {
+			suspended = append(suspended, "scheduled scaling")
+		}
+		if len(suspended) > 0 {
+			lines = append(lines, theme.Current.Warning.Render("  ⚠ suspended: "+strings.Join(suspended, ", ")))
+		}
+	}
+
+	lines = append(lines, "", theme.Current.Header.Render("policies"))
+	lines = append(lines, sectionWarning(d, types.SectionPolicies)...)
+	if len(d.Policies) == 0 && d.Errors.Get(types.SectionPolicies) == nil {
+		lines = append(lines, theme.Current.Subtle.Render("  none"))
+	}
+	lines = append(lines, sectionWarning(d, types.SectionAlarms)...)
+	for _, policy := range d.Policies {
+		lines = append(lines, m.renderPolicy(policy)...)
+	}
+
+	lines = append(lines, "", theme.Current.Header.Render("scheduled actions"))
+	lines = append(lines, sectionWarning(d, types.SectionScheduledActions)...)
+	if len(d.ScheduledActions) == 0 && d.Errors.Get(types.SectionScheduledActions) == nil {
+		lines = append(lines, theme.Current.Subtle.Render("  none"))
+	}
+	for _, action := range d.ScheduledActions {
+		lines = append(lines, renderScheduledAction(action)...)
+	}
+
+	lines = append(lines, "", theme.Current.Header.Render("recent activities"))
+	lines = append(lines, sectionWarning(d, types.SectionActivities)...)
+	if len(d.Activities) == 0 && d.Errors.Get(types.SectionActivities) == nil {
+		lines = append(lines, theme.Current.Subtle.Render("  none"))
+	}
+	for _, activity := range d.Activities {
+		lines = append(lines, renderActivity(activity)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sectionWarning(d *types.ScalingDetails, section string) []string {
+	if err := d.Errors.Get(section); err != nil {
+		return []string{theme.Current.Warning.Render(fmt.Sprintf("  ⚠ %s: %s", section, strings.SplitN(err.Error(), "\n", 2)[0]))}
+	}
+	return nil
+}
+
+func (m Model) renderPolicy(policy *autoscaling.ScalingPolicy) []string {
+	lines := []string{fmt.Sprintf("  %s %s", aws.StringValue(policy.PolicyName), theme.Current.Subtle.Render(aws.StringValue(policy.PolicyType)))}
+	if c := policy.TargetTrackingScalingPolicyConfiguration; c != nil {
+		lines = append(lines, fmt.Sprintf("    keep %s at %s", trackedMetric(c), formatFloat(aws.Float64Value(c.TargetValue))))
+		cooldowns := fmt.Sprintf("    scale out cooldown %ds, scale in cooldown %ds", aws.Int64Value(c.ScaleOutCooldown), aws.Int64Value(c.ScaleInCooldown))
+		if aws.BoolValue(c.DisableScaleIn) {
+			cooldowns = cooldowns + ", scale in disabled"
+		}
+		lines = append(lines, theme.Current.Subtle.Render(cooldowns))
+	}
+	if c := policy.StepScalingPolicyConfiguration; c != nil {
+		lines = append(lines, theme.Current.Subtle.Render(fmt.Sprintf("    %s, cooldown %ds, aggregation %s",
+			aws.StringValue(c.AdjustmentType), aws.Int64Value(c.Cooldown), aws.StringValue(c.MetricAggregationType))))
+		for _, step := range c.StepAdjustments {
+			lines = append(lines, fmt.Sprintf("    metric - threshold in %s: %+d", stepRange(step), aws.Int64Value(step.ScalingAdjustment)))
+		}
+	}
+	for _, alarm := range policy.Alarms {
+		lines = append(lines, m.renderAlarm(aws.StringValue(alarm.AlarmName))...)
+	}
+	return lines
+}
+
+func trackedMetric(c *autoscaling.TargetTrackingScalingPolicyConfiguration) string {
+	if p := c.PredefinedMetricSpecification; p != nil {
+		return aws.StringValue(p.PredefinedMetricType)
+	}
+	if cm := c.CustomizedMetricSpecification; cm != nil {
+		if cm.MetricName != nil {
+			return fmt.Sprintf("%s %s/%s", aws.StringValue(cm.Statistic), aws.StringValue(cm.Namespace), aws.StringValue(cm.MetricName))
+		}
+		return "metric math expression"
+	}
+	return "unknown metric"
+}
+
+// stepRange renders the bounds of a step, relative to the alarm threshold.
+func stepRange(step *autoscaling.StepAdjustment) string {
+	lower, upper := "-∞", "∞"
+	if step.MetricIntervalLowerBound != nil {
+		lower = formatFloat(*step.MetricIntervalLowerBound)
+	}
+	if step.MetricIntervalUpperBound != nil {
+		upper = formatFloat(*step.MetricIntervalUpperBound)
+	}
+	return fmt.Sprintf("[%s, %s)", lower, upper)
+}
+
+func formatFloat(f float64) string {
+	if f == math.Trunc(f) {
+		return fmt.Sprintf("%.0f", f)
+	}
+	return fmt.Sprintf("%.2f", f)
+}
+
+func (m Model) renderAlarm(name string) []string {
+	alarm, ok := m.details.Alarms[name]
+	if !ok {
+		return []string{theme.Current.Subtle.Render("    alarm " + name)}
+	}
+	state := aws.StringValue(alarm.StateValue)
+	switch state {
+	case cloudwatch.StateValueAlarm:
+		state = theme.Current.Failed.Render(state)
+	case cloudwatch.StateValueOk:
+		state = theme.Current.OK.Render(state)
+	default:
+		state = theme.Current.Warning.Render(state)
+	}
+	operator, ok := comparisonOperators[aws.StringValue(alarm.ComparisonOperator)]
+	if !ok {
+		operator = aws.StringValue(alarm.ComparisonOperator)
+	}
+	lines := []string{
+		fmt.Sprintf("    alarm %s %s", name, state),
+		theme.Current.Subtle.Render(fmt.Sprintf("      %s %s %s %s, %d of %d periods of %ds",
+			aws.StringValue(alarm.Statistic), aws.StringValue(alarm.MetricName), operator, formatFloat(aws.Float64Value(alarm.Threshold)),
+			aws.Int64Value(alarm.DatapointsToAlarm), aws.Int64Value(alarm.EvaluationPeriods), aws.Int64Value(alarm.Period))),
+	}
+	if alarm.StateReason != nil {
+		lines = append(lines, theme.Current.Subtle.Render(fmt.Sprintf("      since %s: %s", formatTime(aws.TimeValue(alarm.StateUpdatedTimestamp)), *alarm.StateReason)))
+	}
+	return lines
+}
+
+func renderScheduledAction(action *autoscaling.ScheduledAction) []string {
+	schedule := aws.StringValue(action.Schedule)
+	if action.Timezone != nil {
+		schedule = schedule + " " + *action.Timezone
+	}
+	lines := []string{fmt.Sprintf("  %s %s", aws.StringValue(action.ScheduledActionName), theme.Current.Subtle.Render(schedule))}
+	if target := action.ScalableTargetAction; target != nil {
+		capacity := []string{}
+		if target.MinCapacity != nil {
+			capacity = append(capacity, fmt.Sprintf("min %d", *target.MinCapacity))
+		}
+		if target.MaxCapacity != nil {
+			capacity = append(capacity, fmt.Sprintf("max %d", *target.MaxCapacity))
+		}
+		lines = append(lines, "    set "+strings.Join(capacity, ", "))
+	}
+	if action.StartTime != nil || action.EndTime != nil {
+		window := "    active"
+		if action.StartTime != nil {
+			window = window + " from " + formatTime(*action.StartTime)
+		}
+		if action.EndTime != nil {
+			window = window + " until " + formatTime(*action.EndTime)
+		}
+		lines = append(lines, theme.Current.Subtle.Render(window))
+	}
+	return lines
+}
+
+func renderActivity(activity *autoscaling.ScalingActivity) []string {
+	status := aws.StringValue(activity.StatusCode)
+	switch status {
+	case autoscaling.ScalingActivityStatusCodeSuccessful:
+		status = theme.Current.OK.Render(status)
+	case autoscaling.ScalingActivityStatusCodeFailed, autoscaling.ScalingActivityStatusCodeUnfulfilled:
+		status = theme.Current.Failed.Render(status)
+	default:
+		status = theme.Current.Warning.Render(status)
+	}
+	start := aws.TimeValue(activity.StartTime)
+	lines := []string{
+		fmt.Sprintf("  %s %s %s", formatTime(start), theme.Current.Subtle.Render("("+humanizer.Time(start)+")"), status),
+		"    " + aws.StringValue(activity.Description),
+		theme.Current.Subtle.Render("    cause: " + aws.StringValue(activity.Cause)),
+	}
+	if activity.StatusMessage != nil && *activity.StatusMessage != "" {
+		lines = append(lines, theme.Current.Subtle.Render("    "+*activity.StatusMessage))
+	}
+	return lines
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Common.Reload, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render("auto scaling")+" "+theme.Current.Subtle.Render(m.service), "", m.viewport.View(), "", m.footerView())
+}
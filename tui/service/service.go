@@ -3,20 +3,41 @@ package service
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ecs"
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/mtyurt/ecstui/audit"
 	"github.com/mtyurt/ecstui/logger"
 	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/actions"
 	"github.com/mtyurt/ecstui/tui/deployment"
+	"github.com/mtyurt/ecstui/tui/errorview"
 	"github.com/mtyurt/ecstui/tui/events"
+	"github.com/mtyurt/ecstui/tui/images"
+	"github.com/mtyurt/ecstui/tui/instances"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/layout"
+	"github.com/mtyurt/ecstui/tui/network"
+	"github.com/mtyurt/ecstui/tui/placement"
+	"github.com/mtyurt/ecstui/tui/revisions"
+	"github.com/mtyurt/ecstui/tui/rollback"
+	"github.com/mtyurt/ecstui/tui/scaling"
+	"github.com/mtyurt/ecstui/tui/targets"
+	"github.com/mtyurt/ecstui/tui/taskdef"
+	"github.com/mtyurt/ecstui/tui/tasks"
 	"github.com/mtyurt/ecstui/tui/taskset"
+	"github.com/mtyurt/ecstui/tui/tasktable"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
 	"github.com/mtyurt/ecstui/types"
 	"github.com/mtyurt/ecstui/utils"
 )
@@ -28,54 +49,154 @@ const (
 	loaded
 	errorState
 	eventsOnly
+	taskDefOnly
+	revisionsOnly
+	imagesOnly
+	scalingOnly
+	placementOnly
+	tasksOnly
+	instancesOnly
+	networkOnly
+	targetsOnly
+	actionsOnly
+	rollbackOnly
 )
 
 var (
-	styles = list.DefaultStyles()
-
 	smallSectionStyle = lipgloss.NewStyle().
 				Width(35).
 				Height(8).
 				Margin(0, 1, 0, 0).
 				Align(lipgloss.Center).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
+				BorderStyle(lipgloss.NormalBorder())
 	largeSectionStyle = lipgloss.NewStyle().
 				Width(150).
 				Height(10).
 				Margin(0, 1, 0, 0).
 				Align(lipgloss.Left, lipgloss.Center).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
-	foreground             = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#383838", Dark: "#D9DCCF"})
-	subtle                 = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B")).Align(lipgloss.Left)
-	helpStyle              = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B")).AlignHorizontal(lipgloss.Right)
-	helpStyleKey           = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9BCC")).Bold(true)
-	helpStyleVal           = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B"))
-	lastUpdateSpinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	minWidth               = 120
-	taskSetWidth           = 32
+				BorderStyle(lipgloss.NormalBorder())
+	helpStyle = lipgloss.NewStyle().AlignHorizontal(lipgloss.Right)
+	// minWidth keeps the sections' widths positive, narrower terminals cut
+	// them off
+	minWidth = 40
+	// the task, deployment and taskDef boxes share the width between them
+	// within these bounds, unless they are stacked
+	minSmallSectionWidth = 28
+	maxSmallSectionWidth = 60
+)
+
+// the zone kinds of the overview, besides the task set, deployment and task
+// row zones of their sections: the sections keyed by their number and the
+// footer's help entries keyed by their key
+const (
+	sectionZone = "section"
+	keyZone     = "key"
 )
 
 type Model struct {
-	state                   sessionState
-	cluster, serviceArn     string
-	service                 string
-	spinner                 spinnertui.Model
-	ecsStatus               *types.ServiceStatus
-	err                     error
-	width, height           int
-	eventsViewport          *events.Model
-	taskSetView             *taskset.Model
-	deploymentsView         *deployment.Model
-	Focused                 bool
-	lastUpdateTime          time.Time
-	ecsStatusFetcher        func(string, string) (*types.ServiceStatus, error)
-	taskSetStatusFetcher    types.TaskSetStatusFetcher
-	deploymentStatusFetcher types.DeploymentStatusFetcher
-	autoRefresh             bool
-	footerSpinner           spinner.Model
-	showFooterSpinner       bool
+	state               sessionState
+	cluster, serviceArn string
+	service             string
+	spinner             spinnertui.Model
+	ecsStatus           *types.ServiceStatus
+	err                 error
+	width, height       int
+	eventsViewport      *events.Model
+	taskSetView         *taskset.Model
+	deploymentsView     *deployment.Model
+	taskDefView         *taskdef.Model
+	revisionsView       *revisions.Model
+	imagesView          *images.Model
+	scalingView         *scaling.Model
+	placementView       *placement.Model
+	tasksView           *tasks.Model
+	instancesView       *instances.Model
+	networkView         *network.Model
+	targetsView         *targets.Model
+	actionsView         *actions.Model
+	rollbackView        *rollback.Model
+	Focused             bool
+	lastUpdateTime      time.Time
+	fetchers            Fetchers
+	autoRefresh         bool
+	footerSpinner       spinner.Model
+	showFooterSpinner   bool
+	recovery            errorview.Recovery
+	errorView           errorview.Model
+	// writers and auditLog are only set with --allow-writes.
+	writers  *actions.Writers
+	auditLog *audit.Log
+	// watch follows the deployment started by a rollback until it settles.
+	watch *deploymentWatch
+	// overview scrolls the sections when they do not fit the screen.
+	overview viewport.Model
+	// section has the focus on the overview, noSection if none has.
+	section section
+}
+
+// section is a box of the overview. tab moves the focus between them and
+// enter opens the focused one in its own screen.
+type section int
+
+const (
+	noSection section = iota
+	taskSection
+	deploymentSection
+	taskDefSection
+	taskSetsSection
+	eventsSection
+)
+
+func (s section) zone() string {
+	return zone.ID(sectionZone, strconv.Itoa(int(s)))
+}
+
+// screen is the key opening the screen that goes into the section's
+// details: the auto scaling behind the task counts, the placement behind the
+// deployment configuration, the tasks of the task sets or deployments.
+func (s section) screen() key.Binding {
+	switch s {
+	case taskSection:
+		return keys.Map.Service.Scaling
+	case deploymentSection:
+		return keys.Map.Service.Placement
+	case taskDefSection:
+		return keys.Map.Service.TaskDefinition
+	case taskSetsSection:
+		return keys.Map.Service.Tasks
+	}
+	return keys.Map.Service.Events
+}
+
+// deploymentWatch is the deployment watch mode: the service is refreshed
+// every watchInterval until the deployment of taskDefinition completes or
+// fails.
+type deploymentWatch struct {
+	taskDefinition string
+	deployment     *ecs.Deployment
+	done           bool
+}
+
+const watchInterval = 5 * time.Second
+
+type watchTickMsg time.Time
+
+// Fetchers are the AWS calls made by the service detail screen and its
+// sections.
+type Fetchers struct {
+	ServiceStatus    func(cluster, service string) (*types.ServiceStatus, error)
+	TaskSetStatus    types.TaskSetStatusFetcher
+	DeploymentStatus types.DeploymentStatusFetcher
+	TaskDefinition   types.TaskDefinitionFetcher
+	Revisions        types.TaskDefinitionRevisionsFetcher
+	ImageDetails     types.ImageDetailsFetcher
+	ScalingDetails   types.ScalingDetailsFetcher
+	PlacementDetails types.PlacementDetailsFetcher
+	ServiceTasks     types.ServiceTasksFetcher
+	Instances        types.ContainerInstancesFetcher
+	InstanceTasks    types.InstanceTasksFetcher
+	NetworkDetails   types.NetworkDetailsFetcher
+	TargetGroup      types.TargetGroupDetailsFetcher
 }
 
 type errMsg struct{ err error }
@@ -86,24 +207,36 @@ type ServiceMsg *types.ServiceStatus
 
 type TickMsg time.Time
 
-func New(cluster, service, serviceArn string, ecsStatusFetcher func(string, string) (*types.ServiceStatus, error), taskSetStatusFetcher types.TaskSetStatusFetcher, deploymentStatusFetcher types.DeploymentStatusFetcher) Model {
-	return Model{cluster: cluster,
-		serviceArn:              serviceArn,
-		service:                 service,
-		spinner:                 spinnertui.New(fmt.Sprintf("Fetching %s status...", service)),
-		Focused:                 true,
-		ecsStatusFetcher:        ecsStatusFetcher,
-		taskSetStatusFetcher:    taskSetStatusFetcher,
-		deploymentStatusFetcher: deploymentStatusFetcher,
-		footerSpinner:           spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(lastUpdateSpinnerStyle)),
-		showFooterSpinner:       false,
+// WriteAccess enables the actions view. Without it the service screen is
+// read-only.
+type WriteAccess struct {
+	Writers  actions.Writers
+	AuditLog *audit.Log
+}
+
+func New(cluster, service, serviceArn string, fetchers Fetchers, writeAccess *WriteAccess, recovery errorview.Recovery) Model {
+	m := Model{cluster: cluster,
+		serviceArn:        serviceArn,
+		service:           service,
+		spinner:           spinnertui.New(fmt.Sprintf("Fetching %s status...", service)),
+		Focused:           true,
+		fetchers:          fetchers,
+		footerSpinner:     spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(theme.Current.Highlight)),
+		showFooterSpinner: false,
+		recovery:          recovery,
+		overview:          keys.NewViewport(0, 0),
 	}
+	if writeAccess != nil {
+		m.writers = &writeAccess.Writers
+		m.auditLog = writeAccess.AuditLog
+	}
+	return m
 }
 
 func (m Model) fetchServiceStatus() tea.Msg {
 	logger.Println("started fetching service status")
 	defer logger.Println("finished fetching service status")
-	serviceConfig, err := m.ecsStatusFetcher(m.cluster, m.service)
+	serviceConfig, err := m.fetchers.ServiceStatus(m.cluster, m.service)
 	if err != nil {
 		return errMsg{err}
 	}
@@ -113,12 +246,12 @@ func (m Model) fetchServiceStatus() tea.Msg {
 
 func (m Model) fetchTaskSetStatus() taskset.StatusFetcher {
 	return func(taskSets []*ecs.TaskSet) (*types.TaskSetStatus, error) {
-		return m.taskSetStatusFetcher(m.cluster, m.service, taskSets)
+		return m.fetchers.TaskSetStatus(m.cluster, m.service, taskSets)
 	}
 }
 func (m Model) fetchDeploymentStatus() deployment.DeploymentsFetcher {
 	return func(deployments []*ecs.Deployment) (*types.DeploymentStatus, error) {
-		return m.deploymentStatusFetcher(m.cluster, m.service, deployments, m.ecsStatus.Ecs.LoadBalancers)
+		return m.fetchers.DeploymentStatus(m.cluster, m.service, deployments, m.ecsStatus.Ecs.LoadBalancers)
 	}
 }
 
@@ -129,15 +262,85 @@ func (m *Model) SetSize(width, height int) {
 	}
 	m.width = width
 	m.height = height
+	m.errorView.SetSize(width, height)
 	if m.eventsViewport != nil {
-		m.eventsViewport.SetSize(width-10, 0)
+		m.eventsViewport.SetSize(width, height-lipgloss.Height(m.titleView()))
 	}
 	if m.taskSetView != nil {
-		m.taskSetView.SetSize(width-19, 0)
+		m.taskSetView.SetSize(m.largeSectionWidth(), 0)
 	}
 
 	if m.deploymentsView != nil {
-		m.deploymentsView.SetSize(width-20, 0)
+		m.deploymentsView.SetSize(m.largeSectionWidth(), 0)
+	}
+	if m.taskDefView != nil {
+		m.taskDefView.SetSize(width-4, height-4)
+	}
+	if m.revisionsView != nil {
+		m.revisionsView.SetSize(width-4, height-4)
+	}
+	if m.imagesView != nil {
+		m.imagesView.SetSize(width-4, height-4)
+	}
+	if m.scalingView != nil {
+		m.scalingView.SetSize(width-4, height-4)
+	}
+	if m.placementView != nil {
+		m.placementView.SetSize(width-4, height-4)
+	}
+	if m.tasksView != nil {
+		m.tasksView.SetSize(width-4, height-4)
+	}
+	if m.instancesView != nil {
+		m.instancesView.SetSize(width-4, height-4)
+	}
+	if m.networkView != nil {
+		m.networkView.SetSize(width-4, height-4)
+	}
+	if m.targetsView != nil {
+		m.targetsView.SetSize(width-4, height-4)
+	}
+	if m.actionsView != nil {
+		m.actionsView.SetSize(width-4, height-4)
+	}
+	if m.rollbackView != nil {
+		m.rollbackView.SetSize(width-4, height-4)
+	}
+}
+
+func doWatchTick() tea.Cmd {
+	return tea.Tick(watchInterval, func(t time.Time) tea.Msg {
+		return watchTickMsg(t)
+	})
+}
+
+// startWatch closes the open view and follows the deployment the request
+// started on the overview.
+func (m *Model) startWatch(request actions.Request) tea.Cmd {
+	input, ok := request.Input.(*ecs.UpdateServiceInput)
+	if !ok || input.TaskDefinition == nil {
+		return nil
+	}
+	m.actionsView = nil
+	m.rollbackView = nil
+	m.backToOverview()
+	m.watch = &deploymentWatch{taskDefinition: *input.TaskDefinition}
+	return doWatchTick()
+}
+
+// updateWatch picks up the watched deployment from a fresh service status.
+func (m *Model) updateWatch() {
+	if m.watch == nil || m.watch.done {
+		return
+	}
+	for _, d := range m.ecsStatus.Ecs.Deployments {
+		if aws.StringValue(d.TaskDefinition) == m.watch.taskDefinition && aws.StringValue(d.Status) == "PRIMARY" {
+			m.watch.deployment = d
+			switch aws.StringValue(d.RolloutState) {
+			case ecs.DeploymentRolloutStateCompleted, ecs.DeploymentRolloutStateFailed:
+				m.watch.done = true
+			}
+		}
 	}
 }
 
@@ -165,13 +368,15 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		logger.Println("service status", m.ecsStatus)
 		m.lastUpdateTime = time.Now()
 		m.initializeSections()
-		if m.state == loaded {
+		m.updateWatch()
+		fresh := m.state == initial
+		if !fresh {
 			if m.taskSetView != nil { // if it's already loaded, we don't need to recreate the tasksetview
-				taskSetView, cmd := m.taskSetView.Refresh()
+				taskSetView, cmd := m.taskSetView.Refresh(m.ecsStatus.Ecs.TaskSets)
 				m.taskSetView = &taskSetView
 				cmds = append(cmds, cmd)
 			} else if m.deploymentsView != nil { // if it's already loaded, we don't need to recreate the deploymentsview
-				deploymentsView, cmd := m.deploymentsView.Refresh()
+				deploymentsView, cmd := m.deploymentsView.Refresh(m.ecsStatus.Ecs.Deployments)
 				m.deploymentsView = &deploymentsView
 				cmds = append(cmds, cmd)
 			}
@@ -182,36 +387,200 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			cmd = m.deploymentsView.Init()
 			cmds = append(cmds, cmd)
 		}
-		m.state = loaded
+		if fresh { // sub views stay open across refreshes
+			m.state = loaded
+		}
+		m.err = nil
 		m.showFooterSpinner = false
 	case errMsg:
 		logger.Println("servicedetail error")
 		m.err = msg
-		m.state = errorState
+		m.showFooterSpinner = false
+		if m.state == initial { // refresh failures keep the last good data on screen
+			m.errorView = errorview.New(msg.err, m.recovery, keys.Map.Global.Back)
+			m.errorView.SetSize(m.width, m.height)
+			m.state = errorState
+		}
+	case tasks.OpenInstanceMsg:
+		if m.state == tasksOnly {
+			cmds = append(cmds, m.openInstancesView(msg.ContainerInstanceArn))
+		}
+	case tasks.StopTaskMsg:
+		if m.state == tasksOnly && m.writers != nil {
+			view := actions.NewConfirm(actions.StopTask(*m.writers, msg.Task), m.auditLog, m.ecsStatus.Ecs, m.width-4, m.height-4)
+			m.actionsView = &view
+			m.state = actionsOnly
+		}
+	case tasks.ExecMsg:
+		if m.state == tasksOnly && m.writers != nil {
+			view := actions.NewExec(*m.writers, m.auditLog, m.ecsStatus.Ecs, msg.Task, m.width-4, m.height-4)
+			m.actionsView = &view
+			m.state = actionsOnly
+		}
+	case actions.DoneMsg:
+		m.showFooterSpinner = true
+		cmds = append(cmds, m.fetchServiceStatus, m.footerSpinner.Tick)
+		if msg.Request.WatchDeployment {
+			cmds = append(cmds, m.startWatch(msg.Request))
+		}
+	case watchTickMsg:
+		if m.watch != nil && !m.watch.done {
+			m.showFooterSpinner = true
+			cmds = append(cmds, m.fetchServiceStatus, m.footerSpinner.Tick, doWatchTick())
+		}
+	case errorview.RetryMsg:
+		if m.state == errorState {
+			logger.Println("servicedetail retrying")
+			m.err = nil
+			m.state = initial
+			cmds = append(cmds, m.fetchServiceStatus, m.spinner.SpinnerTick())
+		}
+	case tea.MouseMsg:
+		if m.state == loaded {
+			cmds = append(cmds, m.mouse(msg))
+		}
 	case tea.KeyMsg:
 		logger.Printf("servicedetail update key: %s\n", msg)
 		if m.state == loaded {
-			switch k := msg.String(); k {
-			case "ctrl+e", "ctrl+shift+e":
-				eventsViewport := events.New(m.service, 200, 50, m.ecsStatus.Ecs.Events)
-				m.eventsViewport = &eventsViewport
-				m.state = eventsOnly
-				m.Focused = false
-			case "ctrl+t", "ctrl+shift+t": // toggle auto refresh
+			switch {
+			case key.Matches(msg, keys.Map.Service.Events):
+				m.openEventsView()
+			case key.Matches(msg, keys.Map.Service.AutoRefresh): // toggle auto refresh
 				m.autoRefresh = !m.autoRefresh
 				if m.autoRefresh {
 					cmds = append(cmds, doTick())
 				}
-			case "ctrl+r", "ctrl+shift+r": // refresh
+			case key.Matches(msg, keys.Map.Service.Refresh):
 				m.showFooterSpinner = true
 				cmds = append(cmds, m.fetchServiceStatus, m.footerSpinner.Tick)
+			case key.Matches(msg, keys.Map.Service.TaskDefinition):
+				cmds = append(cmds, m.openTaskDefViewer())
+			case key.Matches(msg, keys.Map.Service.Diff):
+				if candidates := m.taskDefCandidates(); taskdef.Comparable(candidates) {
+					view := taskdef.NewDiff(m.fetchers.TaskDefinition, candidates, m.width-4, m.height-4)
+					m.openTaskDefView(&view)
+					cmds = append(cmds, view.Init())
+				}
+			case key.Matches(msg, keys.Map.Service.Revisions): // task definition revisions
+				if m.ecsStatus.Ecs.TaskDefinition != nil {
+					family, _ := utils.SplitTaskDefinitionArn(*m.ecsStatus.Ecs.TaskDefinition)
+					view := revisions.New(family, m.fetchers.Revisions, m.fetchers.TaskDefinition, m.taskDefCandidates(), m.width-4, m.height-4)
+					m.revisionsView = &view
+					m.state = revisionsOnly
+					m.Focused = false
+					cmds = append(cmds, view.Init())
+				}
+			case key.Matches(msg, keys.Map.Service.Images):
+				view := images.New(m.fetchers.ImageDetails, m.imageGroups(), m.width-4, m.height-4)
+				m.imagesView = &view
+				m.state = imagesOnly
+				m.Focused = false
+				cmds = append(cmds, view.Init())
+			case key.Matches(msg, keys.Map.Service.Scaling):
+				cmds = append(cmds, m.openScalingView())
+			case key.Matches(msg, keys.Map.Service.Placement): // capacity providers and placement
+				cmds = append(cmds, m.openPlacementView())
+			case key.Matches(msg, keys.Map.Service.Tasks):
+				cmds = append(cmds, m.openTasksView())
+			case key.Matches(msg, keys.Map.Service.Instances): // container instances
+				cmds = append(cmds, m.openInstancesView(""))
+			case key.Matches(msg, keys.Map.Service.Network): // network and service discovery
+				view := network.New(m.fetchers.NetworkDetails, m.ecsStatus.Ecs, m.width-4, m.height-4)
+				m.networkView = &view
+				m.state = networkOnly
+				m.Focused = false
+				cmds = append(cmds, view.Init())
+			case key.Matches(msg, keys.Map.Service.Targets): // load balancer targets
+				conns, tasks, instanceIDs := m.connectionsAndTasks()
+				view := targets.New(m.fetchers.TargetGroup, targets.Groups(conns), tasks, instanceIDs, m.width-4, m.height-4)
+				m.targetsView = &view
+				m.state = targetsOnly
+				m.Focused = false
+				cmds = append(cmds, view.Init())
+			case key.Matches(msg, keys.Map.Service.Rollback): // roll back the task definition
+				if m.writers != nil && m.canRollback() {
+					view := rollback.New(m.fetchers.Revisions, m.fetchers.TaskDefinition, *m.writers, m.auditLog, m.ecsStatus.Ecs, m.taskDefCandidates(), m.width-4, m.height-4)
+					m.rollbackView = &view
+					m.state = rollbackOnly
+					m.Focused = false
+					cmds = append(cmds, view.Init())
+				}
+			case key.Matches(msg, keys.Map.Service.Actions): // write actions
+				if m.writers != nil {
+					conns, _, _ := m.connectionsAndTasks()
+					view := actions.NewMenu(*m.writers, m.auditLog, m.ecsStatus.Ecs, conns, m.width-4, m.height-4)
+					m.actionsView = &view
+					m.state = actionsOnly
+					m.Focused = false
+					cmds = append(cmds, view.Init())
+				}
+			case m.boxFocused() && key.Matches(msg, keys.Map.Global.Back):
+				m.blurBoxes()
+			case m.section != noSection && key.Matches(msg, keys.Map.Global.Back):
+				m.section = noSection
+				m.Focused = true
+			case key.Matches(msg, keys.Map.Sections.Next):
+				m.cycleSectionFocus(1)
+			case key.Matches(msg, keys.Map.Sections.Prev):
+				m.cycleSectionFocus(-1)
+			case m.section != noSection && key.Matches(msg, keys.Map.Sections.Open):
+				cmds = append(cmds, m.openSection())
+			case key.Matches(msg, keys.Map.TaskTable.NextBox):
+				m.cycleBoxFocus(1)
+			case key.Matches(msg, keys.Map.TaskTable.PrevBox):
+				m.cycleBoxFocus(-1)
+			case m.boxFocused(): // the focused task table takes the keys below
+			default: // scroll the sections
+				m.syncOverview()
+				m.overview, cmd = m.overview.Update(msg)
+				cmds = append(cmds, cmd)
 			}
 
-		} else {
-			if k := msg.String(); k == "esc" && m.state == eventsOnly && m.eventsViewport.Focused() {
-				m.state = loaded
-				m.Focused = true
+		} else if key.Matches(msg, keys.Map.Global.Back) {
+			if m.state == eventsOnly && m.eventsViewport.Focused() {
+				m.backToOverview()
 				m.eventsViewport = nil
+			} else if m.state == taskDefOnly && m.taskDefView.Focused() {
+				m.backToOverview()
+				m.taskDefView = nil
+			} else if m.state == revisionsOnly && m.revisionsView.Focused() {
+				m.backToOverview()
+				m.revisionsView = nil
+			} else if m.state == imagesOnly && m.imagesView.Focused() {
+				m.backToOverview()
+				m.imagesView = nil
+			} else if m.state == scalingOnly && m.scalingView.Focused() {
+				m.backToOverview()
+				m.scalingView = nil
+			} else if m.state == placementOnly && m.placementView.Focused() {
+				m.backToOverview()
+				m.placementView = nil
+			} else if m.state == tasksOnly && m.tasksView.Focused() {
+				m.backToOverview()
+				m.tasksView = nil
+			} else if m.state == instancesOnly && m.instancesView.Focused() {
+				m.instancesView = nil
+				if m.tasksView != nil { // opened from a task, go back to it
+					m.state = tasksOnly
+				} else {
+					m.backToOverview()
+				}
+			} else if m.state == networkOnly && m.networkView.Focused() {
+				m.backToOverview()
+				m.networkView = nil
+			} else if m.state == targetsOnly && m.targetsView.Focused() {
+				m.backToOverview()
+				m.targetsView = nil
+			} else if m.state == rollbackOnly && m.rollbackView.Focused() {
+				m.backToOverview()
+				m.rollbackView = nil
+			} else if m.state == actionsOnly && m.actionsView.Focused() {
+				m.actionsView = nil
+				if m.tasksView != nil { // stopping a task, go back to the tasks
+					m.state = tasksOnly
+				} else {
+					m.backToOverview()
+				}
 			}
 		}
 
@@ -228,18 +597,68 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case initial:
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
+	case errorState:
+		m.errorView, cmd = m.errorView.Update(msg)
+		cmds = append(cmds, cmd)
 	case eventsOnly:
 		eventsViewport, cmd := m.eventsViewport.Update(msg)
 		m.eventsViewport = &eventsViewport
 		cmds = append(cmds, cmd)
+	case taskDefOnly:
+		taskDefView, cmd := m.taskDefView.Update(msg)
+		m.taskDefView = &taskDefView
+		cmds = append(cmds, cmd)
+	case revisionsOnly:
+		revisionsView, cmd := m.revisionsView.Update(msg)
+		m.revisionsView = &revisionsView
+		cmds = append(cmds, cmd)
+	case imagesOnly:
+		imagesView, cmd := m.imagesView.Update(msg)
+		m.imagesView = &imagesView
+		cmds = append(cmds, cmd)
+	case scalingOnly:
+		scalingView, cmd := m.scalingView.Update(msg)
+		m.scalingView = &scalingView
+		cmds = append(cmds, cmd)
+	case placementOnly:
+		placementView, cmd := m.placementView.Update(msg)
+		m.placementView = &placementView
+		cmds = append(cmds, cmd)
+	case tasksOnly:
+		tasksView, cmd := m.tasksView.Update(msg)
+		m.tasksView = &tasksView
+		cmds = append(cmds, cmd)
+	case instancesOnly:
+		instancesView, cmd := m.instancesView.Update(msg)
+		m.instancesView = &instancesView
+		cmds = append(cmds, cmd)
+	case networkOnly:
+		networkView, cmd := m.networkView.Update(msg)
+		m.networkView = &networkView
+		cmds = append(cmds, cmd)
+	case targetsOnly:
+		targetsView, cmd := m.targetsView.Update(msg)
+		m.targetsView = &targetsView
+		cmds = append(cmds, cmd)
+	case actionsOnly:
+		actionsView, cmd := m.actionsView.Update(msg)
+		m.actionsView = &actionsView
+		cmds = append(cmds, cmd)
+	case rollbackOnly:
+		rollbackView, cmd := m.rollbackView.Update(msg)
+		m.rollbackView = &rollbackView
+		cmds = append(cmds, cmd)
 	}
 
-	if m.taskSetView != nil {
+	// the task tables take no keys behind other screens, but keep their
+	// focus for when the overview is back
+	_, isKey := msg.(tea.KeyMsg)
+	if m.taskSetView != nil && (!isKey || m.state == loaded) {
 		taskSetView, cmd := m.taskSetView.Update(msg)
 		m.taskSetView = &taskSetView
 		cmds = append(cmds, cmd)
 	}
-	if m.deploymentsView != nil {
+	if m.deploymentsView != nil && (!isKey || m.state == loaded) {
 		deploymentsView, cmd := m.deploymentsView.Update(msg)
 		m.deploymentsView = &deploymentsView
 		cmds = append(cmds, cmd)
@@ -257,49 +676,388 @@ func (m *Model) initializeSections() {
 	if serviceStatus.TaskSets != nil && len(serviceStatus.TaskSets) > 0 {
 		status := m.ecsStatus
 		if m.taskSetView == nil {
-			m.taskSetView = taskset.New(m.fetchTaskSetStatus(), status.Ecs.TaskSets, m.width-19, m.height)
+			m.taskSetView = taskset.New(m.fetchTaskSetStatus(), status.Ecs.TaskSets, m.largeSectionWidth(), m.height)
 		}
 	}
 
 	if serviceStatus.Deployments != nil && len(serviceStatus.Deployments) > 0 {
 		if m.deploymentsView == nil {
-			m.deploymentsView = deployment.New(m.fetchDeploymentStatus(), serviceStatus.Deployments, m.width-20, m.height)
+			m.deploymentsView = deployment.New(m.fetchDeploymentStatus(), serviceStatus.Deployments, m.largeSectionWidth(), m.height)
 		}
 	}
 }
 
-func (m Model) taskView() string {
+// backToOverview shows the overview again, with the focus and scroll
+// position it had when the closed screen was opened.
+func (m *Model) backToOverview() {
+	m.state = loaded
+	m.Focused = m.section == noSection
+}
+
+// sections lists the sections on the overview, top to bottom.
+func (m Model) sections() []section {
+	sections := []section{taskSection, deploymentSection}
+	if m.ecsStatus.Ecs.TaskDefinition != nil {
+		sections = append(sections, taskDefSection)
+	}
+	sections = append(sections, taskSetsSection)
+	if len(m.ecsStatus.Ecs.Events) > 0 {
+		sections = append(sections, eventsSection)
+	}
+	return sections
+}
+
+// cycleSectionFocus focuses the next section, or the previous one for a
+// negative step, wrapping around, and scrolls it into sight. esc takes the
+// focus back rather than leaving the service.
+func (m *Model) cycleSectionFocus(step int) {
+	m.blurBoxes()
+	sections := m.sections()
+	i := slices.Index(sections, m.section)
+	switch {
+	case i < 0 && step < 0:
+		i = len(sections) - 1
+	case i < 0:
+		i = 0
+	default:
+		i = (i + step%len(sections) + len(sections)) % len(sections)
+	}
+	m.section = sections[i]
+	m.Focused = false
+	m.scrollToSection()
+}
+
+// openSection opens the screen of the focused section.
+func (m *Model) openSection() tea.Cmd {
+	switch m.section {
+	case taskSection:
+		return m.openScalingView()
+	case deploymentSection:
+		return m.openPlacementView()
+	case taskDefSection:
+		return m.openTaskDefViewer()
+	case taskSetsSection:
+		return m.openTasksView()
+	case eventsSection:
+		m.openEventsView()
+	}
+	return nil
+}
+
+// cycleBoxFocus gives the keys to the task table of the next task set or
+// deployment box, or the previous one for a negative step, focusing their
+// section. esc takes them back rather than leaving the service.
+func (m *Model) cycleBoxFocus(step int) {
+	switch {
+	case m.taskSetView != nil:
+		m.taskSetView.CycleFocus(step)
+	case m.deploymentsView != nil:
+		m.deploymentsView.CycleFocus(step)
+	}
+	if m.boxFocused() {
+		m.section = taskSetsSection
+		m.Focused = false
+		m.scrollToSection()
+	}
+}
+
+func (m *Model) blurBoxes() {
+	if m.taskSetView != nil {
+		m.taskSetView.Blur()
+	}
+	if m.deploymentsView != nil {
+		m.deploymentsView.Blur()
+	}
+}
+
+// boxFocused reports whether a task table has the keys.
+func (m Model) boxFocused() bool {
+	return (m.taskSetView != nil && m.taskSetView.Focused()) || (m.deploymentsView != nil && m.deploymentsView.Focused())
+}
+
+func (m *Model) openEventsView() {
+	eventsViewport := events.New(m.service, m.width, m.height-lipgloss.Height(m.titleView()), m.ecsStatus.Ecs.Events)
+	m.eventsViewport = &eventsViewport
+	m.state = eventsOnly
+	m.Focused = false
+}
+
+// openTaskDefViewer shows the service's task definition, if it has one.
+func (m *Model) openTaskDefViewer() tea.Cmd {
+	if m.ecsStatus.Ecs.TaskDefinition == nil {
+		return nil
+	}
+	view := taskdef.NewViewer(m.fetchers.TaskDefinition, taskdef.Candidate{Label: "service", Arn: *m.ecsStatus.Ecs.TaskDefinition}, m.width-4, m.height-4)
+	m.openTaskDefView(&view)
+	return view.Init()
+}
+
+func (m *Model) openScalingView() tea.Cmd {
+	view := scaling.New(m.fetchers.ScalingDetails, m.cluster, m.service, m.width-4, m.height-4)
+	m.scalingView = &view
+	m.state = scalingOnly
+	m.Focused = false
+	return view.Init()
+}
+
+func (m *Model) openPlacementView() tea.Cmd {
+	view := placement.New(m.fetchers.PlacementDetails, m.cluster, m.ecsStatus.Ecs, m.width-4, m.height-4)
+	m.placementView = &view
+	m.state = placementOnly
+	m.Focused = false
+	return view.Init()
+}
+
+func (m *Model) openTasksView() tea.Cmd {
+	fetcher, cluster, service := m.fetchers.ServiceTasks, m.cluster, m.service
+	return m.openTasks(tasks.New("tasks of "+service, func() ([]*ecs.Task, error) { return fetcher(cluster, service) }, true, m.width-4, m.height-4))
+}
+
+// openBoxTasks lists the tasks of a task set or deployment box as the box
+// last fetched them, with the detail of taskArn open if it is set.
+func (m *Model) openBoxTasks(id, taskArn string) tea.Cmd {
+	var boxTasks []*ecs.Task
+	if m.taskSetView != nil {
+		boxTasks = m.taskSetView.Tasks(id)
+	} else if m.deploymentsView != nil {
+		boxTasks = m.deploymentsView.Tasks(id)
+	}
+	view := tasks.New("tasks of "+id, func() ([]*ecs.Task, error) { return boxTasks, nil }, true, m.width-4, m.height-4)
+	view.OpenDetail(taskArn)
+	return m.openTasks(view)
+}
+
+func (m *Model) openTasks(view tasks.Model) tea.Cmd {
+	if m.writers != nil {
+		view.AllowWrites()
+	}
+	m.tasksView = &view
+	m.state = tasksOnly
+	m.Focused = false
+	return view.Init()
+}
+
+// mouse acts on a click or a wheel turn on the overview. A click on a help
+// entry of the footer presses its key, on a task row or a task set or
+// deployment box opens its tasks and on a section opens it the way enter
+// does. The wheel scrolls the task table under it, or else the sections.
+func (m *Model) mouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	hits := zone.Scan(m.View()).At(msg.X, msg.Y)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		step := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			step = -1
+		}
+		if m.scrollBox(hits, step) {
+			return nil
+		}
+		var cmd tea.Cmd
+		m.syncOverview()
+		m.overview, cmd = m.overview.Update(msg)
+		return cmd
+	case tea.MouseButtonLeft:
+		if k, ok := hits.Key(keyZone); ok {
+			return func() tea.Msg { return keys.Press(k) }
+		}
+		m.blurBoxes()
+		if id, ok := boxAt(hits); ok {
+			taskArn, _ := hits.Key(tasktable.RowZone)
+			m.section = taskSetsSection
+			m.Focused = false
+			return m.openBoxTasks(id, taskArn)
+		}
+		if key, ok := hits.Key(sectionZone); ok {
+			n, _ := strconv.Atoi(key)
+			m.section = section(n)
+			m.Focused = false
+			return m.openSection()
+		}
+	}
+	return nil
+}
+
+// boxAt returns the ID of the task set or deployment box among hits.
+func boxAt(hits zone.Zones) (string, bool) {
+	if id, ok := hits.Key(taskset.BoxZone); ok {
+		return id, true
+	}
+	return hits.Key(deployment.BoxZone)
+}
+
+// scrollBox scrolls the task table of the box among hits by step tasks and
+// reports whether it had any to scroll.
+func (m *Model) scrollBox(hits zone.Zones, step int) bool {
+	id, ok := boxAt(hits)
+	switch {
+	case !ok:
+		return false
+	case m.taskSetView != nil:
+		return m.taskSetView.Scroll(id, step)
+	case m.deploymentsView != nil:
+		return m.deploymentsView.Scroll(id, step)
+	}
+	return false
+}
+
+// openInstancesView shows the cluster's container instances, with the tasks
+// of focusArn opened if it is set.
+func (m *Model) openInstancesView(focusArn string) tea.Cmd {
+	view := instances.New(m.cluster, m.fetchers.Instances, m.fetchers.InstanceTasks, focusArn, m.width-4, m.height-4)
+	m.instancesView = &view
+	m.state = instancesOnly
+	m.Focused = false
+	return view.Init()
+}
+
+func (m *Model) openTaskDefView(view *taskdef.Model) {
+	m.taskDefView = view
+	m.state = taskDefOnly
+	m.Focused = false
+}
+
+// taskDefCandidates lists the task definitions used by the service's task
+// sets or deployments, oldest first, for the diff view.
+func (m Model) taskDefCandidates() []taskdef.Candidate {
+	type candidate struct {
+		taskdef.Candidate
+		createdAt time.Time
+	}
 	serviceStatus := *m.ecsStatus.Ecs
-	taskString := foreground.Render(fmt.Sprintf("%s %d", subtle.Render("running"), *serviceStatus.RunningCount)) + "\n" + subtle.Render(fmt.Sprintf("desired: %d", *serviceStatus.DesiredCount))
-	taskString = taskString + "\n" + subtle.Render(fmt.Sprintf("min: %d, max: %d", m.ecsStatus.Asg.Min, m.ecsStatus.Asg.Max))
-	return m.renderSmallSection("task", taskString)
+	candidates := []candidate{}
+	for _, ts := range serviceStatus.TaskSets {
+		if ts.TaskDefinition != nil {
+			candidates = append(candidates, candidate{taskdef.Candidate{Label: fmt.Sprintf("%s %s", *ts.Status, *ts.Id), Arn: *ts.TaskDefinition}, aws.TimeValue(ts.CreatedAt)})
+		}
+	}
+	for _, d := range serviceStatus.Deployments {
+		if d.TaskDefinition != nil {
+			candidates = append(candidates, candidate{taskdef.Candidate{Label: fmt.Sprintf("%s %s", *d.Status, *d.Id), Arn: *d.TaskDefinition}, aws.TimeValue(d.CreatedAt)})
+		}
+	}
+	slices.SortStableFunc(candidates, func(i, j candidate) int {
+		return i.createdAt.Compare(j.createdAt)
+	})
+
+	result := []taskdef.Candidate{}
+	if len(candidates) < 2 && serviceStatus.TaskDefinition != nil {
+		result = append(result, taskdef.Candidate{Label: "service", Arn: *serviceStatus.TaskDefinition})
+	}
+	for _, c := range candidates {
+		result = append(result, c.Candidate)
+	}
+	return result
 }
 
-func (m Model) deploymentView() string {
+// canRollback reports whether the service's task definition can be changed
+// with UpdateService, which is not the case for CODE_DEPLOY and EXTERNAL
+// controllers.
+func (m Model) canRollback() bool {
+	service := m.ecsStatus.Ecs
+	if service.TaskDefinition == nil {
+		return false
+	}
+	return service.DeploymentController == nil || aws.StringValue(service.DeploymentController.Type) == ecs.DeploymentControllerTypeEcs
+}
+
+// watchView summarizes the watched deployment for the footer.
+func (m Model) watchView() string {
+	target := utils.GetLastItemAfterSplit(m.watch.taskDefinition, "/")
+	d := m.watch.deployment
+	if d == nil {
+		return theme.Current.Warning.Render("watching deployment of " + target + ", waiting for it to start")
+	}
+	status := fmt.Sprintf("deployment of %s %s: %d/%d running, %d pending", target, aws.StringValue(d.RolloutState),
+		aws.Int64Value(d.RunningCount), aws.Int64Value(d.DesiredCount), aws.Int64Value(d.PendingCount))
+	if aws.Int64Value(d.FailedTasks) > 0 {
+		status = status + fmt.Sprintf(", %d failed tasks", aws.Int64Value(d.FailedTasks))
+	}
+	switch aws.StringValue(d.RolloutState) {
+	case ecs.DeploymentRolloutStateCompleted:
+		return theme.Current.OK.Render("✓ " + status)
+	case ecs.DeploymentRolloutStateFailed:
+		return theme.Current.Failed.Render("✗ " + status + " " + aws.StringValue(d.RolloutStateReason))
+	}
+	return theme.Current.Warning.Render("watching " + status)
+}
+
+// connectionsAndTasks returns the load balancer connections, tasks and the
+// EC2 instance IDs of their container instances last fetched by the task set
+// or deployment section.
+func (m Model) connectionsAndTasks() ([]types.ConnectionConfig, []*ecs.Task, map[string]string) {
+	if m.taskSetView != nil {
+		return m.taskSetView.Connections(), m.taskSetView.AllTasks(), m.taskSetView.InstanceIDs()
+	}
+	if m.deploymentsView != nil {
+		return m.deploymentsView.Connections(), m.deploymentsView.AllTasks(), m.deploymentsView.InstanceIDs()
+	}
+	return nil, nil, nil
+}
+
+// imageGroups lists the task sets or deployments with their last fetched
+// tasks, whose resolved image digests are shown in the images view.
+func (m Model) imageGroups() []images.Group {
+	serviceStatus := *m.ecsStatus.Ecs
+	groups := []images.Group{}
+	for _, ts := range serviceStatus.TaskSets {
+		group := images.Group{Label: fmt.Sprintf("%s %s", *ts.Status, *ts.Id), TaskDefinitionArn: aws.StringValue(ts.TaskDefinition)}
+		if m.taskSetView != nil {
+			group.Tasks = m.taskSetView.Tasks(*ts.Id)
+		}
+		groups = append(groups, group)
+	}
+	for _, d := range serviceStatus.Deployments {
+		group := images.Group{Label: fmt.Sprintf("%s %s", *d.Status, *d.Id), TaskDefinitionArn: aws.StringValue(d.TaskDefinition)}
+		if m.deploymentsView != nil {
+			group.Tasks = m.deploymentsView.Tasks(*d.Id)
+		}
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 && serviceStatus.TaskDefinition != nil {
+		groups = append(groups, images.Group{Label: "service", TaskDefinitionArn: *serviceStatus.TaskDefinition})
+	}
+	return groups
+}
+
+func (m Model) taskView(width int) string {
+	serviceStatus := *m.ecsStatus.Ecs
+	taskString := theme.Current.Text.Render(fmt.Sprintf("%s %d", theme.Current.Subtle.Render("running"), *serviceStatus.RunningCount)) + "\n" + theme.Current.Subtle.Render(fmt.Sprintf("desired: %d", *serviceStatus.DesiredCount))
+	taskString = taskString + "\n" + theme.Current.Subtle.Render(fmt.Sprintf("min: %d, max: %d", m.ecsStatus.Asg.Min, m.ecsStatus.Asg.Max))
+	return m.renderSmallSection(taskSection, "task", taskString, width)
+}
+
+func (m Model) deploymentView(width int) string {
 	serviceStatus := *m.ecsStatus.Ecs
 	deploymentString := ""
 	if len(serviceStatus.CapacityProviderStrategy) > 0 {
-		deploymentString = *serviceStatus.CapacityProviderStrategy[0].CapacityProvider + "\n"
+		providers := []string{}
+		for _, item := range serviceStatus.CapacityProviderStrategy {
+			providers = append(providers, fmt.Sprintf("%s b:%d w:%d", *item.CapacityProvider, aws.Int64Value(item.Base), aws.Int64Value(item.Weight)))
+		}
+		deploymentString = strings.Join(providers, ", ") + "\n"
 	}
 	deploymentString = deploymentString + fmt.Sprintf("%s: %s\n%s: %s",
-		subtle.Render("controller"),
+		theme.Current.Subtle.Render("controller"),
 		*serviceStatus.DeploymentController.Type,
-		subtle.Render("status"),
+		theme.Current.Subtle.Render("status"),
 		*serviceStatus.Status,
 	)
 	if m.ecsStatus.Ecs.Deployments != nil && len(m.ecsStatus.Ecs.Deployments) > 0 {
 		deploymentString = deploymentString + "\n" + fmt.Sprintf("%s: %d%%\n%s: %d%%",
-			subtle.Render("maximum-percent"),
+			theme.Current.Subtle.Render("maximum-percent"),
 			*serviceStatus.DeploymentConfiguration.MaximumPercent,
-			subtle.Render("minimum-healthy-percent"),
+			theme.Current.Subtle.Render("minimum-healthy-percent"),
 			*serviceStatus.DeploymentConfiguration.MinimumHealthyPercent,
 		)
 
 	}
-	return m.renderSmallSection("deployment", deploymentString)
+	return m.renderSmallSection(deploymentSection, "deployment", deploymentString, width)
 }
 
-func (m Model) taskdefView() *string {
+func (m Model) taskdefView(width int) *string {
 	serviceStatus := *m.ecsStatus.Ecs
 	if serviceStatus.TaskDefinition == nil {
 		return nil
@@ -307,7 +1065,7 @@ func (m Model) taskdefView() *string {
 	taskDef := utils.GetLastItemAfterSplit(*serviceStatus.TaskDefinition, "/")
 
 	content := fmt.Sprintf("%s\n %s", taskDef, utils.JoinImageNames(m.ecsStatus.Images))
-	view := m.renderSmallSection("taskDef", content)
+	view := m.renderSmallSection(taskDefSection, "taskDef", content, width)
 	return &view
 }
 
@@ -319,7 +1077,7 @@ func (m Model) tasksetsView() string {
 		tsSection = m.deploymentsView.View()
 	}
 
-	return m.renderLargeSection("tasksets", tsSection)
+	return m.renderLargeSection(taskSetsSection, "tasksets", tsSection)
 }
 
 func (m Model) eventsView() string {
@@ -334,7 +1092,7 @@ func (m Model) eventsView() string {
 	if events == "" {
 		return ""
 	}
-	return m.renderLargeSection("events", events)
+	return m.renderLargeSection(eventsSection, "events", layout.WrapText(events, m.largeSectionWidth()))
 }
 
 func (m *Model) TestUpdate(status *types.ServiceStatus) {
@@ -343,79 +1101,341 @@ func (m *Model) TestUpdate(status *types.ServiceStatus) {
 	m.initializeSections()
 }
 
-func (m Model) renderSmallSection(title, content string) string {
-	return smallSectionStyle.Render(styles.Title.AlignHorizontal(lipgloss.Center).Render(title) + "\n\n" + content + "\n")
+// smallSectionWidth is the width of the task, deployment and taskDef boxes,
+// an equal share of the screen side by side or the large sections' stacked.
+func (m Model) smallSectionWidth(n int) int {
+	if layout.Stacked(m.width) {
+		return m.largeSectionWidth()
+	}
+	return layout.Share(m.width-4, n, 3, minSmallSectionWidth, maxSmallSectionWidth)
 }
-func (m Model) renderLargeSection(title, content string) string {
-	return largeSectionStyle.Copy().Width(m.width - 19).Render(lipgloss.JoinVertical(lipgloss.Left, styles.Title.AlignHorizontal(lipgloss.Center).Render(title), content))
+
+// largeSectionWidth is the width of the task sets and events boxes, the
+// screen's but for their border and margins.
+func (m Model) largeSectionWidth() int {
+	return m.width - 6
 }
 
-func (m Model) footerView() string {
+// sectionStyle borders style in the theme's color, or as focused for the
+// focused section.
+func (m Model) sectionStyle(s section, style lipgloss.Style) lipgloss.Style {
+	if m.section == s {
+		return theme.Current.Focused(style)
+	}
+	return theme.Current.Bordered(style)
+}
+
+func (m Model) renderSmallSection(s section, title, content string, width int) string {
+	style := m.sectionStyle(s, smallSectionStyle).Width(width)
+	if layout.Stacked(m.width) { // no room to spare for padding
+		style = style.Height(0)
+	}
+	return zone.Mark(s.zone(), style.Render(theme.Current.Title.Copy().AlignHorizontal(lipgloss.Center).Render(title)+"\n\n"+content+"\n"))
+}
+func (m Model) renderLargeSection(s section, title, content string) string {
+	return zone.Mark(s.zone(), m.sectionStyle(s, largeSectionStyle).Width(m.largeSectionWidth()).Render(lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Copy().AlignHorizontal(lipgloss.Center).Render(title), content)))
+}
+
+// Help lists the keys of the current screen, the overview's or the open
+// view's.
+func (m Model) Help() []key.Binding {
+	switch m.state {
+	case errorState:
+		return m.errorView.Help()
+	case eventsOnly:
+		return m.eventsViewport.Help()
+	case taskDefOnly:
+		return m.taskDefView.Help()
+	case revisionsOnly:
+		return m.revisionsView.Help()
+	case imagesOnly:
+		return m.imagesView.Help()
+	case scalingOnly:
+		return m.scalingView.Help()
+	case placementOnly:
+		return m.placementView.Help()
+	case tasksOnly:
+		return m.tasksView.Help()
+	case instancesOnly:
+		return m.instancesView.Help()
+	case networkOnly:
+		return m.networkView.Help()
+	case targetsOnly:
+		return m.targetsView.Help()
+	case actionsOnly:
+		return m.actionsView.Help()
+	case rollbackOnly:
+		return m.rollbackView.Help()
+	}
+	boxes := keys.Map.TaskTable.Boxes()
+	if m.deploymentsView != nil {
+		boxes = keys.Describe(boxes, "focus deployment")
+	}
+	unfocus := keys.Describe(keys.Map.Global.Back, "unfocus")
+	switch {
+	case m.taskSetView != nil && m.taskSetView.Focused():
+		return append(m.taskSetView.Help(), boxes, m.openHelp(), unfocus)
+	case m.deploymentsView != nil && m.deploymentsView.Focused():
+		return append(m.deploymentsView.Help(), boxes, m.openHelp(), unfocus)
+	}
+	help := append(m.overviewHelp(), keys.Map.Sections.Cycle())
+	if m.section != noSection {
+		help = append(help, m.openHelp())
+	}
+	if m.taskSetView != nil || m.deploymentsView != nil {
+		help = append(help, boxes)
+	}
+	help = append(help, keys.Map.Common.Scroll())
+	if m.section != noSection {
+		return append(help, unfocus)
+	}
+	return append(help, keys.Map.Global.Back)
+}
+
+// openHelp describes Sections.Open with the screen of the focused section.
+func (m Model) openHelp() key.Binding {
+	return keys.Describe(keys.Map.Sections.Open, "open "+m.section.screen().Help().Desc)
+}
+
+// overviewHelp lists the keys opening the screens of the service.
+func (m Model) overviewHelp() []key.Binding {
 	refreshStatus := "enabled"
 	if !m.autoRefresh {
 		refreshStatus = "disabled"
 	}
-	help := map[string]string{
-		"ctrl+t": "auto refresh " + refreshStatus,
-		"ctrl+r": "manual refresh",
-		"ctrl+e": "events",
-		"esc":    "back",
+	service := keys.Map.Service
+	help := []key.Binding{
+		keys.Describe(service.AutoRefresh, "auto refresh "+refreshStatus),
+		service.Refresh,
+		service.Events,
+		service.TaskDefinition,
+	}
+	if taskdef.Comparable(m.taskDefCandidates()) {
+		help = append(help, service.Diff)
 	}
+	help = append(help,
+		service.Revisions,
+		service.Images,
+		service.Scaling,
+		service.Placement,
+		service.Tasks,
+		service.Instances,
+		service.Network,
+		service.Targets,
+	)
+	if m.writers != nil {
+		help = append(help, service.Actions)
+		if m.canRollback() {
+			help = append(help, service.Rollback)
+		}
+	}
+	return help
+}
+
+// InputFocused reports whether keys go to a text input, so they should not be
+// taken as shortcuts.
+func (m Model) InputFocused() bool {
+	switch m.state {
+	case eventsOnly:
+		return !m.eventsViewport.Focused()
+	case actionsOnly:
+		return m.actionsView.Entering()
+	}
+	return false
+}
+
+// ctrlShiftWorks reports whether every binding also answers to ctrl+shift,
+// as the defaults do, for terminals that report it. Overrides may drop it.
+func ctrlShiftWorks(bindings []key.Binding) bool {
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		if !strings.HasPrefix(b.Help().Key, "ctrl+") || !slices.Contains(b.Keys(), "ctrl+shift+"+strings.TrimPrefix(b.Help().Key, "ctrl+")) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Model) footerView() string {
+	type entry struct {
+		field   string
+		binding key.Binding
+	}
+	entries := []entry{}
+	for _, b := range m.Help() {
+		for _, field := range keys.Fields(theme.Current.HelpKey, theme.Current.HelpDesc, b) {
+			entries = append(entries, entry{field, b})
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.field, b.field) })
 	fields := []string{}
-	for k, v := range help {
-		fields = append(fields, fmt.Sprintf("%s %s", helpStyleKey.Render(k), helpStyleVal.Render(v)))
+	for _, e := range entries { // clicking an entry presses its key
+		if keys.Clickable(e.binding) {
+			e.field = zone.Mark(zone.ID(keyZone, e.binding.Help().Key), e.field)
+		}
+		fields = append(fields, e.field)
+	}
+	note := ""
+	if ctrlShiftWorks(m.overviewHelp()) {
+		note = theme.Current.HelpDesc.Render(" | ctrl+shift+key works!")
 	}
-	slices.Sort(fields)
 
-	lastUpdate := fmt.Sprintf("%s: %s", helpStyleVal.Render("last update"), helpStyleKey.Render(m.lastUpdateTime.Format("15:04:05.000")))
+	lastUpdate := fmt.Sprintf("%s: %s", theme.Current.HelpDesc.Render("last update"), theme.Current.HelpKey.Render(m.lastUpdateTime.Format("15:04:05.000")))
 
-	style := helpStyle.Copy().Width(m.width - 30)
+	style := helpStyle.Copy().Inherit(theme.Current.HelpDesc).Width(m.width - 4)
 	if m.showFooterSpinner {
 		lastUpdate = m.footerSpinner.View() + " " + lastUpdate
 	}
+	rows := []string{style.Render(strings.Join(fields, " • ") + note), style.Render(lastUpdate)}
+	if m.watch != nil {
+		rows = append(rows, style.Render(m.watchView()))
+	}
+	if m.err != nil {
+		rows = append(rows, style.Render(theme.Current.Warning.Render(utils.StaleBannerText(m.err, m.lastUpdateTime, keys.Map.Service.Refresh.Help().Key, m.width-4))))
+	}
 
-	return (lipgloss.JoinVertical(lipgloss.Right, style.Render(strings.Join(fields, " • ")+helpStyleVal.Render(" | ctrl+shift+key works!")), style.Render(lastUpdate)))
+	return lipgloss.JoinVertical(lipgloss.Right, rows...)
 }
+
+// sectionsView lays the sections out for the screen's width, the small ones
+// side by side while they fit, stacked under the layout breakpoint.
 func (m Model) sectionsView() string {
-	firstRow := lipgloss.JoinHorizontal(lipgloss.Center, m.taskView(), m.deploymentView())
-	taskdef := m.taskdefView()
-	if taskdef != nil {
-		firstRow = lipgloss.JoinHorizontal(lipgloss.Center, firstRow, *taskdef)
-	}
-	rows := []string{}
-	rows = append(rows, firstRow)
-	rows = append(rows, m.tasksetsView())
+	rows := []string{layout.Flow(m.width, lipgloss.Center, m.smallSectionsView()...), m.tasksetsView()}
 	events := m.eventsView()
 	if events != "" {
 		rows = append(rows, events)
 	}
-	rows = append(rows, m.footerView())
-	view := lipgloss.JoinVertical(lipgloss.Center, rows...)
+	return layout.Center(m.width, lipgloss.JoinVertical(lipgloss.Center, rows...))
+}
 
-	return lipgloss.NewStyle().
-		Width(m.width).Height(m.height - 2).
-		AlignHorizontal(lipgloss.Center).AlignVertical(lipgloss.Top).Render(view)
+// smallSectionsView renders the task, deployment and taskDef boxes, in the
+// order of sections.
+func (m Model) smallSectionsView() []string {
+	n := 2
+	if m.ecsStatus.Ecs.TaskDefinition != nil {
+		n = 3
+	}
+	width := m.smallSectionWidth(n)
+	small := []string{m.taskView(width), m.deploymentView(width)}
+	if taskdef := m.taskdefView(width); taskdef != nil {
+		small = append(small, *taskdef)
+	}
+	return small
 }
 
-func (m Model) View() string {
-	serviceName := foreground.Copy().
-		Bold(true).
+// sectionLines returns the first line of section s within the sections and
+// its height.
+func (m Model) sectionLines(s section) (top, height int) {
+	i := 0
+	for _, row := range layout.Wrap(m.width, m.smallSectionsView()...) {
+		height = lipgloss.Height(lipgloss.JoinHorizontal(lipgloss.Center, row...))
+		for range row {
+			if m.sections()[i] == s {
+				return top, height
+			}
+			i++
+		}
+		top += height
+	}
+	height = lipgloss.Height(m.tasksetsView())
+	if s == taskSetsSection {
+		return top, height
+	}
+	return top + height, lipgloss.Height(m.eventsView())
+}
+
+// scrollToSection scrolls the overview as little as it takes to show the
+// focused section, its top if it is taller than the screen.
+func (m *Model) scrollToSection() {
+	m.syncOverview()
+	top, height := m.sectionLines(m.section)
+	switch {
+	case top < m.overview.YOffset || height > m.overview.Height:
+		m.overview.SetYOffset(top)
+	case top+height > m.overview.YOffset+m.overview.Height:
+		m.overview.SetYOffset(top + height - m.overview.Height)
+	}
+}
+
+// overviewHeight is the height left to the sections between the service
+// title and the footer.
+func (m Model) overviewHeight() int {
+	return max(m.height-lipgloss.Height(m.titleView())-lipgloss.Height(m.footerView()), 1)
+}
+
+// syncOverview fits the overview to the screen and fills it with the
+// sections as they are now, which it returns. The scroll position is kept
+// while the sections are long enough.
+func (m *Model) syncOverview() string {
+	sections := m.sectionsView()
+	m.overview.Width = m.width
+	m.overview.Height = m.overviewHeight()
+	m.overview.SetContent(sections)
+	return sections
+}
+
+// overviewView shows the sections, scrolled if they do not fit the screen,
+// above the footer.
+func (m Model) overviewView() string {
+	sections := m.syncOverview()
+	if lipgloss.Height(sections) > m.overview.Height {
+		sections = m.overview.View()
+	}
+	return lipgloss.NewStyle().Height(m.height - lipgloss.Height(m.titleView())).
+		Render(lipgloss.JoinVertical(lipgloss.Center, sections, m.footerView()))
+}
+
+// titleView heads the screen with the service's ARN, or its cluster and
+// name when the ARN is wider than the screen.
+func (m Model) titleView() string {
+	title := m.serviceArn
+	if lipgloss.Width(title) > m.width-4 {
+		title = m.cluster + "/" + m.service
+	}
+	return theme.Current.ServiceTitle.Copy().
 		Margin(1, 2, 0, 2).
-		Background(lipgloss.Color("#7D56F4")).
 		AlignHorizontal(lipgloss.Center).
-		Width(m.width).
-		Render(m.serviceArn)
+		Width(m.width - 4).
+		Render(title)
+}
+
+func (m Model) View() string {
+	serviceName := m.titleView()
 
 	view := ""
 	switch m.state {
 	case initial:
 		view = view + m.spinner.View()
 	case loaded:
-		view = view + m.sectionsView()
+		view = view + m.overviewView()
 	case errorState:
-		view = view + m.err.Error()
+		view = view + m.errorView.View()
 	case eventsOnly:
 		view = view + m.eventsViewport.View()
+	case taskDefOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.taskDefView.View())
+	case revisionsOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.revisionsView.View())
+	case imagesOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.imagesView.View())
+	case scalingOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.scalingView.View())
+	case placementOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.placementView.View())
+	case tasksOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.tasksView.View())
+	case instancesOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.instancesView.View())
+	case networkOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.networkView.View())
+	case targetsOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.targetsView.View())
+	case actionsOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.actionsView.View())
+	case rollbackOnly:
+		view = view + lipgloss.NewStyle().Margin(1, 2).Render(m.rollbackView.View())
 	default:
 		view = view + m.serviceArn
 	}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/internal/fixtures"
+	"github.com/mtyurt/ecstui/internal/golden"
+	"github.com/mtyurt/ecstui/tui/deployment"
+	"github.com/mtyurt/ecstui/tui/errorview"
+	"github.com/mtyurt/ecstui/tui/taskset"
+	"github.com/mtyurt/ecstui/tui/zone"
+)
+
+func TestView(t *testing.T) {
+	for _, width := range golden.Widths {
+		for _, scenario := range fixtures.Scenarios() {
+			t.Run(fmt.Sprintf("%s/%d", scenario.Name, width), func(t *testing.T) {
+				svc := scenario.Service.Ecs
+				m := New("app-cluster-staging", *svc.ServiceName, *svc.ServiceArn, Fetchers{}, nil, errorview.Recovery{})
+				m.SetSize(width, 100) // tall enough for every section, scrolling is covered by the driver tests
+				m.TestUpdate(scenario.Service)
+				if scenario.TaskSets != nil {
+					m, _ = m.Update(taskset.StatusMsg(scenario.TaskSets))
+				}
+				if scenario.Deployments != nil {
+					m, _ = m.Update(deployment.StatusMsg(scenario.Deployments))
+				}
+				golden.Assert(t, fmt.Sprintf("%s_%d", scenario.Name, width), m.View())
+			})
+		}
+	}
+}
+
+func TestViewFocusedSection(t *testing.T) {
+	scenario := fixtures.RollingDeployment()
+	svc := scenario.Service.Ecs
+	m := New("app-cluster-staging", *svc.ServiceName, *svc.ServiceArn, Fetchers{}, nil, errorview.Recovery{})
+	m.SetSize(120, 100)
+	m.TestUpdate(scenario.Service)
+	m, _ = m.Update(deployment.StatusMsg(scenario.Deployments))
+	// the deployment configuration, shift+tab wraps around past it
+	for _, k := range []tea.KeyType{tea.KeyTab, tea.KeyTab, tea.KeyShiftTab, tea.KeyTab} {
+		m, _ = m.Update(tea.KeyMsg{Type: k})
+	}
+	golden.Assert(t, "focused_section_120", m.View())
+}
+
+func TestWheelScrollsTaskTable(t *testing.T) {
+	scenario := fixtures.HugeTaskCount()
+	svc := scenario.Service.Ecs
+	m := New("app-cluster-staging", *svc.ServiceName, *svc.ServiceArn, Fetchers{}, nil, errorview.Recovery{})
+	m.SetSize(120, 100)
+	m.TestUpdate(scenario.Service)
+	m, _ = m.Update(deployment.StatusMsg(scenario.Deployments))
+	// expand the table, then leave it to the mouse
+	for _, k := range []string{"]", "c"} {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	view := zone.Clean(m.View())
+	lines := strings.Split(view, "\n")
+	y := slices.IndexFunc(lines, func(line string) bool { return strings.Contains(line, "17156075") })
+	x := lipgloss.Width(lines[y][:strings.Index(lines[y], "17156075")])
+	for i := 0; i < 2; i++ {
+		m, _ = m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	}
+	if view := zone.Clean(m.View()); !strings.Contains(view, "3-10 of 60") {
+		t.Errorf("the wheel did not scroll the tasks:\n%s", view)
+	}
+}
+
+func TestDiffNeedsTwoTaskDefinitions(t *testing.T) {
+	// an EXTERNAL controller service without task sets runs no task
+	// definition but the service's own
+	scenario := fixtures.Empty()
+	svc := scenario.Service.Ecs
+	m := New("app-cluster-staging", *svc.ServiceName, *svc.ServiceArn, Fetchers{}, nil, errorview.Recovery{})
+	m.SetSize(120, 100)
+	m.TestUpdate(scenario.Service)
+	before := m.View()
+	if strings.Contains(before, "ctrl+d") {
+		t.Errorf("the footer offers a diff with a single task definition:\n%s", before)
+	}
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if cmd != nil || m.View() != before {
+		t.Errorf("ctrl+d opened a diff of a single task definition:\n%s", m.View())
+	}
+}
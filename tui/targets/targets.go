@@ -0,0 +1,265 @@
+package targets
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+const targetRowFormat = "  %-16s %-6s %-34s %-12s %-10s %s"
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+)
+
+// Group is a target group the service's tasks are registered into, labelled
+// with the load balancers routing to it.
+type Group struct {
+	Label string
+	Arn   string
+}
+
+// Groups collapses connections into one group per target group.
+func Groups(conns []types.ConnectionConfig) []Group {
+	lbs := make(map[string][]string)
+	arns := []string{}
+	for _, conn := range conns {
+		if conn.TGArn == "" {
+			continue
+		}
+		if _, ok := lbs[conn.TGArn]; !ok {
+			arns = append(arns, conn.TGArn)
+			lbs[conn.TGArn] = []string{}
+		}
+		if conn.LBName != "" {
+			lbs[conn.TGArn] = append(lbs[conn.TGArn], conn.LBName)
+		}
+	}
+	slices.Sort(arns)
+	groups := []Group{}
+	for _, arn := range arns {
+		label := "no load balancer"
+		if names := utils.UniqueStrings(lbs[arn]); len(names) > 0 {
+			slices.Sort(names)
+			label = strings.Join(names, ", ")
+		}
+		groups = append(groups, Group{Label: label, Arn: arn})
+	}
+	return groups
+}
+
+type Model struct {
+	fetcher types.TargetGroupDetailsFetcher
+	groups  []Group
+	tasks   []*ecs.Task
+	// instanceIDs maps container instance ARNs to EC2 instance IDs, to match
+	// bridge and host mode targets.
+	instanceIDs map[string]string
+	details     []*types.TargetGroupDetails
+	errs        []error
+	viewport    viewport.Model
+	state       sessionState
+	spinner     spinnertui.Model
+	width       int
+	height      int
+}
+
+type loadedMsg struct {
+	details []*types.TargetGroupDetails
+	errs    []error
+}
+
+// New shows every target of groups. tasks are the service's tasks, used to
+// tell which task a target is.
+func New(fetcher types.TargetGroupDetailsFetcher, groups []Group, tasks []*ecs.Task, instanceIDs map[string]string, width, height int) Model {
+	m := Model{
+		fetcher:     fetcher,
+		groups:      groups,
+		tasks:       tasks,
+		instanceIDs: instanceIDs,
+		spinner:     spinnertui.New("Loading target health"),
+		viewport:    keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.viewport.SetContent(m.renderGroups())
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching target health")
+	defer logger.Println("finished fetching target health")
+	msg := loadedMsg{
+		details: make([]*types.TargetGroupDetails, len(m.groups)),
+		errs:    make([]error, len(m.groups)),
+	}
+	for i, group := range m.groups {
+		msg.details[i], msg.errs[i] = m.fetcher(group.Arn)
+	}
+	return msg
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.details = msg.details
+		m.errs = msg.errs
+		m.state = loaded
+		m.viewport.SetContent(m.renderGroups())
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, keys.Map.Common.Reload) && m.state == loaded {
+			m.state = initial
+			return m, m.Init()
+		}
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) renderGroups() string {
+	if len(m.groups) == 0 {
+		return theme.Current.Subtle.Render("the service is not registered to any target group")
+	}
+	matcher := utils.NewTargetMatcher(m.tasks, m.instanceIDs)
+	sections := []string{}
+	for i, group := range m.groups {
+		lines := []string{theme.Current.Call.Render(utils.GetLastItemAfterSplit(group.Arn, "targetgroup/")) + " " + theme.Current.Subtle.Render(group.Label)}
+		if m.errs[i] != nil {
+			lines = append(lines, theme.Current.Warning.Render("  ⚠ "+strings.SplitN(m.errs[i].Error(), "\n", 2)[0]))
+		}
+		if m.details[i] != nil {
+			lines = append(lines, renderHealthCheck(m.details[i].TargetGroup)...)
+			lines = append(lines, renderTargets(m.details[i].Health, matcher)...)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func renderHealthCheck(tg *elbv2.TargetGroup) []string {
+	check := fmt.Sprintf("  health check %s", aws.StringValue(tg.HealthCheckProtocol))
+	if tg.HealthCheckPath != nil {
+		check = check + " " + *tg.HealthCheckPath
+	}
+	check = check + " port " + aws.StringValue(tg.HealthCheckPort)
+	if tg.Matcher != nil {
+		if tg.Matcher.HttpCode != nil {
+			check = check + ", expects " + *tg.Matcher.HttpCode
+		} else if tg.Matcher.GrpcCode != nil {
+			check = check + ", expects grpc " + *tg.Matcher.GrpcCode
+		}
+	}
+	return []string{
+		check,
+		theme.Current.Subtle.Render(fmt.Sprintf("  every %ds, timeout %ds, healthy after %d, unhealthy after %d",
+			aws.Int64Value(tg.HealthCheckIntervalSeconds), aws.Int64Value(tg.HealthCheckTimeoutSeconds),
+			aws.Int64Value(tg.HealthyThresholdCount), aws.Int64Value(tg.UnhealthyThresholdCount))),
+	}
+}
+
+func renderTargets(health []*elbv2.TargetHealthDescription, matcher utils.TargetMatcher) []string {
+	if len(health) == 0 {
+		return []string{theme.Current.Warning.Render("  ⚠ no registered targets")}
+	}
+	// unhealthy targets first, they are why this view is opened
+	health = slices.Clone(health)
+	slices.SortStableFunc(health, func(i, j *elbv2.TargetHealthDescription) int {
+		iHealthy := aws.StringValue(i.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy
+		jHealthy := aws.StringValue(j.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy
+		if iHealthy != jHealthy {
+			if iHealthy {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(aws.StringValue(i.Target.Id), aws.StringValue(j.Target.Id))
+	})
+
+	lines := []string{"", theme.Current.Header.Render(fmt.Sprintf(targetRowFormat, "target", "port", "task", "zone", "state", "reason"))}
+	for _, h := range health {
+		taskID := "-"
+		if task := matcher.Task(h.Target); task != nil {
+			taskID = utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/")
+		}
+		state := aws.StringValue(h.TargetHealth.State)
+		row := fmt.Sprintf(targetRowFormat,
+			aws.StringValue(h.Target.Id),
+			fmt.Sprintf("%d", aws.Int64Value(h.Target.Port)),
+			taskID,
+			aws.StringValue(h.Target.AvailabilityZone),
+			state,
+			aws.StringValue(h.TargetHealth.Reason),
+		)
+		switch state {
+		case elbv2.TargetHealthStateEnumHealthy:
+			row = theme.Current.OK.Render(row)
+		case elbv2.TargetHealthStateEnumUnhealthy:
+			row = theme.Current.Failed.Render(row)
+		default:
+			row = theme.Current.Warning.Render(row)
+		}
+		lines = append(lines, row)
+		if h.TargetHealth.Description != nil {
+			lines = append(lines, theme.Current.Subtle.Render("    "+*h.TargetHealth.Description))
+		}
+	}
+	return lines
+}
+
+// Help lists the keys of the screen.
+func (m Model) Help() []key.Binding {
+	return []key.Binding{keys.Map.Common.Scroll(), keys.Map.Common.Reload, keys.Map.Global.Back}
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	if m.state == initial {
+		return m.spinner.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, theme.Current.Title.Render("targets"), "", m.viewport.View(), "", m.footerView())
+}
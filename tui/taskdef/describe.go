@@ -0,0 +1,212 @@
+package taskdef
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// secretNameHints mark environment variables whose values are masked even
+// though they are plain environment entries rather than secrets references.
+var secretNameHints = []string{"SECRET", "PASSWORD", "PASSWD", "TOKEN", "API_KEY", "PRIVATE_KEY", "ACCESS_KEY", "CREDENTIAL"}
+
+const mask = "******"
+
+// maskKey keys the fingerprints of masked values. It is drawn for every run,
+// so a fingerprint cannot be looked up in a table of hashed common values or
+// matched against one taken in another session. It is nil if no randomness
+// could be read, values are then masked without a fingerprint.
+var maskKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil
+	}
+	return key
+}()
+
+// maskValue hides a value but keeps a short fingerprint, so a diff still
+// shows that a masked value changed between revisions.
+func maskValue(value string) string {
+	if maskKey == nil {
+		return mask
+	}
+	mac := hmac.New(sha256.New, maskKey)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("%s (%x)", mask, mac.Sum(nil)[:3])
+}
+
+func looksSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, hint := range secretNameHints {
+		if strings.Contains(upper, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe flattens a task definition into stable, indented lines. The same
+// representation is used by the viewer and as the input of the diff.
+func Describe(td *ecs.TaskDefinition) []string {
+	lines := []string{
+		fmt.Sprintf("family: %s", aws.StringValue(td.Family)),
+		fmt.Sprintf("revision: %d", aws.Int64Value(td.Revision)),
+		fmt.Sprintf("status: %s", aws.StringValue(td.Status)),
+	}
+	if td.RegisteredAt != nil {
+		lines = append(lines, fmt.Sprintf("registered: %s", td.RegisteredAt.Format("2006-01-02 15:04:05")))
+	}
+	if td.RegisteredBy != nil {
+		lines = append(lines, fmt.Sprintf("registered by: %s", *td.RegisteredBy))
+	}
+	lines = appendField(lines, "cpu", aws.StringValue(td.Cpu))
+	lines = appendField(lines, "memory", aws.StringValue(td.Memory))
+	lines = appendField(lines, "network mode", aws.StringValue(td.NetworkMode))
+	lines = appendField(lines, "compatibilities", strings.Join(aws.StringValueSlice(td.RequiresCompatibilities), ", "))
+	if td.RuntimePlatform != nil {
+		lines = append(lines, fmt.Sprintf("platform: %s/%s", aws.StringValue(td.RuntimePlatform.OperatingSystemFamily), aws.StringValue(td.RuntimePlatform.CpuArchitecture)))
+	}
+	if td.EphemeralStorage != nil {
+		lines = append(lines, fmt.Sprintf("ephemeral storage: %d GiB", aws.Int64Value(td.EphemeralStorage.SizeInGiB)))
+	}
+	lines = appendField(lines, "task role", aws.StringValue(td.TaskRoleArn))
+	lines = appendField(lines, "execution role", aws.StringValue(td.ExecutionRoleArn))
+
+	for _, c := range td.ContainerDefinitions {
+		lines = append(lines, "", fmt.Sprintf("container %s", aws.StringValue(c.Name)))
+		lines = append(lines, describeContainer(c)...)
+	}
+
+	if len(td.Volumes) > 0 {
+		lines = append(lines, "", "volumes")
+		for _, v := range td.Volumes {
+			lines = append(lines, "  "+describeVolume(v))
+		}
+	}
+	return lines
+}
+
+// appendField adds a "name: value" line, skipping values that are not set.
+func appendField(lines []string, name, value string) []string {
+	if value == "" {
+		return lines
+	}
+	return append(lines, fmt.Sprintf("%s: %s", name, value))
+}
+
+func describeContainer(c *ecs.ContainerDefinition) []string {
+	indent := "  "
+	lines := []string{
+		indent + fmt.Sprintf("image: %s", aws.StringValue(c.Image)),
+		indent + fmt.Sprintf("essential: %t", aws.BoolValue(c.Essential)),
+		indent + fmt.Sprintf("cpu: %d, memory: %d, reservation: %d", aws.Int64Value(c.Cpu), aws.Int64Value(c.Memory), aws.Int64Value(c.MemoryReservation)),
+	}
+	if len(c.EntryPoint) > 0 {
+		lines = append(lines, indent+fmt.Sprintf("entrypoint: %s", strings.Join(aws.StringValueSlice(c.EntryPoint), " ")))
+	}
+	if len(c.Command) > 0 {
+		lines = append(lines, indent+fmt.Sprintf("command: %s", strings.Join(aws.StringValueSlice(c.Command), " ")))
+	}
+	if c.User != nil {
+		lines = append(lines, indent+fmt.Sprintf("user: %s", *c.User))
+	}
+	if c.WorkingDirectory != nil {
+		lines = append(lines, indent+fmt.Sprintf("working directory: %s", *c.WorkingDirectory))
+	}
+	for _, p := range c.PortMappings {
+		port := fmt.Sprintf("%d/%s", aws.Int64Value(p.ContainerPort), aws.StringValue(p.Protocol))
+		if p.HostPort != nil && *p.HostPort != aws.Int64Value(p.ContainerPort) {
+			port = fmt.Sprintf("%d:%s", *p.HostPort, port)
+		}
+		if p.Name != nil {
+			port = port + " (" + *p.Name + ")"
+		}
+		lines = append(lines, indent+"port: "+port)
+	}
+	for _, d := range c.DependsOn {
+		lines = append(lines, indent+fmt.Sprintf("depends on: %s %s", aws.StringValue(d.ContainerName), aws.StringValue(d.Condition)))
+	}
+	if hc := c.HealthCheck; hc != nil {
+		lines = append(lines,
+			indent+fmt.Sprintf("health check: %s", strings.Join(aws.StringValueSlice(hc.Command), " ")),
+			indent+fmt.Sprintf("  interval %ds, timeout %ds, retries %d, start period %ds",
+				aws.Int64Value(hc.Interval), aws.Int64Value(hc.Timeout), aws.Int64Value(hc.Retries), aws.Int64Value(hc.StartPeriod)),
+		)
+	}
+	if lc := c.LogConfiguration; lc != nil {
+		lines = append(lines, indent+fmt.Sprintf("log driver: %s", aws.StringValue(lc.LogDriver)))
+		for _, k := range sortedKeys(lc.Options) {
+			lines = append(lines, indent+fmt.Sprintf("  %s=%s", k, aws.StringValue(lc.Options[k])))
+		}
+	}
+	for _, mp := range c.MountPoints {
+		mode := "rw"
+		if aws.BoolValue(mp.ReadOnly) {
+			mode = "ro"
+		}
+		lines = append(lines, indent+fmt.Sprintf("mount: %s -> %s (%s)", aws.StringValue(mp.SourceVolume), aws.StringValue(mp.ContainerPath), mode))
+	}
+
+	env := make([]string, 0, len(c.Environment))
+	for _, kv := range c.Environment {
+		value := aws.StringValue(kv.Value)
+		if looksSecret(aws.StringValue(kv.Name)) {
+			value = maskValue(value)
+		}
+		env = append(env, fmt.Sprintf("%s=%s", aws.StringValue(kv.Name), value))
+	}
+	slices.Sort(env)
+	if len(env) > 0 {
+		lines = append(lines, indent+"environment")
+		for _, e := range env {
+			lines = append(lines, indent+"  "+e)
+		}
+	}
+	for _, f := range c.EnvironmentFiles {
+		lines = append(lines, indent+fmt.Sprintf("environment file: %s", aws.StringValue(f.Value)))
+	}
+
+	secrets := make([]string, 0, len(c.Secrets))
+	for _, s := range c.Secrets {
+		secrets = append(secrets, fmt.Sprintf("%s=%s from %s", aws.StringValue(s.Name), mask, aws.StringValue(s.ValueFrom)))
+	}
+	slices.Sort(secrets)
+	if len(secrets) > 0 {
+		lines = append(lines, indent+"secrets")
+		for _, s := range secrets {
+			lines = append(lines, indent+"  "+s)
+		}
+	}
+	return lines
+}
+
+func describeVolume(v *ecs.Volume) string {
+	name := aws.StringValue(v.Name)
+	switch {
+	case v.EfsVolumeConfiguration != nil:
+		efs := v.EfsVolumeConfiguration
+		return fmt.Sprintf("%s: efs %s:%s transit encryption %s", name, aws.StringValue(efs.FileSystemId), aws.StringValue(efs.RootDirectory), aws.StringValue(efs.TransitEncryption))
+	case v.DockerVolumeConfiguration != nil:
+		docker := v.DockerVolumeConfiguration
+		return fmt.Sprintf("%s: docker %s (%s)", name, aws.StringValue(docker.Driver), aws.StringValue(docker.Scope))
+	case v.Host != nil && v.Host.SourcePath != nil:
+		return fmt.Sprintf("%s: host %s", name, *v.Host.SourcePath)
+	default:
+		return fmt.Sprintf("%s: task storage", name)
+	}
+}
+
+func sortedKeys(m map[string]*string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
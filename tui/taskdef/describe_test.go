@@ -0,0 +1,90 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLooksSecret(t *testing.T) {
+	for name, want := range map[string]bool{
+		"DB_PASSWORD":           true,
+		"db_password":           true,
+		"MYSQL_PASSWD":          true,
+		"CLIENT_SECRET":         true,
+		"GITHUB_TOKEN":          true,
+		"STRIPE_API_KEY":        true,
+		"SSH_PRIVATE_KEY":       true,
+		"AWS_ACCESS_KEY_ID":     true,
+		"GOOGLE_CREDENTIALS":    true,
+		"AWS_SECRET_ACCESS_KEY": true,
+		"LOG_LEVEL":             false,
+		"PORT":                  false,
+		"API_URL":               false,
+		"KEYCLOAK_REALM":        false,
+		"":                      false,
+	} {
+		if got := looksSecret(name); got != want {
+			t.Errorf("looksSecret(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	masked := maskValue("hunter2")
+	if strings.Contains(masked, "hunter2") || !strings.HasPrefix(masked, mask) {
+		t.Errorf("maskValue = %q, want the value masked", masked)
+	}
+	if maskValue("hunter2") != masked {
+		t.Error("the same value is masked differently, a diff would show it changed")
+	}
+	if maskValue("hunter3") == masked {
+		t.Error("different values are masked alike, a diff would not show the change")
+	}
+}
+
+func TestDescribeMasksSecrets(t *testing.T) {
+	td := &ecs.TaskDefinition{
+		Family:   aws.String("web"),
+		Revision: aws.Int64(3),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name: aws.String("app"),
+			Environment: []*ecs.KeyValuePair{
+				{Name: aws.String("LOG_LEVEL"), Value: aws.String("debug")},
+				{Name: aws.String("DB_PASSWORD"), Value: aws.String("hunter2")},
+			},
+		}},
+	}
+	description := strings.Join(Describe(td), "\n")
+	if !strings.Contains(description, "LOG_LEVEL=debug") {
+		t.Errorf("plain variable not shown:\n%s", description)
+	}
+	if strings.Contains(description, "hunter2") || !strings.Contains(description, "DB_PASSWORD="+maskValue("hunter2")) {
+		t.Errorf("secret not masked:\n%s", description)
+	}
+}
+
+func TestPad(t *testing.T) {
+	for _, tc := range []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"cpu: 256", 12, "cpu: 256    "},
+		{"cpu: 256", 8, "cpu: 256"},
+		{"image: web:1", 8, "image: …"},
+		{"mount: a → b", 14, "mount: a → b  "},
+		{"", 3, "   "},
+	} {
+		got := pad(tc.s, tc.width)
+		if got != tc.want {
+			t.Errorf("pad(%q, %d) = %q, want %q", tc.s, tc.width, got, tc.want)
+		}
+		if lipgloss.Width(got) != tc.width {
+			t.Errorf("pad(%q, %d) is %d wide", tc.s, tc.width, lipgloss.Width(got))
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package taskdef
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/types"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+func separator() string {
+	return theme.Current.Subtle.Render(" │ ")
+}
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+	// nothingToCompare is a diff of fewer than two distinct task definitions.
+	nothingToCompare
+)
+
+// Candidate is a task definition that can be shown or compared, labelled
+// with what uses it, e.g. the task set or deployment running it.
+type Candidate struct {
+	Label string
+	Arn   string
+}
+
+type Model struct {
+	fetcher     types.TaskDefinitionFetcher
+	candidates  []Candidate
+	left, right int
+	diff        bool
+	changesOnly bool
+	definitions map[string]*ecs.TaskDefinition
+	viewport    viewport.Model
+	state       sessionState
+	err         error
+	spinner     spinnertui.Model
+	width       int
+	height      int
+}
+
+type loadedMsg map[string]*ecs.TaskDefinition
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+// NewViewer shows a single task definition.
+func NewViewer(fetcher types.TaskDefinitionFetcher, candidate Candidate, width, height int) Model {
+	m := Model{
+		fetcher:    fetcher,
+		candidates: []Candidate{candidate},
+		spinner:    spinnertui.New("Loading task definition"),
+		viewport:   keys.NewViewport(width, height),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+// NewDiff compares the newest candidate with the newest one before it that
+// runs another task definition, side by side, so callers order candidates
+// oldest first and the newest one is shown on the right. tab and shift+tab
+// cycle the right and left sides through the other candidates. Fewer than two
+// distinct task definitions leave nothing to compare, which the view says.
+func NewDiff(fetcher types.TaskDefinitionFetcher, candidates []Candidate, width, height int) Model {
+	m := Model{
+		fetcher:    fetcher,
+		candidates: candidates,
+		diff:       true,
+		state:      nothingToCompare,
+		spinner:    spinnertui.New("Loading task definitions"),
+		viewport:   keys.NewViewport(width, height),
+	}
+	for i := len(candidates) - 2; i >= 0; i-- {
+		if candidates[i].Arn != candidates[len(candidates)-1].Arn {
+			m.left, m.right = i, len(candidates)-1
+			m.state = initial
+			break
+		}
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+// Comparable reports whether candidates hold at least two distinct task
+// definitions for NewDiff to compare.
+func Comparable(candidates []Candidate) bool {
+	for _, c := range candidates {
+		if c.Arn != candidates[0].Arn {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-4, 1)
+	if m.state == loaded {
+		m.updateContent()
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching task definitions")
+	defer logger.Println("finished fetching task definitions")
+	definitions := make(map[string]*ecs.TaskDefinition)
+	for _, c := range m.candidates {
+		if _, ok := definitions[c.Arn]; ok {
+			continue
+		}
+		td, err := m.fetcher(c.Arn)
+		if err != nil {
+			return errMsg{err}
+		}
+		definitions[c.Arn] = td
+	}
+	return loadedMsg(definitions)
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.state == nothingToCompare {
+		return nil
+	}
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view.
+func (m Model) Focused() bool {
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.definitions = msg
+		m.state = loaded
+		m.updateContent()
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if m.state == loaded && m.diff {
+			switch {
+			case key.Matches(msg, keys.Map.TaskDef.NextRight):
+				m.right = m.nextCandidate(m.right, m.left)
+				m.updateContent()
+			case key.Matches(msg, keys.Map.TaskDef.NextLeft):
+				m.left = m.nextCandidate(m.left, m.right)
+				m.updateContent()
+			case key.Matches(msg, keys.Map.TaskDef.ChangesOnly):
+				m.changesOnly = !m.changesOnly
+				m.updateContent()
+			}
+		}
+	}
+
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.viewport, cmd = m.viewport.Update(msg)
+	}
+	return m, cmd
+}
+
+// nextCandidate returns the candidate after current, skipping the one shown
+// on the other side.
+func (m Model) nextCandidate(current, other int) int {
+	if len(m.candidates) < 3 {
+		return current
+	}
+	next := (current + 1) % len(m.candidates)
+	if next == other {
+		next = (next + 1) % len(m.candidates)
+	}
+	return next
+}
+
+func (m *Model) updateContent() {
+	if m.diff {
+		m.viewport.SetContent(m.renderDiff())
+	} else {
+		m.viewport.SetContent(m.renderDefinition(m.definitions[m.candidates[0].Arn]))
+	}
+}
+
+func styleLine(line string) string {
+	if strings.HasPrefix(line, "container ") || line == "volumes" {
+		return theme.Current.Header.Render(line)
+	}
+	return line
+}
+
+func (m Model) renderDefinition(td *ecs.TaskDefinition) string {
+	lines := Describe(td)
+	for i, line := range lines {
+		lines[i] = styleLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pad(s string, width int) string {
+	if lipgloss.Width(s) > width {
+		s = truncateTo(s, width)
+	}
+	return s + strings.Repeat(" ", max(0, width-lipgloss.Width(s)))
+}
+
+func truncateTo(s string, max int) string {
+	if len([]rune(s)) > max {
+		return string([]rune(s)[:max-1]) + "…"
+	}
+	return s
+}
+
+func (m Model) renderDiff() string {
+	left := m.definitions[m.candidates[m.left].Arn]
+	right := m.definitions[m.candidates[m.right].Arn]
+	rows := utils.SideBySide(utils.DiffLines(Describe(left), Describe(right)))
+
+	columnWidth := (m.width - lipgloss.Width(separator())) / 2
+	lines := []string{}
+	changes := 0
+	for _, row := range rows {
+		if row.Changed() {
+			changes++
+		} else if m.changesOnly {
+			continue
+		}
+		leftText, rightText := "", ""
+		if row.Left != nil {
+			leftText = pad(*row.Left, columnWidth)
+		} else {
+			leftText = pad("", columnWidth)
+		}
+		if row.Right != nil {
+			rightText = pad(*row.Right, columnWidth)
+		}
+		switch {
+		case !row.Changed():
+			leftText, rightText = styleLine(leftText), styleLine(rightText)
+		default:
+			leftText, rightText = theme.Current.Failed.Render(leftText), theme.Current.OK.Render(rightText)
+		}
+		lines = append(lines, leftText+separator()+rightText)
+	}
+	if changes == 0 {
+		lines = append([]string{theme.Current.Subtle.Render("no differences")}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) headerView() string {
+	if !m.diff {
+		c := m.candidates[0]
+		return theme.Current.Title.Render(utils.GetLastItemAfterSplit(c.Arn, "/")) + " " + theme.Current.Subtle.Render(c.Label)
+	}
+	columnWidth := (m.width - lipgloss.Width(separator())) / 2
+	side := func(c Candidate) string {
+		title := theme.Current.Title.Render(utils.GetLastItemAfterSplit(c.Arn, "/"))
+		return pad(title+" "+theme.Current.Subtle.Render(truncateTo(c.Label, max(columnWidth-lipgloss.Width(title)-1, 1))), columnWidth)
+	}
+	return side(m.candidates[m.left]) + separator() + side(m.candidates[m.right])
+}
+
+// Help lists the keys of the viewer, or of the diff.
+func (m Model) Help() []key.Binding {
+	help := []key.Binding{keys.Map.Common.Scroll()}
+	if m.diff {
+		help = append(help, keys.Map.TaskDef.NextRight, keys.Map.TaskDef.NextLeft, keys.Map.TaskDef.ChangesOnly)
+	}
+	return append(help, keys.Map.Global.Back)
+}
+
+func (m Model) footerView() string {
+	scroll := theme.Current.HelpDesc.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...) + "  " + scroll
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return m.err.Error()
+	case nothingToCompare:
+		return lipgloss.JoinVertical(lipgloss.Left,
+			theme.Current.Subtle.Render("nothing to compare, a single task definition is in use"),
+			"",
+			keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, keys.Map.Global.Back))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.headerView(), "", m.viewport.View(), "", m.footerView())
+}
@@ -0,0 +1,57 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func fetchTaskDefinition(arn string) (*ecs.TaskDefinition, error) {
+	return &ecs.TaskDefinition{TaskDefinitionArn: aws.String(arn), Family: aws.String("web")}, nil
+}
+
+func TestNewDiffNothingToCompare(t *testing.T) {
+	for name, candidates := range map[string][]Candidate{
+		"none": nil,
+		"one":  {{Label: "service", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3"}},
+		"same": {
+			{Label: "PRIMARY ecs-svc/1", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3"},
+			{Label: "ACTIVE ecs-svc/2", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if Comparable(candidates) {
+				t.Error("Comparable = true")
+			}
+			m := NewDiff(fetchTaskDefinition, candidates, 120, 40)
+			if cmd := m.Init(); cmd != nil {
+				t.Error("Init fetches task definitions though there is nothing to compare")
+			}
+			if view := m.View(); !strings.Contains(view, "nothing to compare") {
+				t.Errorf("view does not say there is nothing to compare:\n%s", view)
+			}
+		})
+	}
+}
+
+func TestNewDiffSides(t *testing.T) {
+	candidates := []Candidate{
+		{Label: "INACTIVE ecs-svc/1", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:2"},
+		{Label: "ACTIVE ecs-svc/2", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3"},
+		{Label: "PRIMARY ecs-svc/3", Arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3"},
+	}
+	if !Comparable(candidates) {
+		t.Fatal("Comparable = false")
+	}
+	m := NewDiff(fetchTaskDefinition, candidates, 120, 40)
+	// the newest on the right, against the newest other definition
+	if m.left != 0 || m.right != 2 {
+		t.Errorf("sides = %d, %d, want 0, 2", m.left, m.right)
+	}
+	m, _ = m.Update(m.fetch())
+	if view := m.View(); !strings.Contains(view, "web:2") || !strings.Contains(view, "web:3") {
+		t.Errorf("view does not compare web:2 and web:3:\n%s", view)
+	}
+}
@@ -0,0 +1,89 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// Describe flattens a task into indented lines for the detail view.
+func Describe(task *ecs.Task) []string {
+	lines := []string{
+		fmt.Sprintf("task: %s", utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/")),
+		fmt.Sprintf("status: %s (desired %s)", aws.StringValue(task.LastStatus), aws.StringValue(task.DesiredStatus)),
+	}
+	lines = appendField(lines, "health", aws.StringValue(task.HealthStatus))
+	lines = appendField(lines, "task definition", utils.GetLastItemAfterSplit(aws.StringValue(task.TaskDefinitionArn), "/"))
+	lines = appendField(lines, "started by", aws.StringValue(task.StartedBy))
+	lines = appendField(lines, "capacity", utils.TaskCapacity(task))
+	lines = appendField(lines, "availability zone", aws.StringValue(task.AvailabilityZone))
+	if task.ContainerInstanceArn != nil {
+		lines = append(lines, fmt.Sprintf("container instance: %s", utils.GetLastItemAfterSplit(*task.ContainerInstanceArn, "/")))
+	}
+	lines = appendField(lines, "cpu", aws.StringValue(task.Cpu))
+	lines = appendField(lines, "memory", aws.StringValue(task.Memory))
+	lines = appendField(lines, "private ip", utils.TaskPrivateIP(task))
+	lines = appendField(lines, "started", formatTime(task.StartedAt))
+	lines = appendField(lines, "stopping", formatTime(task.StoppingAt))
+	lines = appendField(lines, "stopped", formatTime(task.StoppedAt))
+	lines = appendField(lines, "stop code", aws.StringValue(task.StopCode))
+	lines = appendField(lines, "stopped reason", aws.StringValue(task.StoppedReason))
+
+	for _, c := range task.Containers {
+		lines = append(lines, "", fmt.Sprintf("container %s", aws.StringValue(c.Name)))
+		lines = append(lines, describeContainer(c)...)
+	}
+	return lines
+}
+
+// appendField adds a "name: value" line, skipping values that are not set.
+func appendField(lines []string, name, value string) []string {
+	if value == "" {
+		return lines
+	}
+	return append(lines, fmt.Sprintf("%s: %s", name, value))
+}
+
+func describeContainer(c *ecs.Container) []string {
+	indent := "  "
+	lines := []string{indent + fmt.Sprintf("status: %s", aws.StringValue(c.LastStatus))}
+	lines = appendIndented(lines, indent, "health", aws.StringValue(c.HealthStatus))
+	lines = appendIndented(lines, indent, "image", aws.StringValue(c.Image))
+	lines = appendIndented(lines, indent, "digest", aws.StringValue(c.ImageDigest))
+	if c.ExitCode != nil {
+		lines = append(lines, indent+fmt.Sprintf("exit code: %d", *c.ExitCode))
+	}
+	lines = appendIndented(lines, indent, "reason", aws.StringValue(c.Reason))
+	for _, b := range c.NetworkBindings {
+		lines = append(lines, indent+fmt.Sprintf("port: %s:%d -> %d/%s", aws.StringValue(b.BindIP), aws.Int64Value(b.HostPort), aws.Int64Value(b.ContainerPort), aws.StringValue(b.Protocol)))
+	}
+	return lines
+}
+
+func appendIndented(lines []string, indent, name, value string) []string {
+	if value == "" {
+		return lines
+	}
+	return append(lines, indent+fmt.Sprintf("%s: %s", name, value))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// shortStatus condenses the last and desired status, e.g. RUNNING→STOPPED
+// for a task that is being stopped.
+func shortStatus(task *ecs.Task) string {
+	last, desired := aws.StringValue(task.LastStatus), aws.StringValue(task.DesiredStatus)
+	if last == desired || desired == "" {
+		return last
+	}
+	return strings.Join([]string{last, desired}, "→")
+}
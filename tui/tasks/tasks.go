@@ -0,0 +1,295 @@
+package tasks
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/logger"
+	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+type sessionState int
+
+const (
+	initial sessionState = iota
+	loaded
+	failed
+)
+
+// Fetcher loads the tasks listed by the view.
+type Fetcher func() ([]*ecs.Task, error)
+
+// OpenInstanceMsg asks the parent view to show the container instance a task
+// runs on.
+type OpenInstanceMsg struct {
+	ContainerInstanceArn string
+}
+
+// StopTaskMsg asks the parent view to stop a task, it is only sent after
+// AllowWrites.
+type StopTaskMsg struct {
+	Task *ecs.Task
+}
+
+// ExecMsg asks the parent view to open an ECS Exec session in the task, it
+// is only sent after AllowWrites.
+type ExecMsg struct {
+	Task *ecs.Task
+}
+
+type Model struct {
+	title         string
+	fetcher       Fetcher
+	linkInstances bool
+	allowWrites   bool
+	tasks         []*ecs.Task
+	table         table.Model
+	detail        *viewport.Model
+	state         sessionState
+	err           error
+	spinner       spinnertui.Model
+	width, height int
+	// detailArn is the task whose detail opens once the tasks are loaded.
+	detailArn string
+}
+
+type loadedMsg []*ecs.Task
+
+type errMsg struct{ err error }
+
+func (e errMsg) Error() string { return e.err.Error() }
+
+// New lists the tasks returned by fetcher. With linkInstances, i opens the
+// container instance of the selected task through OpenInstanceMsg.
+func New(title string, fetcher Fetcher, linkInstances bool, width, height int) Model {
+	m := Model{
+		title:         title,
+		fetcher:       fetcher,
+		linkInstances: linkInstances,
+		table:         keys.NewTable(table.WithFocused(true), table.WithStyles(theme.Current.Table())),
+		spinner:       spinnertui.New("Loading tasks"),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+// AllowWrites lets s request stopping the selected task through StopTaskMsg
+// and x opening a shell in it through ExecMsg.
+func (m *Model) AllowWrites() {
+	m.allowWrites = true
+}
+
+// OpenDetail opens the detail of the task taskArn once the tasks are loaded,
+// "" lists them as usual.
+func (m *Model) OpenDetail(taskArn string) {
+	m.detailArn = taskArn
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.table.SetColumns([]table.Column{
+		{Title: "task", Width: 32},
+		{Title: "status", Width: 18},
+		{Title: "health", Width: 10},
+		{Title: "started", Width: 19},
+		{Title: "zone", Width: 14},
+		{Title: "capacity", Width: 14},
+		{Title: "instance", Width: 32},
+		{Title: "revision", Width: max(width-32-18-10-19-14-14-32-16, 10)},
+	})
+	m.table.SetWidth(width)
+	m.table.SetHeight(max(height-6, 3))
+	if m.detail != nil {
+		m.detail.Width = width
+		m.detail.Height = max(height-4, 1)
+	}
+}
+
+func (m Model) fetch() tea.Msg {
+	logger.Println("started fetching tasks for", m.title)
+	defer logger.Println("finished fetching tasks for", m.title)
+	tasks, err := m.fetcher()
+	if err != nil {
+		return errMsg{err}
+	}
+	return loadedMsg(tasks)
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.fetch, m.spinner.SpinnerTick())
+}
+
+// Focused reports whether esc should close the view, it closes an open task
+// detail first.
+func (m Model) Focused() bool {
+	return m.detail == nil
+}
+
+func (m Model) selected() *ecs.Task {
+	if len(m.tasks) == 0 {
+		return nil
+	}
+	return m.tasks[m.table.Cursor()]
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case loadedMsg:
+		m.tasks = msg
+		// running tasks first, newest first within the same status
+		slices.SortStableFunc(m.tasks, func(i, j *ecs.Task) int {
+			iRunning := aws.StringValue(i.LastStatus) == ecs.DesiredStatusRunning
+			jRunning := aws.StringValue(j.LastStatus) == ecs.DesiredStatusRunning
+			if iRunning != jRunning {
+				if iRunning {
+					return -1
+				}
+				return 1
+			}
+			return aws.TimeValue(j.StartedAt).Compare(aws.TimeValue(i.StartedAt))
+		})
+		m.state = loaded
+		m.updateRows()
+		if i := slices.IndexFunc(m.tasks, func(t *ecs.Task) bool { return aws.StringValue(t.TaskArn) == m.detailArn }); i >= 0 {
+			m.table.SetCursor(i)
+			m.showDetail(m.tasks[i])
+		}
+		m.detailArn = ""
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		m.state = failed
+		return m, nil
+	case tea.KeyMsg:
+		if m.detail != nil && key.Matches(msg, keys.Map.Global.Back) {
+			m.detail = nil
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Map.Common.Reload):
+			if m.state != initial && m.detail == nil {
+				m.state = initial
+				return m, m.Init()
+			}
+		case key.Matches(msg, keys.Map.Tasks.Detail):
+			if task := m.selected(); task != nil && m.detail == nil {
+				m.showDetail(task)
+				return m, nil
+			}
+		case key.Matches(msg, keys.Map.Tasks.Instance):
+			if task := m.selected(); task != nil && m.linkInstances && task.ContainerInstanceArn != nil {
+				arn := *task.ContainerInstanceArn
+				return m, func() tea.Msg { return OpenInstanceMsg{ContainerInstanceArn: arn} }
+			}
+		case key.Matches(msg, keys.Map.Tasks.Stop):
+			if task := m.selected(); task != nil && m.allowWrites && m.detail == nil && aws.StringValue(task.LastStatus) != ecs.DesiredStatusStopped {
+				return m, func() tea.Msg { return StopTaskMsg{Task: task} }
+			}
+		case key.Matches(msg, keys.Map.Tasks.Exec):
+			if task := m.selected(); task != nil && m.allowWrites && m.detail == nil && aws.StringValue(task.LastStatus) == ecs.DesiredStatusRunning {
+				return m, func() tea.Msg { return ExecMsg{Task: task} }
+			}
+		}
+	}
+
+	if m.detail != nil {
+		detail, cmd := m.detail.Update(msg)
+		m.detail = &detail
+		return m, cmd
+	}
+	switch m.state {
+	case initial:
+		m.spinner, cmd = m.spinner.Update(msg)
+	case loaded:
+		m.table, cmd = m.table.Update(msg)
+	}
+	return m, cmd
+}
+
+func renderTask(task *ecs.Task) string {
+	lines := Describe(task)
+	for i, line := range lines {
+		if strings.HasPrefix(line, "container ") && !strings.HasPrefix(line, "container instance") {
+			lines[i] = theme.Current.Header.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *Model) showDetail(task *ecs.Task) {
+	detail := keys.NewViewport(m.width, max(m.height-4, 1))
+	detail.SetContent(renderTask(task))
+	m.detail = &detail
+}
+
+func (m *Model) updateRows() {
+	rows := make([]table.Row, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		started := ""
+		if task.StartedAt != nil {
+			started = task.StartedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		_, revision := utils.SplitTaskDefinitionArn(aws.StringValue(task.TaskDefinitionArn))
+		rows = append(rows, table.Row{
+			utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/"),
+			shortStatus(task),
+			aws.StringValue(task.HealthStatus),
+			started,
+			aws.StringValue(task.AvailabilityZone),
+			utils.TaskCapacity(task),
+			utils.GetLastItemAfterSplit(aws.StringValue(task.ContainerInstanceArn), "/"),
+			fmt.Sprintf("%d", revision),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+// Help lists the keys of the task list, or of the open task detail.
+func (m Model) Help() []key.Binding {
+	help := []key.Binding{}
+	if m.detail != nil {
+		help = append(help, keys.Map.Common.Scroll())
+	} else {
+		help = append(help, keys.Map.Common.Select(), keys.Map.Tasks.Detail, keys.Map.Common.Reload)
+	}
+	if m.linkInstances {
+		help = append(help, keys.Map.Tasks.Instance)
+	}
+	if m.allowWrites && m.detail == nil {
+		help = append(help, keys.Map.Tasks.Stop, keys.Map.Tasks.Exec)
+	}
+	return append(help, keys.Map.Global.Back)
+}
+
+func (m Model) footerView() string {
+	return keys.Render(theme.Current.HelpKey, theme.Current.HelpDesc, m.Help()...)
+}
+
+func (m Model) View() string {
+	switch m.state {
+	case initial:
+		return m.spinner.View()
+	case failed:
+		return lipgloss.JoinVertical(lipgloss.Left, m.err.Error(), "", m.footerView())
+	}
+	if m.detail != nil {
+		task := m.selected()
+		header := theme.Current.Title.Render(utils.GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/")) + " " + theme.Current.Subtle.Render(m.title)
+		return lipgloss.JoinVertical(lipgloss.Left, header, "", m.detail.View(), "", m.footerView())
+	}
+	header := theme.Current.Title.Render(m.title) + " " + theme.Current.Subtle.Render(fmt.Sprintf("%d tasks", len(m.tasks)))
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", m.table.View(), "", m.footerView())
+}
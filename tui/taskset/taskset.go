@@ -4,47 +4,48 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/ecs"
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	humanizer "github.com/dustin/go-humanize"
 	"github.com/mtyurt/ecstui/logger"
 	"github.com/mtyurt/ecstui/spinnertui"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/layout"
+	"github.com/mtyurt/ecstui/tui/tasktable"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
 	"github.com/mtyurt/ecstui/types"
 	"github.com/mtyurt/ecstui/utils"
 )
 
 var (
-	styles = list.DefaultStyles()
-
-	taskSetWidth      = 35
+	// task set boxes share the width between them within these bounds
+	minTaskSetWidth   = 30
+	maxTaskSetWidth   = 60
 	smallSectionStyle = lipgloss.NewStyle().
 				Width(32).
 				Height(6).
 				Margin(0, 1, 0, 0).
 				PaddingLeft(1).
 				Align(lipgloss.Center).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
+				BorderStyle(lipgloss.NormalBorder())
 	largeSectionStyle = lipgloss.NewStyle().
 				Width(150).
 				Height(10).
 				Margin(0, 1, 0, 0).
 				Align(lipgloss.Left, lipgloss.Center).
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
-	subtle              = lipgloss.NewStyle().Foreground(lipgloss.Color("#9B9B9B"))
-	bold                = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFBF00"))
-	healthy             = lipgloss.NewStyle().Foreground(lipgloss.Color("#80C904"))
-	unhealthy           = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFBF00"))
-	tgNameStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("#ADD8E6")) // Amber
-	refreshSpinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+				BorderStyle(lipgloss.NormalBorder())
 )
 
+// BoxZone is the zone kind of the task set boxes, keyed by task set ID.
+const BoxZone = "taskSet"
+
 type sessionState int
 
 const (
@@ -57,6 +58,7 @@ type Model struct {
 	images             map[string][]string
 	connections        map[string][]types.ConnectionConfig
 	tasks              map[string][]*ecs.Task
+	instanceIDs        map[string]string
 	taskSetMap         map[string]ecs.TaskSet
 	taskSets           []*ecs.TaskSet
 	statusFetcher      StatusFetcher
@@ -66,6 +68,13 @@ type Model struct {
 	spinner            spinnertui.Model
 	refreshSpinner     spinner.Model
 	showRefreshSpinner bool
+	sectionErrors      types.SectionErrors
+	staleSince         utils.StaleSections
+	lastUpdate         time.Time
+	// tables are the task tables by task set ID, focused the task set whose
+	// table has the keys.
+	tables  map[string]tasktable.Model
+	focused string
 }
 
 type StatusFetcher func(taskSets []*ecs.TaskSet) (*types.TaskSetStatus, error)
@@ -89,8 +98,9 @@ func New(statusFetcher StatusFetcher, taskSets []*ecs.TaskSet, width, height int
 		statusFetcher:      statusFetcher,
 		state:              initial,
 		spinner:            spinnertui.New("Loading tasksets"),
-		refreshSpinner:     spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(refreshSpinnerStyle)),
+		refreshSpinner:     spinner.New(spinner.WithSpinner(spinner.Hamburger), spinner.WithStyle(theme.Current.Highlight)),
 		showRefreshSpinner: false,
+		tables:             make(map[string]tasktable.Model),
 	}
 	m.SetSize(width, height)
 	return m
@@ -119,8 +129,15 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.fetchStatus, m.spinner.SpinnerTick())
 }
 
-func (m Model) Refresh() (Model, tea.Cmd) {
+// Refresh reloads the status of taskSets, the service's task sets as of its
+// last refresh.
+func (m Model) Refresh(taskSets []*ecs.TaskSet) (Model, tea.Cmd) {
 	logger.Println("refreshing taskset status")
+	m.taskSets = taskSets
+	m.taskSetMap = make(map[string]ecs.TaskSet)
+	for _, ts := range taskSets {
+		m.taskSetMap[*ts.Id] = *ts
+	}
 	m.showRefreshSpinner = true
 	return m, tea.Batch(m.fetchStatus, m.refreshSpinner.Tick)
 
@@ -130,16 +147,23 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if table, ok := m.tables[m.focused]; ok {
+			m.tables[m.focused], cmd = table.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case StatusMsg:
 		logger.Println("taskset status fetched")
-		m.images = msg.TaskSetImages
-		m.connections = msg.TaskSetConnections
-		m.tasks = msg.TaskSetTasks
+		m.mergeStatus(msg)
+		m.err = nil
 		m.state = loaded
 		m.showRefreshSpinner = false
 	case errMsg:
 		m.err = msg.err
-		m.state = failed
+		m.showRefreshSpinner = false
+		if m.state != loaded { // keep showing the last good data on refresh failures
+			m.state = failed
+		}
 	}
 
 	switch m.state {
@@ -159,15 +183,198 @@ func (m Model) View() string {
 	case initial:
 		return m.spinner.View()
 	case loaded:
-		return m.renderTaskSetsWithConnections()
+		view := m.renderTaskSetsWithConnections()
+		if m.err != nil {
+			view = lipgloss.JoinVertical(lipgloss.Center, theme.Current.Warning.Render(utils.StaleBannerText(m.err, m.lastUpdate, keys.Map.Service.Refresh.Help().Key, m.width)), view)
+		}
+		return view
 	case failed:
-		return m.err.Error()
+		return m.err.Error() + "\n" + theme.Current.Subtle.Render(utils.RetryHint(keys.Map.Service.Refresh.Help().Key))
 	default:
 		return m.spinner.View()
 
 	}
 }
 
+// Connections returns the last fetched load balancer connections of every
+// task set.
+func (m Model) Connections() []types.ConnectionConfig {
+	conns := []types.ConnectionConfig{}
+	for _, ts := range m.taskSets {
+		conns = append(conns, m.connections[*ts.Id]...)
+	}
+	return conns
+}
+
+// AllTasks returns the last fetched tasks of every task set.
+func (m Model) AllTasks() []*ecs.Task {
+	tasks := []*ecs.Task{}
+	for _, ts := range m.taskSets {
+		tasks = append(tasks, m.tasks[*ts.Id]...)
+	}
+	return tasks
+}
+
+// InstanceIDs returns the EC2 instance IDs of the container instances the
+// tasks run on.
+func (m Model) InstanceIDs() map[string]string {
+	return m.instanceIDs
+}
+
+// targetHealth returns the target health of the task set's tasks by task
+// ARN, or nil if the task set is not registered to a target group.
+func (m Model) targetHealth(ts ecs.TaskSet) map[string]string {
+	conns := m.connections[*ts.Id]
+	if len(conns) == 0 {
+		return nil
+	}
+	health := []*elbv2.TargetHealthDescription{}
+	for _, conn := range conns {
+		health = append(health, conn.TGHealth...)
+	}
+	return utils.NewTargetMatcher(m.tasks[*ts.Id], m.instanceIDs).TaskTargetHealth(m.tasks[*ts.Id], health)
+}
+
+// Tasks returns the last fetched tasks of the task set.
+func (m Model) Tasks(taskSetID string) []*ecs.Task {
+	return m.tasks[taskSetID]
+}
+
+// mergeStatus applies a fetched status. Sections that failed to load keep the
+// data of the previous refresh and are marked stale since it was loaded.
+func (m *Model) mergeStatus(status *types.TaskSetStatus) {
+	if status.TaskSetImages == nil {
+		status.TaskSetImages = make(map[string][]string)
+	}
+	if status.TaskSetConnections == nil {
+		status.TaskSetConnections = make(map[string][]types.ConnectionConfig)
+	}
+	if status.TaskSetTasks == nil {
+		status.TaskSetTasks = make(map[string][]*ecs.Task)
+	}
+	if status.InstanceIDs == nil {
+		status.InstanceIDs = make(map[string]string)
+	}
+	if status.Errors == nil {
+		status.Errors = make(types.SectionErrors)
+	}
+
+	merge := utils.NewSectionMerge(status.Errors, m.staleSince, m.lastUpdate)
+	for _, ts := range m.taskSets {
+		id := *ts.Id
+		utils.KeepSection(merge, id, types.SectionImages, status.TaskSetImages, m.images)
+		utils.KeepSection(merge, id, types.SectionConnections, status.TaskSetConnections, m.connections)
+		utils.KeepSection(merge, id, types.SectionTasks, status.TaskSetTasks, m.tasks)
+		if merge.Failed(id, types.SectionInstances) {
+			for arn, instanceID := range m.instanceIDs {
+				if _, ok := status.InstanceIDs[arn]; !ok {
+					status.InstanceIDs[arn] = instanceID
+				}
+			}
+			merge.Keep(id, types.SectionInstances)
+		}
+	}
+
+	m.images = status.TaskSetImages
+	m.connections = status.TaskSetConnections
+	m.tasks = status.TaskSetTasks
+	m.instanceIDs = status.InstanceIDs
+	m.sectionErrors = status.Errors
+	m.staleSince = merge.Stale
+	m.lastUpdate = time.Now()
+
+	for _, ts := range m.taskSets {
+		table, ok := m.tables[*ts.Id]
+		if !ok {
+			table = tasktable.New()
+		}
+		table.SetTasks(m.tasks[*ts.Id], m.targetHealth(*ts))
+		m.tables[*ts.Id] = table
+	}
+}
+
+// boxOrder lists the task set IDs in the order their boxes are shown: the
+// ones behind a load balancer by its name, then the unattached ones.
+func (m Model) boxOrder() []string {
+	lbName := func(id string) string {
+		if conns := m.connections[id]; len(conns) > 0 {
+			return conns[0].LBName
+		}
+		return ""
+	}
+	ids := []string{}
+	for _, ts := range m.taskSets {
+		ids = append(ids, *ts.Id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		la, lb := lbName(a), lbName(b)
+		if (la == "") != (lb == "") {
+			if la == "" {
+				return 1
+			}
+			return -1
+		}
+		if c := strings.Compare(la, lb); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return ids
+}
+
+// CycleFocus moves the keys to the task table of the next box, or the
+// previous one for a negative step, wrapping around. With no box focused it
+// starts from the first or the last.
+func (m *Model) CycleFocus(step int) {
+	next := tasktable.Next(m.boxOrder(), m.focused, step)
+	m.Blur()
+	if table, ok := m.tables[next]; ok {
+		table.Focus()
+		m.tables[next] = table
+		m.focused = next
+	}
+}
+
+// Blur takes the keys from the focused task table.
+func (m *Model) Blur() {
+	if table, ok := m.tables[m.focused]; ok {
+		table.Blur()
+		m.tables[m.focused] = table
+	}
+	m.focused = ""
+}
+
+// Focused reports whether a task table has the keys.
+func (m Model) Focused() bool {
+	return m.focused != ""
+}
+
+// Scroll scrolls the task table of the task set by n tasks, see
+// tasktable.Model.Scroll.
+func (m *Model) Scroll(taskSetID string, n int) bool {
+	table, ok := m.tables[taskSetID]
+	if !ok || !table.Scroll(n) {
+		return false
+	}
+	m.tables[taskSetID] = table
+	return true
+}
+
+// Help lists the keys of the focused task table.
+func (m Model) Help() []key.Binding {
+	return m.tables[m.focused].Help()
+}
+
+func (m Model) sectionWarning(taskSetID, section string) string {
+	return theme.Current.Alert(m.staleSince.Warning(m.sectionErrors.Get(taskSetID, section), taskSetID, section, keys.Map.Service.Refresh.Help().Key, m.boxWidth()-2))
+}
+
+// boxWidth is the width of a task set box, an equal share of the width
+// between the task sets.
+func (m Model) boxWidth() int {
+	return layout.Share(m.width, len(m.taskSets), 3, minTaskSetWidth, maxTaskSetWidth)
+}
+
 type taskSetView struct {
 	tsID string
 	view string
@@ -177,7 +384,13 @@ func (m *Model) renderTaskSetsWithConnections() string {
 	viewByConn := make(map[string][]taskSetView)
 	connByTaskSet := make(map[string]*types.ConnectionConfig)
 	lbRulePriority := make(map[string]string)
-	for taskSetID, conns := range m.connections {
+	taskSetIDs := make([]string, 0, len(m.connections))
+	for taskSetID := range m.connections {
+		taskSetIDs = append(taskSetIDs, taskSetID)
+	}
+	slices.Sort(taskSetIDs) // map order would shuffle the boxes on every render
+	for _, taskSetID := range taskSetIDs {
+		conns := m.connections[taskSetID]
 		priorities := []string{}
 		for _, conn := range conns {
 			if conn.LBName != "" && conn.Priority != "" {
@@ -188,6 +401,7 @@ func (m *Model) renderTaskSetsWithConnections() string {
 			LBName:    conns[0].LBName,
 			Priority:  strings.Join(priorities, ","),
 			TGName:    conns[0].TGName,
+			TGArn:     conns[0].TGArn,
 			TGWeigth:  conns[0].TGWeigth,
 			TaskSetID: taskSetID,
 			TGHealth:  conns[0].TGHealth,
@@ -201,6 +415,12 @@ func (m *Model) renderTaskSetsWithConnections() string {
 		}
 	}
 
+	for _, ts := range m.taskSets { // task sets whose connections are unknown are still shown
+		if _, ok := connByTaskSet[*ts.Id]; !ok {
+			connByTaskSet[*ts.Id] = &types.ConnectionConfig{TaskSetID: *ts.Id}
+		}
+	}
+
 	unattachedTaskSets := []taskSetView{}
 	for _, conn := range connByTaskSet {
 
@@ -217,13 +437,12 @@ func (m *Model) renderTaskSetsWithConnections() string {
 			unattachedTaskSets = append(unattachedTaskSets, taskSetView{tsID: conn.TaskSetID, view: view})
 		}
 	}
+	slices.SortFunc(unattachedTaskSets, func(i, j taskSetView) int {
+		return strings.Compare(i.tsID, j.tsID)
+	})
 	connViews := make(map[string]string)
 	for lbName, lbTaskSets := range viewByConn {
-		priority := subtle.Render("rules " + lbRulePriority[lbName])
-		bottom := smallSectionStyle.Copy().
-			Width(len(lbTaskSets)*taskSetWidth + 2).
-			Height(2).
-			Render(styles.Title.AlignHorizontal(lipgloss.Center).Render(lbName) + "\n" + priority)
+		priority := theme.Current.Subtle.Render("rules " + lbRulePriority[lbName])
 		slices.SortFunc(lbTaskSets, func(i, j taskSetView) int {
 			return strings.Compare(i.tsID, j.tsID)
 		})
@@ -232,19 +451,29 @@ func (m *Model) renderTaskSetsWithConnections() string {
 		for _, lbView := range lbTaskSets {
 			viewStrings = append(viewStrings, lbView.view)
 		}
+		taskSetsView := layout.Flow(m.width, lipgloss.Top, viewStrings...)
+		bottom := theme.Current.Bordered(smallSectionStyle).
+			Width(lipgloss.Width(taskSetsView) - 4).
+			Height(2).
+			Render(theme.Current.Title.Copy().AlignHorizontal(lipgloss.Center).Render(lbName) + "\n" + priority)
 
-		tgView := lipgloss.JoinVertical(lipgloss.Top, lipgloss.JoinHorizontal(lipgloss.Top, viewStrings...), bottom)
+		tgView := lipgloss.JoinVertical(lipgloss.Top, taskSetsView, bottom)
 		connViews[lbName] = tgView
 	}
+	lbNames := make([]string, 0, len(connViews))
+	for lbName := range connViews {
+		lbNames = append(lbNames, lbName)
+	}
+	slices.Sort(lbNames)
 	conns := make([]string, 0, len(viewByConn))
-	for _, conn := range connViews {
-		conns = append(conns, conn)
+	for _, lbName := range lbNames {
+		conns = append(conns, connViews[lbName])
 	}
 	for _, unattachedTaskSet := range unattachedTaskSets {
 		conns = append(conns, unattachedTaskSet.view)
 	}
 
-	return lipgloss.NewStyle().Width(m.width).AlignHorizontal(lipgloss.Center).AlignVertical(lipgloss.Top).Render(lipgloss.JoinHorizontal(lipgloss.Right, conns...))
+	return layout.Center(m.width, layout.Flow(m.width, lipgloss.Bottom, conns...))
 }
 
 func truncateTo(s string, max int) string {
@@ -262,7 +491,10 @@ func (m Model) renderAttachedTaskSet(connConfig types.ConnectionConfig) string {
 |
 %s`
 
-	attachment := fmt.Sprintf(attachmentTemplate, connConfig.TGWeigth, getTGNameAndHealth(connConfig))
+	attachment := fmt.Sprintf(attachmentTemplate, connConfig.TGWeigth, getTGNameAndHealth(connConfig, m.boxWidth()))
+	if warning := m.sectionWarning(connConfig.TaskSetID, types.SectionConnections); warning != "" {
+		attachment = attachment + "\n" + warning
+	}
 	return m.renderTaskSetWithAttachment(ts, attachment)
 }
 
@@ -275,14 +507,17 @@ func (m Model) renderUnattachedTaskSet(connConfig types.ConnectionConfig) string
 %s
 (unattached)`
 
-	attachment := fmt.Sprintf(attachmentTemplate, getTGNameAndHealth(connConfig))
+	attachment := fmt.Sprintf(attachmentTemplate, getTGNameAndHealth(connConfig, m.boxWidth()))
+	if warning := m.sectionWarning(connConfig.TaskSetID, types.SectionConnections); warning != "" {
+		attachment = attachment + "\n" + warning
+	}
 
 	return m.renderTaskSetWithAttachment(ts, attachment)
 }
 
-func getTGNameAndHealth(connConfig types.ConnectionConfig) string {
-	tgName := truncateTo(connConfig.TGName, taskSetWidth)
-	tgName = tgNameStyle.Render(tgName)
+func getTGNameAndHealth(connConfig types.ConnectionConfig, width int) string {
+	tgName := truncateTo(connConfig.TGName, width)
+	tgName = theme.Current.Info.Render(tgName)
 
 	healths := []string{}
 	azByState := make(map[string][]string)
@@ -300,9 +535,9 @@ func getTGNameAndHealth(connConfig types.ConnectionConfig) string {
 	}
 	slices.Sort(states)
 	for _, state := range states {
-		style := unhealthy
+		style := theme.Current.Warning
 		if state == "healthy" {
-			style = healthy
+			style = theme.Current.OK
 		}
 		slices.Sort(azByState[state])
 		azByState[state] = utils.UniqueStrings(azByState[state])
@@ -315,7 +550,12 @@ func (m Model) renderTaskSetWithAttachment(ts ecs.TaskSet, attachment string) st
 	content := m.renderTaskSetDetails(ts)
 
 	attachment = lipgloss.NewStyle().AlignHorizontal(lipgloss.Center).Render(attachment)
-	return lipgloss.JoinVertical(lipgloss.Center, smallSectionStyle.Copy().Height(10).Width(taskSetWidth).AlignHorizontal(lipgloss.Left).Render(content), attachment)
+	style := theme.Current.Bordered(smallSectionStyle)
+	if *ts.Id == m.focused {
+		style = theme.Current.Focused(smallSectionStyle)
+	}
+	box := zone.Mark(zone.ID(BoxZone, *ts.Id), style.Height(10).Width(m.boxWidth()).AlignHorizontal(lipgloss.Left).Render(content))
+	return lipgloss.JoinVertical(lipgloss.Center, box, attachment)
 
 }
 
@@ -323,35 +563,33 @@ func (m Model) renderTaskSetDetails(ts ecs.TaskSet) string {
 	taskCreation := *ts.CreatedAt
 	taskDefinition := utils.GetLastItemAfterSplit(*ts.TaskDefinition, "/")
 	status := *ts.Status
-	taskIds := []table.Row{}
-	for _, task := range m.tasks[*ts.Id] {
-		taskIds = append(taskIds, table.Row{utils.GetLastItemAfterSplit(*task.TaskArn, "/"), utils.MapTaskStatusToLabel(*task.LastStatus)})
-	}
-	tableStyles := table.DefaultStyles()
-	tableStyles.Selected = lipgloss.NewStyle()
-	tableStyles.Header = tableStyles.Header.Copy().PaddingLeft(1)
-	taskTable := table.New(
-		table.WithColumns([]table.Column{{Title: "id", Width: 10}, {Title: "status", Width: 30}}),
-		table.WithRows(taskIds),
-		table.WithHeight(len(taskIds)),
-		table.WithFocused(false),
-		table.WithStyles(tableStyles),
-	)
-
-	title := styles.Title.Copy().Padding(0).MarginBottom(0).Render(truncateTo(*ts.Id, taskSetWidth-2))
+	width := m.boxWidth()
+	title := theme.Current.Title.Copy().Padding(0).MarginBottom(0).Render(truncateTo(*ts.Id, width-2))
 	if m.showRefreshSpinner {
-		space := taskSetWidth - lipgloss.Width(title) - lipgloss.Width(m.refreshSpinner.View())
+		space := width - lipgloss.Width(title) - lipgloss.Width(m.refreshSpinner.View())
 		title = title + strings.Repeat(" ", space) + m.refreshSpinner.View()
 	}
 	title = title + "\n"
-	content := lipgloss.JoinVertical(lipgloss.Left,
+	lines := []string{
 		title,
-		"created "+humanizer.Time(taskCreation),
-		fmt.Sprintf("%s: %s", bold.Render("status"), status),
-		fmt.Sprintf("%s: %s", bold.Render("steady"), *ts.StabilityStatus),
-		fmt.Sprintf("\n%s: %s", bold.Render("taskdef"), taskDefinition), utils.JoinImageNames(m.images[*ts.Id]),
-		bold.Render("tasks:")+"\n"+taskTable.View(),
-	)
-
-	return content
+		"created " + humanizer.Time(taskCreation),
+		fmt.Sprintf("%s: %s", theme.Current.Header.Render("status"), status),
+		fmt.Sprintf("%s: %s", theme.Current.Header.Render("steady"), *ts.StabilityStatus),
+		fmt.Sprintf("\n%s: %s", theme.Current.Header.Render("taskdef"), taskDefinition), layout.WrapText(utils.JoinImageNames(m.images[*ts.Id]), width-smallSectionStyle.GetHorizontalPadding()),
+	}
+	if mismatches := utils.DigestMismatches(m.tasks[*ts.Id]); len(mismatches) > 0 {
+		lines = append(lines, theme.Current.Header.Render("⚠ digest drift: ")+truncateTo(strings.Join(mismatches, ", "), width-2))
+	}
+	if warning := m.sectionWarning(*ts.Id, types.SectionImages); warning != "" {
+		lines = append(lines, warning)
+	}
+	lines = append(lines, theme.Current.Header.Render("tasks:")+"\n"+m.tables[*ts.Id].View(width-1))
+	if warning := m.sectionWarning(*ts.Id, types.SectionTasks); warning != "" {
+		lines = append(lines, warning)
+	}
+	if warning := m.sectionWarning(*ts.Id, types.SectionInstances); warning != "" {
+		lines = append(lines, warning)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
@@ -0,0 +1,107 @@
+package taskset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mtyurt/ecstui/internal/fixtures"
+	"github.com/mtyurt/ecstui/internal/golden"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/types"
+)
+
+func TestView(t *testing.T) {
+	for _, width := range golden.Widths {
+		for _, scenario := range fixtures.Scenarios() {
+			if scenario.TaskSets == nil {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s/%d", scenario.Name, width), func(t *testing.T) {
+				m := New(nil, scenario.Service.Ecs.TaskSets, width, 0)
+				loaded, _ := m.Update(StatusMsg(scenario.TaskSets))
+				golden.Assert(t, fmt.Sprintf("%s_%d", scenario.Name, width), loaded.View())
+			})
+		}
+	}
+}
+
+func TestViewSectionError(t *testing.T) {
+	scenario := fixtures.BlueGreen()
+	delete(scenario.TaskSets.TaskSetTasks, "ecs-svc/8895224990753999325")
+	scenario.TaskSets.Errors.Add("ecs-svc/8895224990753999325", types.SectionTasks, errors.New("ThrottlingException: Rate exceeded"))
+	m := New(nil, scenario.Service.Ecs.TaskSets, 120, 0)
+	loaded, _ := m.Update(StatusMsg(scenario.TaskSets))
+	golden.Assert(t, "section_error_120", loaded.View())
+}
+
+func TestViewFailed(t *testing.T) {
+	scenario := fixtures.BlueGreen()
+	m := New(nil, scenario.Service.Ecs.TaskSets, 120, 0)
+	failed, _ := m.Update(errMsg{errors.New("AccessDeniedException: not authorized to perform elasticloadbalancing:DescribeTargetHealth")})
+	golden.Assert(t, "failed_120", failed.View())
+}
+
+func TestRetryHintFollowsRefreshKey(t *testing.T) {
+	t.Cleanup(func() { keys.Map = keys.Default() })
+	if err := keys.Map.Apply(map[string][]string{"service.refresh": {"f5"}}); err != nil {
+		t.Fatal(err)
+	}
+	scenario := fixtures.BlueGreen()
+	scenario.TaskSets.Errors.Add("ecs-svc/8895224990753999325", types.SectionTasks, errors.New("ThrottlingException: Rate exceeded"))
+	m := New(nil, scenario.Service.Ecs.TaskSets, 120, 0)
+	loaded, _ := m.Update(StatusMsg(scenario.TaskSets))
+	failed, _ := m.Update(errMsg{errors.New("AccessDeniedException")})
+	for _, view := range []string{loaded.View(), failed.View()} {
+		if !strings.Contains(view, "f5 retry") || strings.Contains(view, "ctrl+r") {
+			t.Errorf("view does not name the rebound refresh key:\n%s", view)
+		}
+	}
+}
+
+func TestStaleSection(t *testing.T) {
+	const id = "ecs-svc/8895224990753999325"
+	m := New(nil, fixtures.BlueGreen().Service.Ecs.TaskSets, 120, 0)
+	loaded, _ := m.Update(StatusMsg(fixtures.BlueGreen().TaskSets))
+	tasks := loaded.Tasks(id)
+	if len(tasks) == 0 {
+		t.Fatal("the fixture has no tasks in the task set")
+	}
+
+	failed := fixtures.BlueGreen().TaskSets
+	delete(failed.TaskSetTasks, id)
+	failed.Errors.Add(id, types.SectionTasks, errors.New("ThrottlingException: Rate exceeded"))
+	refreshed, _ := loaded.Update(StatusMsg(failed))
+	if got := refreshed.Tasks(id); len(got) != len(tasks) || got[0] != tasks[0] {
+		t.Fatalf("tasks after the failed refresh = %v, want the previous ones", got)
+	}
+	since := refreshed.staleSince.Since(id, types.SectionTasks)
+	if !since.Equal(loaded.lastUpdate) {
+		t.Errorf("stale since %v, want the previous load at %v", since, loaded.lastUpdate)
+	}
+	view := refreshed.View()
+	for _, want := range []string{"ThrottlingException", "stale since " + since.Format("15:04:05")} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// failing again, the data stays stale since the load it came from
+	refreshed, _ = refreshed.Update(StatusMsg(failed))
+	if got := refreshed.staleSince.Since(id, types.SectionTasks); !got.Equal(since) {
+		t.Errorf("stale since %v after another failed refresh, want %v", got, since)
+	}
+	if got := refreshed.Tasks(id); len(got) != len(tasks) {
+		t.Errorf("%d tasks after another failed refresh, want %d", len(got), len(tasks))
+	}
+
+	// a successful refresh clears it
+	refreshed, _ = refreshed.Update(StatusMsg(fixtures.BlueGreen().TaskSets))
+	if got := refreshed.staleSince.Since(id, types.SectionTasks); !got.IsZero() {
+		t.Errorf("stale since %v after a successful refresh", got)
+	}
+	if strings.Contains(refreshed.View(), "stale since") {
+		t.Errorf("view still marks a section stale:\n%s", refreshed.View())
+	}
+}
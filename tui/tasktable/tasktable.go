@@ -0,0 +1,364 @@
+// Package tasktable shows the tasks of a task set or deployment box. Tables
+// longer than a page scroll and page through their tasks, sort them and
+// collapse into counts by status.
+package tasktable
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtyurt/ecstui/tui/keys"
+	"github.com/mtyurt/ecstui/tui/theme"
+	"github.com/mtyurt/ecstui/tui/zone"
+	"github.com/mtyurt/ecstui/utils"
+)
+
+// PageSize is the number of tasks a table shows at once. Longer tables
+// start collapsed.
+const PageSize = 8
+
+// RowZone is the zone kind of the rows, keyed by task ARN.
+const RowZone = "task"
+
+type Order int
+
+const (
+	ByStatus Order = iota
+	ByStartTime
+	ByZone
+)
+
+func (o Order) String() string {
+	switch o {
+	case ByStartTime:
+		return "start time"
+	case ByZone:
+		return "zone"
+	}
+	return "status"
+}
+
+func (o Order) next() Order {
+	return (o + 1) % 3
+}
+
+// lifecycle orders task statuses from starting to stopped.
+var lifecycle = []string{"PROVISIONING", "PENDING", "ACTIVATING", "RUNNING", "DEACTIVATING", "STOPPING", "DEPROVISIONING", "STOPPED"}
+
+type Model struct {
+	tasks []*ecs.Task
+	// targetHealth is the target health by task ARN, nil if the tasks are
+	// not registered to a target group.
+	targetHealth   map[string]string
+	order          Order
+	cursor, offset int
+	collapsed      bool
+	// toggled is set once the table is collapsed or expanded by hand, until
+	// then it is collapsed while it is longer than a page.
+	toggled bool
+	focused bool
+}
+
+func New() Model {
+	return Model{}
+}
+
+// SetTasks replaces the tasks of the table, keeping its order, cursor and
+// whether it is collapsed.
+func (m *Model) SetTasks(tasks []*ecs.Task, targetHealth map[string]string) {
+	m.tasks = slices.Clone(tasks)
+	m.targetHealth = targetHealth
+	m.sort()
+	if !m.toggled {
+		m.collapsed = len(m.tasks) > PageSize
+	}
+	m.moveCursor(0)
+}
+
+func (m *Model) Focus() {
+	m.focused = true
+}
+
+func (m *Model) Blur() {
+	m.focused = false
+}
+
+func (m Model) Focused() bool {
+	return m.focused
+}
+
+// Selected returns the task under the cursor, nil for an empty table.
+func (m Model) Selected() *ecs.Task {
+	if len(m.tasks) == 0 {
+		return nil
+	}
+	return m.tasks[m.cursor]
+}
+
+// Next returns the box ID after current in ids, or before it for a negative
+// step, wrapping around. With current not in ids it starts from the first
+// or the last.
+func Next(ids []string, current string, step int) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	i := slices.Index(ids, current)
+	switch {
+	case i < 0 && step < 0:
+		i = len(ids) - 1
+	case i < 0:
+		i = 0
+	default:
+		i = (i + step%len(ids) + len(ids)) % len(ids)
+	}
+	return ids[i]
+}
+
+func (m *Model) sort() {
+	rank := func(t *ecs.Task) int {
+		if i := slices.Index(lifecycle, aws.StringValue(t.LastStatus)); i >= 0 {
+			return i
+		}
+		return len(lifecycle)
+	}
+	slices.SortStableFunc(m.tasks, func(a, b *ecs.Task) int {
+		var c int
+		switch m.order {
+		case ByStatus:
+			c = rank(a) - rank(b)
+		case ByStartTime: // newest first, tasks yet to start before them
+			switch {
+			case a.StartedAt == nil && b.StartedAt != nil:
+				c = -1
+			case a.StartedAt != nil && b.StartedAt == nil:
+				c = 1
+			case a.StartedAt != nil:
+				c = b.StartedAt.Compare(*a.StartedAt)
+			}
+		case ByZone:
+			c = strings.Compare(aws.StringValue(a.AvailabilityZone), aws.StringValue(b.AvailabilityZone))
+		}
+		if c != 0 {
+			return c
+		}
+		return strings.Compare(aws.StringValue(a.TaskArn), aws.StringValue(b.TaskArn))
+	})
+}
+
+// moveCursor moves the cursor by n tasks, scrolling the table to keep it in
+// sight.
+func (m *Model) moveCursor(n int) {
+	m.cursor = min(max(m.cursor+n, 0), max(len(m.tasks)-1, 0))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+PageSize {
+		m.offset = m.cursor - PageSize + 1
+	}
+	m.offset = min(m.offset, max(len(m.tasks)-PageSize, 0))
+}
+
+// page scrolls the table by n pages, the cursor along with it.
+func (m *Model) page(n int) {
+	m.offset = min(max(m.offset+n*PageSize, 0), max(len(m.tasks)-PageSize, 0))
+	m.moveCursor(n * PageSize)
+}
+
+// Scroll scrolls the table by n tasks, keeping the cursor on the page, and
+// reports whether it had more tasks than a page to scroll through.
+func (m *Model) Scroll(n int) bool {
+	if m.collapsed || len(m.tasks) <= PageSize {
+		return false
+	}
+	m.offset = min(max(m.offset+n, 0), len(m.tasks)-PageSize)
+	m.cursor = min(max(m.cursor, m.offset), m.offset+PageSize-1)
+	return true
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.focused {
+		return m, nil
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		t := keys.Map.TaskTable
+		switch {
+		case key.Matches(msg, t.Collapse):
+			m.collapsed = !m.collapsed
+			m.toggled = true
+		case key.Matches(msg, t.Sort):
+			m.order = m.order.next()
+			m.sort()
+		case m.collapsed:
+		case key.Matches(msg, keys.Map.Common.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, keys.Map.Common.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, t.PrevPage):
+			m.page(-1)
+		case key.Matches(msg, t.NextPage):
+			m.page(1)
+		}
+	}
+	return m, nil
+}
+
+// Help lists the keys of the table while it is focused.
+func (m Model) Help() []key.Binding {
+	t := keys.Map.TaskTable
+	sort := keys.Describe(t.Sort, "sort by "+m.order.next().String())
+	if m.collapsed {
+		return []key.Binding{keys.Describe(t.Collapse, "expand"), sort}
+	}
+	return []key.Binding{keys.Map.Common.Select(), t.Pages(), sort, t.Collapse}
+}
+
+// View renders the table width columns wide.
+func (m Model) View(width int) string {
+	if m.collapsed {
+		return m.countsView(width)
+	}
+	statusWidth := 20
+	if m.targetHealth != nil {
+		statusWidth = 10
+	}
+	columns := []table.Column{{Title: "id"}, {Title: "status", Width: statusWidth}}
+	if m.targetHealth != nil {
+		columns = append(columns, table.Column{Title: "target", Width: 10})
+	}
+	switch m.order { // the column sorted by, if there is room for it
+	case ByStartTime:
+		columns = append(columns, table.Column{Title: "started", Width: 8})
+	case ByZone:
+		columns = append(columns, table.Column{Title: "zone", Width: 5})
+	}
+	if idWidth(width, columns) < 8 && len(columns) > 2 && m.order != ByStatus {
+		columns = columns[:len(columns)-1]
+	}
+	columns[0].Width = idWidth(width, columns)
+
+	end := min(m.offset+PageSize, len(m.tasks))
+	rows := []table.Row{}
+	for _, task := range m.tasks[m.offset:end] {
+		row := table.Row{utils.GetLastItemAfterSplit(*task.TaskArn, "/"), statusLabel(*task.LastStatus)}
+		if m.targetHealth != nil {
+			row = append(row, targetHealthLabel(m.targetHealth[*task.TaskArn]))
+		}
+		switch columns[len(columns)-1].Title {
+		case "started":
+			started := "-"
+			if task.StartedAt != nil {
+				started = task.StartedAt.Local().Format("15:04:05")
+			}
+			row = append(row, started)
+		case "zone":
+			row = append(row, utils.GetLastItemAfterSplit(aws.StringValue(task.AvailabilityZone), "-"))
+		}
+		rows = append(rows, row)
+	}
+	lines := strings.Split(utils.RenderTable(columns, rows), "\n")
+	if m.focused && len(rows) > 0 {
+		i := m.cursor - m.offset + 1 // past the header
+		lines[i] = theme.Current.Selected.Render("›" + lines[i][1:])
+	}
+	for i, task := range m.tasks[m.offset:end] {
+		lines[i+1] = zone.Mark(zone.ID(RowZone, aws.StringValue(task.TaskArn)), lines[i+1])
+	}
+	if len(m.tasks) > PageSize || m.focused {
+		lines = append(lines, theme.Current.Subtle.Render(fmt.Sprintf("%d-%d of %d, by %s", min(m.offset+1, end), end, len(m.tasks), m.order)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// idWidth is what the id column has left of width next to the others, each
+// padded with a space on both sides.
+func idWidth(width int, columns []table.Column) int {
+	w := width
+	for _, c := range columns[1:] {
+		w -= c.Width
+	}
+	return w - 2*len(columns)
+}
+
+// countsView summarizes the tasks by status and target health.
+func (m Model) countsView(width int) string {
+	statuses := map[string]int{}
+	health := map[string]int{}
+	for _, task := range m.tasks {
+		statuses[aws.StringValue(task.LastStatus)]++
+		if m.targetHealth != nil {
+			health[m.targetHealth[aws.StringValue(task.TaskArn)]]++
+		}
+	}
+	names := []string{}
+	for name := range statuses {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		ia, ib := slices.Index(lifecycle, a), slices.Index(lifecycle, b)
+		if ia < 0 || ib < 0 || ia == ib {
+			return strings.Compare(a, b)
+		}
+		return ia - ib
+	})
+	counts := []string{}
+	for _, name := range names {
+		counts = append(counts, fmt.Sprintf("%s %d", statusLabel(name), statuses[name]))
+	}
+	lines := []string{strings.Join(counts, " ")}
+	if len(health) > 0 {
+		states := []string{}
+		for state := range health {
+			states = append(states, state)
+		}
+		slices.Sort(states)
+		counts = []string{}
+		for _, state := range states {
+			counts = append(counts, fmt.Sprintf("%s %d", targetHealthLabel(state), health[state]))
+		}
+		lines = append(lines, strings.Join(counts, " "))
+	}
+	lines = append(lines, theme.Current.Subtle.Render(fmt.Sprintf("%d tasks, collapsed", len(m.tasks))))
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// statusLabel styles a task status by whether the task is up, on its way or
+// gone.
+func statusLabel(status string) string {
+	style := lipgloss.NewStyle()
+	switch status {
+	case "RUNNING":
+		style = theme.Current.OK
+	case "ACTIVATING", "DEACTIVATING":
+		style = theme.Current.Warning
+	case "PENDING", "STOPPING":
+		style = theme.Current.Pending
+	case "PROVISIONING", "DEPROVISIONING":
+		style = theme.Current.Info
+	case "STOPPED":
+		style = theme.Current.Failed
+	}
+	return style.Render(utils.TaskStatusLabel(status))
+}
+
+// targetHealthLabel styles a task's target health state the way the task
+// status labels are styled.
+func targetHealthLabel(state string) string {
+	switch state {
+	case "healthy":
+		return theme.Current.OK.Render(state)
+	case "unhealthy", "unavailable":
+		return theme.Current.Failed.Render(state)
+	case utils.NotRegistered:
+		return theme.Current.Subtle.Render(state)
+	default:
+		return theme.Current.Warning.Render(state)
+	}
+}
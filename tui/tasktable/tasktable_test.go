@@ -0,0 +1,142 @@
+package tasktable
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func task(id, status, zone string, started time.Time) *ecs.Task {
+	t := &ecs.Task{
+		TaskArn:          aws.String("arn:aws:ecs:me-central-1:139007003299:task/app/" + id),
+		LastStatus:       aws.String(status),
+		AvailabilityZone: aws.String(zone),
+	}
+	if !started.IsZero() {
+		t.StartedAt = aws.Time(started)
+	}
+	return t
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestSort(t *testing.T) {
+	now := time.Now()
+	m := New()
+	m.SetTasks([]*ecs.Task{
+		task("a", "RUNNING", "me-central-1c", now.Add(-time.Hour)),
+		task("b", "PENDING", "me-central-1b", time.Time{}),
+		task("c", "RUNNING", "me-central-1a", now),
+	}, nil)
+	m.Focus()
+	want := map[Order]string{ByStatus: "bac", ByStartTime: "bca", ByZone: "cba"}
+	for _, order := range []Order{ByStatus, ByStartTime, ByZone} {
+		ids := ""
+		for _, task := range m.tasks {
+			ids += (*task.TaskArn)[len(*task.TaskArn)-1:]
+		}
+		if ids != want[order] {
+			t.Errorf("by %s the tasks are %s, want %s", order, ids, want[order])
+		}
+		m = press(m, "s")
+	}
+}
+
+func TestPages(t *testing.T) {
+	tasks := []*ecs.Task{}
+	for _, id := range strings.Split("abcdefghijklmnopqrst", "") {
+		tasks = append(tasks, task(id, "RUNNING", "me-central-1a", time.Time{}))
+	}
+	m := New()
+	m.SetTasks(tasks, nil)
+	if !m.collapsed {
+		t.Fatal("a table longer than a page is not collapsed")
+	}
+	if view := m.View(40); !strings.Contains(view, "RUNNING 20") {
+		t.Errorf("collapsed table does not count the tasks:\n%s", view)
+	}
+
+	m = press(m, "down")
+	if m.cursor != 0 {
+		t.Error("the cursor moved before the table had the keys")
+	}
+	m.Focus()
+	m = press(m, "c", "down", "right", "right")
+	if m.cursor != 17 || m.offset != 12 {
+		t.Errorf("cursor %d, offset %d after paging to the end, want 17 and 12", m.cursor, m.offset)
+	}
+	if view := m.View(40); !strings.Contains(view, "13-20 of 20") {
+		t.Errorf("the last page is not shown:\n%s", view)
+	}
+
+	// refreshes keep the table expanded
+	m.SetTasks(tasks[:10], nil)
+	if m.collapsed || m.cursor != 9 {
+		t.Errorf("collapsed %v, cursor %d after a refresh, want expanded at 9", m.collapsed, m.cursor)
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tasks := []*ecs.Task{}
+	for _, id := range strings.Split("abcdefghij", "") {
+		tasks = append(tasks, task(id, "RUNNING", "me-central-1a", time.Time{}))
+	}
+	m := New()
+	m.SetTasks(tasks, nil)
+	if m.Scroll(1) {
+		t.Error("a collapsed table scrolled")
+	}
+	m.Focus()
+	m = press(m, "c")
+	for i := 0; i < 5; i++ {
+		m.Scroll(1)
+	}
+	if m.offset != 2 || m.cursor != 2 {
+		t.Errorf("cursor %d, offset %d after scrolling past the end, want 2 and 2", m.cursor, m.offset)
+	}
+	m.Scroll(-1)
+	if m.offset != 1 || m.cursor != 2 {
+		t.Errorf("cursor %d, offset %d after scrolling back, want 2 and 1", m.cursor, m.offset)
+	}
+
+	m.SetTasks(tasks[:PageSize], nil)
+	if m.Scroll(1) {
+		t.Error("a table of one page scrolled")
+	}
+}
+
+func TestNext(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	cases := []struct {
+		current string
+		step    int
+		want    string
+	}{
+		{"", 1, "a"},
+		{"", -1, "c"},
+		{"a", 1, "b"},
+		{"c", 1, "a"},
+		{"a", -1, "c"},
+	}
+	for _, c := range cases {
+		if got := Next(ids, c.current, c.step); got != c.want {
+			t.Errorf("Next(%q, %d) = %q, want %q", c.current, c.step, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package theme holds the colors of every screen. Views render with the
+// styles of Current, which main sets from the config file before the first
+// screen is drawn.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Palette holds the colors of a theme for one terminal background, as
+// lipgloss color strings. An empty string leaves the terminal's color.
+type Palette struct {
+	Text    string
+	Subtle  string
+	HelpKey string
+	// Accent is the background of screen titles, AccentText their text.
+	Accent     string
+	AccentText string
+	// Brand is the background of the service title.
+	Brand  string
+	Border string
+	// Highlight marks filter matches, spinners and the input cursor.
+	Highlight    string
+	Selected     string
+	SelectedText string
+	Prompt       string
+	// OK, Warning and Failed are the health states, Pending and Info the
+	// states in between, like provisioning tasks.
+	OK      string
+	Warning string
+	Failed  string
+	Pending string
+	Info    string
+}
+
+type Theme struct {
+	Name string
+	// Light is used on light terminal backgrounds, Dark on dark ones.
+	Light, Dark Palette
+	// Mono themes have no colors, what colors tell is marked with bold,
+	// underline and reverse video instead.
+	Mono bool
+	// BoldStates renders health states in bold too, to tell them apart by
+	// more than hue.
+	BoldStates bool
+}
+
+var (
+	dark = Palette{
+		Text:         "#D9DCCF",
+		Subtle:       "#9B9B9B",
+		HelpKey:      "#9B9BCC",
+		Accent:       "#5A56E0",
+		AccentText:   "#FFFDF5",
+		Brand:        "#7D56F4",
+		Border:       "#AD58B4",
+		Highlight:    "205",
+		Selected:     "57",
+		SelectedText: "229",
+		Prompt:       "#ECFD65",
+		OK:           "#80C904",
+		Warning:      "#FFBF00",
+		Failed:       "#FF007A",
+		Pending:      "212",
+		Info:         "#ADD8E6",
+	}
+	light = Palette{
+		Text:         "#383838",
+		Subtle:       "#6E6E6E",
+		HelpKey:      "#5C5CA8",
+		Accent:       "#5A56E0",
+		AccentText:   "#FFFDF5",
+		Brand:        "#7D56F4",
+		Border:       "#F793FF",
+		Highlight:    "#D7005F",
+		Selected:     "#5A56E0",
+		SelectedText: "#FFFDF5",
+		Prompt:       "#04B575",
+		OK:           "#2E7D32",
+		Warning:      "#B35C00",
+		Failed:       "#C8005A",
+		Pending:      "#A000A0",
+		Info:         "#1F6FB2",
+	}
+	// highContrast uses the Okabe-Ito colors for the states, which stay
+	// apart with the common kinds of color blindness: blue for healthy,
+	// yellow for warnings and vermillion for failures.
+	highContrast = Palette{
+		Text:         "#FFFFFF",
+		Subtle:       "#C0C0C0",
+		HelpKey:      "#FFFFFF",
+		Accent:       "#0072B2",
+		AccentText:   "#FFFFFF",
+		Brand:        "#0072B2",
+		Border:       "#FFFFFF",
+		Highlight:    "#CC79A7",
+		Selected:     "#FFFFFF",
+		SelectedText: "#000000",
+		Prompt:       "#56B4E9",
+		OK:           "#56B4E9",
+		Warning:      "#F0E442",
+		Failed:       "#D55E00",
+		Pending:      "#CC79A7",
+		Info:         "#009E73",
+	}
+)
+
+var themes = []Theme{
+	{Name: "auto", Light: light, Dark: dark},
+	{Name: "dark", Light: dark, Dark: dark},
+	{Name: "light", Light: light, Dark: light},
+	{Name: "high-contrast", Light: highContrast, Dark: highContrast, BoldStates: true},
+	{Name: "no-color", Mono: true},
+}
+
+// Names lists the themes Load accepts.
+func Names() []string {
+	names := []string{}
+	for _, t := range themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Current are the styles of the theme in use.
+var Current = themes[0].Styles()
+
+// Load switches to the named theme, "" keeps the default. NO_COLOR in the
+// environment wins over it, see https://no-color.org.
+func Load(name string) error {
+	if os.Getenv("NO_COLOR") != "" {
+		name = "no-color"
+	}
+	if name == "" {
+		name = themes[0].Name
+	}
+	i := slices.IndexFunc(themes, func(t Theme) bool { return t.Name == name })
+	if i < 0 {
+		return fmt.Errorf("unknown theme %q, the known ones are %s", name, strings.Join(Names(), ", "))
+	}
+	if themes[i].Mono {
+		// lipgloss drops every attribute along with the colors under
+		// NO_COLOR, which would hide selections
+		output := termenv.NewOutput(os.Stdout)
+		if output.ColorProfile() != termenv.Ascii {
+			lipgloss.SetColorProfile(termenv.ANSI)
+		}
+	}
+	Current = themes[i].Styles()
+	return nil
+}
+
+// Styles are the styles views render with.
+type Styles struct {
+	// Title heads a screen, Header a part of it, like a container or a
+	// table column.
+	Title  lipgloss.Style
+	Header lipgloss.Style
+	// ServiceTitle is the service ARN above the service screen, Banner the
+	// write mode warning and ErrorTitle the heading of a failure.
+	ServiceTitle lipgloss.Style
+	Banner       lipgloss.Style
+	ErrorTitle   lipgloss.Style
+	Text         lipgloss.Style
+	Subtle       lipgloss.Style
+	HelpKey      lipgloss.Style
+	HelpDesc     lipgloss.Style
+	OK           lipgloss.Style
+	Warning      lipgloss.Style
+	Failed       lipgloss.Style
+	Pending      lipgloss.Style
+	Info         lipgloss.Style
+	// Call names an API call or other identifier worth spotting.
+	Call      lipgloss.Style
+	Selected  lipgloss.Style
+	Highlight lipgloss.Style
+	Prompt    lipgloss.Style
+	Border    lipgloss.TerminalColor
+	mono      bool
+}
+
+func (t Theme) color(light, dark string) lipgloss.TerminalColor {
+	switch {
+	case light == "" && dark == "":
+		return lipgloss.NoColor{}
+	case light == dark:
+		return lipgloss.Color(dark)
+	}
+	return lipgloss.AdaptiveColor{Light: light, Dark: dark}
+}
+
+func (t Theme) Styles() Styles {
+	l, d := t.Light, t.Dark
+	fg := func(light, dark string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(t.color(light, dark))
+	}
+	state := func(light, dark string) lipgloss.Style {
+		return fg(light, dark).Bold(t.BoldStates)
+	}
+	s := Styles{
+		Title:        fg(l.AccentText, d.AccentText).Bold(true).Background(t.color(l.Accent, d.Accent)).Padding(0, 1),
+		Header:       fg(l.Warning, d.Warning).Bold(true),
+		ServiceTitle: fg(l.Text, d.Text).Bold(true).Background(t.color(l.Brand, d.Brand)),
+		Banner:       fg(l.AccentText, d.AccentText).Bold(true).Background(t.color(l.Failed, d.Failed)).Padding(0, 1),
+		ErrorTitle:   fg(l.Failed, d.Failed).Bold(true),
+		Text:         fg(l.Text, d.Text),
+		Subtle:       fg(l.Subtle, d.Subtle),
+		HelpKey:      fg(l.HelpKey, d.HelpKey).Bold(true),
+		HelpDesc:     fg(l.Subtle, d.Subtle),
+		OK:           state(l.OK, d.OK),
+		Warning:      state(l.Warning, d.Warning),
+		Failed:       state(l.Failed, d.Failed),
+		Pending:      fg(l.Pending, d.Pending),
+		Info:         fg(l.Info, d.Info),
+		Call:         fg(l.Info, d.Info).Bold(true),
+		Selected:     fg(l.SelectedText, d.SelectedText).Background(t.color(l.Selected, d.Selected)),
+		Highlight:    fg(l.Highlight, d.Highlight),
+		Prompt:       fg(l.Prompt, d.Prompt),
+		Border:       t.color(l.Border, d.Border),
+		mono:         t.Mono,
+	}
+	if t.Mono {
+		s.Title = s.Title.Reverse(true)
+		s.Header = s.Header.Underline(true)
+		s.ServiceTitle = s.ServiceTitle.Reverse(true)
+		s.Banner = s.Banner.Reverse(true)
+		s.Warning = s.Warning.Bold(true)
+		s.Failed = s.Failed.Bold(true).Underline(true)
+		s.Selected = s.Selected.Reverse(true)
+		s.Highlight = s.Highlight.Bold(true).Underline(true)
+	}
+	return s
+}
+
+// Bordered returns a copy of style with its border in the theme's color.
+func (s Styles) Bordered(style lipgloss.Style) lipgloss.Style {
+	return style.Copy().BorderForeground(s.Border)
+}
+
+// Focused returns a copy of style with a thick border in the highlight color,
+// for the box that has the keys.
+func (s Styles) Focused(style lipgloss.Style) lipgloss.Style {
+	return style.Copy().BorderStyle(lipgloss.ThickBorder()).BorderForeground(s.Highlight.GetForeground())
+}
+
+// Alert renders a warning with a hint under it, e.g. what to do about it.
+// An empty warning renders as nothing.
+func (s Styles) Alert(warning, hint string) string {
+	if warning == "" {
+		return ""
+	}
+	return s.Warning.Render(warning) + "\n" + s.Subtle.Render(hint)
+}
+
+// plain drops the colors of a bubbles default style in mono themes.
+func (s Styles) plain(style lipgloss.Style) lipgloss.Style {
+	if !s.mono {
+		return style
+	}
+	return style.UnsetForeground().UnsetBackground().UnsetBorderForeground().UnsetBorderBackground()
+}
+
+// Table returns the bubbles table styles in the theme's colors.
+func (s Styles) Table() table.Styles {
+	styles := table.DefaultStyles()
+	styles.Header = s.plain(styles.Header)
+	styles.Selected = s.Selected.Copy().Bold(true)
+	return styles
+}
+
+// Help returns the bubbles help styles in the theme's colors.
+func (s Styles) Help() help.Styles {
+	styles := help.New().Styles
+	styles.ShortKey, styles.FullKey = s.HelpKey, s.HelpKey
+	styles.ShortDesc, styles.FullDesc = s.HelpDesc, s.HelpDesc
+	styles.ShortSeparator = s.plain(styles.ShortSeparator)
+	styles.FullSeparator = s.plain(styles.FullSeparator)
+	styles.Ellipsis = s.plain(styles.Ellipsis)
+	return styles
+}
+
+// List returns the bubbles list styles in the theme's colors.
+func (s Styles) List() list.Styles {
+	styles := list.DefaultStyles()
+	styles.Title = s.Title
+	styles.FilterPrompt = s.Prompt
+	styles.FilterCursor = s.Highlight
+	styles.StatusBar = s.plain(styles.StatusBar)
+	styles.StatusEmpty = s.Subtle
+	styles.StatusBarActiveFilter = s.Text
+	styles.StatusBarFilterCount = s.Subtle
+	styles.NoItems = s.Subtle
+	styles.ActivePaginationDot = s.plain(styles.ActivePaginationDot)
+	styles.InactivePaginationDot = s.plain(styles.InactivePaginationDot)
+	styles.DividerDot = s.plain(styles.DividerDot)
+	styles.ArabicPagination = s.plain(styles.ArabicPagination)
+	return styles
+}
+
+// ListItems returns the bubbles list item styles in the theme's colors.
+func (s Styles) ListItems() list.DefaultItemStyles {
+	styles := list.NewDefaultItemStyles()
+	styles.NormalTitle = s.plain(styles.NormalTitle)
+	styles.NormalDesc = s.plain(styles.NormalDesc)
+	styles.SelectedTitle = styles.SelectedTitle.BorderForeground(s.Border).Foreground(s.Highlight.GetForeground()).Bold(s.mono)
+	styles.SelectedDesc = styles.SelectedDesc.BorderForeground(s.Border).Foreground(s.Highlight.GetForeground())
+	styles.DimmedTitle = s.plain(styles.DimmedTitle)
+	styles.DimmedDesc = s.plain(styles.DimmedDesc)
+	return styles
+}
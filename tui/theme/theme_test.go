@@ -0,0 +1,51 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLoad(t *testing.T) {
+	t.Cleanup(func() { Current = themes[0].Styles() })
+	if err := Load("light"); err != nil {
+		t.Fatal(err)
+	}
+	if Current.Selected.GetReverse() {
+		t.Error("light theme selects with reverse video")
+	}
+	err := Load("solarized")
+	if err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("unknown theme error does not list the known ones: %v", err)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	t.Cleanup(func() { Current = themes[0].Styles() })
+	t.Setenv("NO_COLOR", "1")
+	if err := Load("high-contrast"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Current.OK.GetForeground().(lipgloss.NoColor); !ok {
+		t.Error("no-color theme has colors")
+	}
+	if !Current.Selected.GetReverse() || !Current.Title.GetReverse() {
+		t.Error("no-color theme does not mark selections and titles with reverse video")
+	}
+}
+
+// TestStatesDiffer guards that healthy, warning and failed states never
+// share a color, which would leave only the text to tell them apart.
+func TestStatesDiffer(t *testing.T) {
+	for _, theme := range themes {
+		if theme.Mono {
+			continue
+		}
+		for _, p := range []Palette{theme.Light, theme.Dark} {
+			if p.OK == p.Warning || p.OK == p.Failed || p.Warning == p.Failed {
+				t.Errorf("%s: states share a color: %s %s %s", theme.Name, p.OK, p.Warning, p.Failed)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package zone hit-tests views composed with lipgloss. A view marks the
+// parts it wants to find again, like a box or a table row, with Mark. The
+// marks take no room, so the view is laid out as without them, and Scan
+// finds where each marked part ended up in the composed view. Clean strips
+// the marks before the view goes to the terminal.
+package zone
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// marks are CSI sequences ending in z, which the terminal would ignore and
+// lipgloss and the reflow packages measure as zero width: "\x1b[1;<id>z"
+// opens a zone, "\x1b[2;<id>z" closes it. The ID is spelled out as its
+// bytes in decimal, letters would end the sequence.
+const (
+	csi       = "\x1b["
+	openMark  = '1'
+	closeMark = '2'
+)
+
+// ID names a zone of kind, like "task", for key, like the task's ARN.
+func ID(kind, key string) string {
+	return kind + ":" + key
+}
+
+// Mark marks every line of s as part of the zone id, so the zone follows the
+// lines wherever lipgloss joins and pads them, and a viewport showing only
+// some of them keeps the zone on those.
+func Mark(id, s string) string {
+	start, end := mark(openMark, id), mark(closeMark, id)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = start + line + end
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mark(kind byte, id string) string {
+	var b strings.Builder
+	b.WriteString(csi)
+	b.WriteByte(kind)
+	for i := 0; i < len(id); i++ {
+		b.WriteByte(';')
+		b.WriteString(strconv.Itoa(int(id[i])))
+	}
+	b.WriteByte('z')
+	return b.String()
+}
+
+// Zone is the part of a line a marked part takes, from X up to EndX.
+type Zone struct {
+	ID      string
+	X, EndX int
+	Y       int
+}
+
+// Key returns the key of a zone of kind, false for zones of other kinds.
+func (z Zone) Key(kind string) (string, bool) {
+	return strings.CutPrefix(z.ID, kind+":")
+}
+
+// Zones are the zones of a view, one per line of each marked part.
+type Zones []Zone
+
+// At lists the zones at the cell x, y, the innermost first, e.g. a table
+// row before the box holding it.
+func (zs Zones) At(x, y int) Zones {
+	hits := Zones{}
+	for _, z := range zs {
+		if z.Y == y && z.X <= x && x < z.EndX {
+			hits = append(hits, z)
+		}
+	}
+	// nested zones are narrower, or as wide and closed first
+	for i := 1; i < len(hits); i++ {
+		for j := i; j > 0 && hits[j].EndX-hits[j].X < hits[j-1].EndX-hits[j-1].X; j-- {
+			hits[j], hits[j-1] = hits[j-1], hits[j]
+		}
+	}
+	return hits
+}
+
+// Key returns the key of the innermost zone of kind, false if there is none.
+func (zs Zones) Key(kind string) (string, bool) {
+	for _, z := range zs {
+		if key, ok := z.Key(kind); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Scan finds the marked parts of view. A mark left open at the end of a
+// line, because the text was wrapped after it was marked, carries on from
+// the start of the next one.
+func Scan(view string) Zones {
+	type opened struct {
+		id string
+		x  int
+	}
+	zones := Zones{}
+	stack := []opened{}
+	for y, line := range strings.Split(view, "\n") {
+		x := 0
+		for i := 0; i < len(line); {
+			if kind, id, n, ok := parse(line[i:]); ok {
+				switch kind {
+				case openMark:
+					stack = append(stack, opened{id, x})
+				case closeMark:
+					for j := len(stack) - 1; j >= 0; j-- {
+						if stack[j].id == id {
+							zones = append(zones, Zone{ID: id, X: stack[j].x, EndX: x, Y: y})
+							stack = append(stack[:j], stack[j+1:]...)
+							break
+						}
+					}
+				}
+				i += n
+				continue
+			}
+			if n := sequence(line[i:]); n > 0 {
+				i += n
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			x += runewidth.RuneWidth(r)
+			i += size
+		}
+		for j := range stack {
+			if stack[j].x < x {
+				zones = append(zones, Zone{ID: stack[j].id, X: stack[j].x, EndX: x, Y: y})
+			}
+			stack[j].x = 0
+		}
+	}
+	return zones
+}
+
+// Clean strips the marks from view.
+func Clean(view string) string {
+	if !strings.Contains(view, csi) {
+		return view
+	}
+	var b strings.Builder
+	for i := 0; i < len(view); {
+		if _, _, n, ok := parse(view[i:]); ok {
+			i += n
+			continue
+		}
+		b.WriteByte(view[i])
+		i++
+	}
+	return b.String()
+}
+
+// parse reads the mark s starts with, returning its kind, ID and length.
+func parse(s string) (kind byte, id string, n int, ok bool) {
+	if !strings.HasPrefix(s, csi) || len(s) < 4 || (s[2] != openMark && s[2] != closeMark) || (s[3] != ';' && s[3] != 'z') {
+		return 0, "", 0, false
+	}
+	n = sequence(s)
+	if s[n-1] != 'z' {
+		return 0, "", 0, false
+	}
+	var b strings.Builder
+	for _, code := range strings.Split(s[3:n-1], ";")[1:] {
+		c, err := strconv.Atoi(code)
+		if err != nil || c > 255 {
+			return 0, "", 0, false
+		}
+		b.WriteByte(byte(c))
+	}
+	return s[2], b.String(), n, true
+}
+
+// sequence returns the length of the ANSI sequence s starts with, 0 if it
+// does not start with one. Sequences end with a letter.
+func sequence(s string) int {
+	if s[0] != '\x1b' {
+		return 0
+	}
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,69 @@
+package zone
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestScan(t *testing.T) {
+	box := lipgloss.NewStyle().Width(10).Height(3).BorderStyle(lipgloss.NormalBorder())
+	row := Mark(ID("row", "b1"), "row")
+	left := Mark(ID("box", "a"), box.Render("a"))
+	right := Mark(ID("box", "b"), box.Render("b\n"+row))
+	view := lipgloss.NewStyle().Width(40).AlignHorizontal(lipgloss.Center).
+		Render(lipgloss.JoinVertical(lipgloss.Center, "title", lipgloss.JoinHorizontal(lipgloss.Top, left, " ", right)))
+	zones := Scan(view)
+
+	if strings.Contains(Clean(view), csi) {
+		t.Errorf("Clean left marks in %q", Clean(view))
+	}
+	if got, want := lipgloss.Width(view), 40; got != want {
+		t.Errorf("marked view is %d wide, want %d", got, want)
+	}
+	cases := []struct {
+		x, y int
+		want []string
+	}{
+		{20, 0, nil},
+		{7, 1, []string{"box:a"}},
+		{18, 3, []string{"box:a"}},
+		{19, 3, nil},
+		{20, 3, []string{"box:b"}},
+		{21, 3, []string{"row:b1", "box:b"}},
+		{31, 5, []string{"box:b"}},
+		{32, 5, nil},
+	}
+	for _, c := range cases {
+		got := []string{}
+		for _, z := range zones.At(c.x, c.y) {
+			got = append(got, z.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("zones at %d,%d = %v, want %v\n%s", c.x, c.y, got, c.want, Clean(view))
+		}
+	}
+	if key, ok := zones.At(21, 3).Key("box"); !ok || key != "b" {
+		t.Errorf("box at 21,3 = %q, want b", key)
+	}
+}
+
+func TestScanClipped(t *testing.T) {
+	lines := strings.Split(Mark("box", "one\ntwo\nthree"), "\n")
+	zones := Scan(strings.Join(lines[1:], "\n")) // scrolled past the first line
+	if len(zones.At(1, 0)) != 1 || len(zones.At(1, 1)) != 1 {
+		t.Errorf("zones of the lines left = %v", zones)
+	}
+}
+
+func TestScanWrapped(t *testing.T) {
+	view := lipgloss.NewStyle().Width(10).Render("press " + Mark("key", "ctrl+e events"))
+	zones := Scan(view)
+	if len(zones.At(2, 1)) != 1 || len(zones.At(2, 2)) != 1 {
+		t.Errorf("wrapped zone = %v in\n%s", zones, Clean(view))
+	}
+	if len(zones.At(2, 0)) != 0 {
+		t.Errorf("zone starts before its text: %v", zones)
+	}
+}
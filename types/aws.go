@@ -1,8 +1,33 @@
 package types
 
 import (
+	"time"
+
+	autoscaling "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/servicediscovery"
+)
+
+const (
+	SectionImages      = "images"
+	SectionTasks       = "tasks"
+	SectionConnections = "connections"
+	SectionInstances   = "container instances"
+
+	SectionPolicies         = "policies"
+	SectionScheduledActions = "scheduled actions"
+	SectionActivities       = "activities"
+	SectionAlarms           = "alarms"
+
+	SectionCluster           = "cluster"
+	SectionCapacityProviders = "capacity providers"
+
+	SectionSubnets          = "subnets"
+	SectionSecurityGroups   = "security groups"
+	SectionServiceDiscovery = "service discovery"
 )
 
 type ServiceScale struct {
@@ -14,10 +39,15 @@ type ConnectionConfig struct {
 	TaskSetID    string
 	LBName       string
 	TGName       string
+	TGArn        string
 	TGWeigth     int64
 	ListenerPort int64
 	Priority     string
 	TGHealth     []*elbv2.TargetHealthDescription
+	// ListenerArn and Rule are set for connections made by a listener rule,
+	// weights are shifted by modifying them.
+	ListenerArn string
+	Rule        *elbv2.Rule
 }
 type ServiceStatus struct {
 	Ecs    *ecs.Service
@@ -25,16 +55,141 @@ type ServiceStatus struct {
 	Images []string
 }
 
+// SectionError records a single section of a status response that failed to load.
+type SectionError struct {
+	Section string
+	Err     error
+}
+
+// SectionErrors groups failed sections by task set or deployment id.
+type SectionErrors map[string][]SectionError
+
+func (e SectionErrors) Add(id, section string, err error) {
+	e[id] = append(e[id], SectionError{Section: section, Err: err})
+}
+
+func (e SectionErrors) Get(id, section string) error {
+	return SectionErrorList(e[id]).Get(section)
+}
+
+// SectionErrorList lists the failed sections of a response about a single
+// resource.
+type SectionErrorList []SectionError
+
+func (l SectionErrorList) Get(section string) error {
+	for _, sectionErr := range l {
+		if sectionErr.Section == section {
+			return sectionErr.Err
+		}
+	}
+	return nil
+}
+
 type TaskSetStatus struct {
 	TaskSetImages      map[string][]string
 	TaskSetConnections map[string][]ConnectionConfig
 	TaskSetTasks       map[string][]*ecs.Task
+	// InstanceIDs maps the container instances of EC2 tasks to their EC2
+	// instance IDs, the targets bridge and host mode tasks register as.
+	InstanceIDs map[string]string
+	Errors      SectionErrors
 }
 type DeploymentStatus struct {
 	DeploymentImages      map[string][]string
 	DeploymentConnections []ConnectionConfig
 	DeploymentTasks       map[string][]*ecs.Task
+	// InstanceIDs maps the container instances of EC2 tasks to their EC2
+	// instance IDs, the targets bridge and host mode tasks register as.
+	InstanceIDs    map[string]string
+	Errors         SectionErrors
+	ConnectionsErr error
+}
+
+// ImageDetails describes the image of one container, both as referenced by
+// the task definition and as resolved by the running tasks.
+type ImageDetails struct {
+	Container string
+	Image     string
+	// Digests maps every digest the tasks resolved the image to, to the ids
+	// of the tasks running it.
+	Digests map[string][]string
+	ECR     []ECRImageDetails
+	ECRErr  error
+}
+
+// ECRImageDetails is the registry metadata of an image stored in ECR.
+type ECRImageDetails struct {
+	Digest     string
+	Tags       []string
+	PushedAt   time.Time
+	SizeBytes  int64
+	ScanStatus string
+	Findings   map[string]int64
+	// Missing is set for digests the repository no longer has, e.g. expired
+	// by a lifecycle policy, the other fields are empty then.
+	Missing bool
+}
+
+// ScalingDetails is the Application Auto Scaling configuration of a service
+// and what it did recently. Sections that failed to load are listed in
+// Errors, the others are still filled in.
+type ScalingDetails struct {
+	Target           *autoscaling.ScalableTarget
+	Policies         []*autoscaling.ScalingPolicy
+	ScheduledActions []*autoscaling.ScheduledAction
+	Activities       []*autoscaling.ScalingActivity
+	// Alarms are the CloudWatch alarms backing the policies, by name.
+	Alarms map[string]*cloudwatch.MetricAlarm
+	Errors SectionErrorList
+}
+
+// PlacementDetails is where the tasks of a service run compared to where
+// its capacity provider strategy wants them.
+type PlacementDetails struct {
+	// Cluster carries the cluster's capacity providers and default strategy.
+	Cluster           *ecs.Cluster
+	CapacityProviders []*ecs.CapacityProvider
+	Tasks             []*ecs.Task
+	Errors            SectionErrorList
+}
+
+type ScalingDetailsFetcher func(cluster, service string) (*ScalingDetails, error)
+
+// NetworkDetails resolves the subnets, security groups and Cloud Map
+// registries a service is configured with.
+type NetworkDetails struct {
+	Subnets        []*ec2.Subnet
+	SecurityGroups []*ec2.SecurityGroup
+	Registries     []ServiceRegistryDetails
+	Errors         SectionErrorList
+}
+
+// ServiceRegistryDetails is a Cloud Map service the tasks register into,
+// with its registered instances and their health.
+type ServiceRegistryDetails struct {
+	RegistryArn string
+	Service     *servicediscovery.Service
+	Namespace   *servicediscovery.Namespace
+	Instances   []*servicediscovery.InstanceSummary
+	// Health maps instance ids to their health status.
+	Health map[string]string
+}
+
+// TargetGroupDetails is a target group with its health check configuration
+// and the current health of every registered target.
+type TargetGroupDetails struct {
+	TargetGroup *elbv2.TargetGroup
+	Health      []*elbv2.TargetHealthDescription
 }
 
+type TargetGroupDetailsFetcher func(targetGroupArn string) (*TargetGroupDetails, error)
+type NetworkDetailsFetcher func(service *ecs.Service) (*NetworkDetails, error)
+type ContainerInstancesFetcher func(cluster string) ([]*ecs.ContainerInstance, error)
+type InstanceTasksFetcher func(cluster, containerInstanceArn string) ([]*ecs.Task, error)
+type ServiceTasksFetcher func(cluster, service string) ([]*ecs.Task, error)
+type PlacementDetailsFetcher func(cluster, service string) (*PlacementDetails, error)
+type ImageDetailsFetcher func(taskDefinitionArn string, tasks []*ecs.Task) ([]ImageDetails, error)
+type TaskDefinitionFetcher func(taskDefinitionArn string) (*ecs.TaskDefinition, error)
+type TaskDefinitionRevisionsFetcher func(family string) ([]string, error)
 type TaskSetStatusFetcher func(cluster, service string, taskSets []*ecs.TaskSet) (*TaskSetStatus, error)
 type DeploymentStatusFetcher func(cluster, service string, deployments []*ecs.Deployment, loadBalancers []*ecs.LoadBalancer) (*DeploymentStatus, error)
@@ -0,0 +1,99 @@
+package utils
+
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines computes a line based diff from a to b using the longest common
+// subsequence. Inputs are small (task definitions render to a few hundred
+// lines at most), so the quadratic table is fine.
+func DiffLines(a, b []string) []DiffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{DiffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{DiffDelete, a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{DiffInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{DiffDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{DiffInsert, b[j]})
+	}
+	return lines
+}
+
+// DiffRow is one row of a side by side diff. Left or Right is nil when the
+// line only exists on the other side.
+type DiffRow struct {
+	Left, Right *string
+}
+
+func (r DiffRow) Changed() bool {
+	return r.Left == nil || r.Right == nil || *r.Left != *r.Right
+}
+
+// SideBySide pairs the deletions and insertions of a diff into rows, so a
+// changed line is shown next to its replacement.
+func SideBySide(lines []DiffLine) []DiffRow {
+	var rows []DiffRow
+	for i := 0; i < len(lines); {
+		if lines[i].Op == DiffEqual {
+			text := lines[i].Text
+			rows = append(rows, DiffRow{&text, &text})
+			i++
+			continue
+		}
+		var deleted, inserted []string
+		for ; i < len(lines) && lines[i].Op == DiffDelete; i++ {
+			deleted = append(deleted, lines[i].Text)
+		}
+		for ; i < len(lines) && lines[i].Op == DiffInsert; i++ {
+			inserted = append(inserted, lines[i].Text)
+		}
+		for k := 0; k < max(len(deleted), len(inserted)); k++ {
+			row := DiffRow{}
+			if k < len(deleted) {
+				row.Left = &deleted[k]
+			}
+			if k < len(inserted) {
+				row.Right = &inserted[k]
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a, b []string
+		want []DiffLine
+	}{
+		{
+			name: "equal",
+			a:    []string{"x", "y"},
+			b:    []string{"x", "y"},
+			want: []DiffLine{{DiffEqual, "x"}, {DiffEqual, "y"}},
+		},
+		{
+			name: "insert",
+			a:    []string{"x", "z"},
+			b:    []string{"x", "y", "z"},
+			want: []DiffLine{{DiffEqual, "x"}, {DiffInsert, "y"}, {DiffEqual, "z"}},
+		},
+		{
+			name: "delete",
+			a:    []string{"x", "y", "z"},
+			b:    []string{"x", "z"},
+			want: []DiffLine{{DiffEqual, "x"}, {DiffDelete, "y"}, {DiffEqual, "z"}},
+		},
+		{
+			name: "replace",
+			a:    []string{"x", "image: web:1", "z"},
+			b:    []string{"x", "image: web:2", "z"},
+			want: []DiffLine{{DiffEqual, "x"}, {DiffDelete, "image: web:1"}, {DiffInsert, "image: web:2"}, {DiffEqual, "z"}},
+		},
+		{
+			name: "from nothing",
+			b:    []string{"x", "y"},
+			want: []DiffLine{{DiffInsert, "x"}, {DiffInsert, "y"}},
+		},
+		{
+			name: "to nothing",
+			a:    []string{"x", "y"},
+			want: []DiffLine{{DiffDelete, "x"}, {DiffDelete, "y"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DiffLines(tc.a, tc.b); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DiffLines = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// rowString renders a row as "left|right", "-" standing for a missing side.
+func rowString(row DiffRow) string {
+	side := func(s *string) string {
+		if s == nil {
+			return "-"
+		}
+		return *s
+	}
+	return side(row.Left) + "|" + side(row.Right)
+}
+
+func TestSideBySide(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		a, b    []string
+		want    []string
+		changed []bool
+	}{
+		{
+			name:    "insert",
+			a:       []string{"x", "z"},
+			b:       []string{"x", "y", "z"},
+			want:    []string{"x|x", "-|y", "z|z"},
+			changed: []bool{false, true, false},
+		},
+		{
+			name:    "delete",
+			a:       []string{"x", "y", "z"},
+			b:       []string{"x", "z"},
+			want:    []string{"x|x", "y|-", "z|z"},
+			changed: []bool{false, true, false},
+		},
+		{
+			name:    "replace pairs the lines",
+			a:       []string{"x", "cpu: 256", "memory: 512", "z"},
+			b:       []string{"x", "cpu: 512", "memory: 1024", "z"},
+			want:    []string{"x|x", "cpu: 256|cpu: 512", "memory: 512|memory: 1024", "z|z"},
+			changed: []bool{false, true, true, false},
+		},
+		{
+			name:    "replace with more lines",
+			a:       []string{"x", "a"},
+			b:       []string{"x", "b", "c"},
+			want:    []string{"x|x", "a|b", "-|c"},
+			changed: []bool{false, true, true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := SideBySide(DiffLines(tc.a, tc.b))
+			got := make([]string, len(rows))
+			changed := make([]bool, len(rows))
+			for i, row := range rows {
+				got[i] = rowString(row)
+				changed[i] = row.Changed()
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("rows = %s, want %s", strings.Join(got, " "), strings.Join(tc.want, " "))
+			}
+			if !reflect.DeepEqual(changed, tc.changed) {
+				t.Errorf("changed = %v, want %v", changed, tc.changed)
+			}
+		})
+	}
+}
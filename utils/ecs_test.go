@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestTaskStatusLabel(t *testing.T) {
+	for status, want := range map[string]string{
+		"RUNNING":        "↑RUNNING",
+		"PROVISIONING":   "↑PROVISIONING",
+		"PENDING":        "↑PENDING",
+		"ACTIVATING":     "↑ACTIVATING",
+		"DEACTIVATING":   "↓DEACTIVATING",
+		"STOPPING":       "↓STOPPING",
+		"DEPROVISIONING": "↓DEPROVISIONING",
+		"STOPPED":        "↓STOPPED",
+		"UNKNOWN":        "UNKNOWN",
+	} {
+		if got := TaskStatusLabel(status); got != want {
+			t.Errorf("TaskStatusLabel(%s) = %q, want %q", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetryHint tells which key, refreshKey, loads a failed view again.
+func RetryHint(refreshKey string) string {
+	return refreshKey + " retry"
+}
+
+// SectionErrorText describes a section that failed to load in a warning and
+// a hint under it naming refreshKey, both truncated to width. A non-zero
+// staleSince means older data for the section is still displayed, the hint
+// then gives the time it was loaded.
+func SectionErrorText(section string, err error, staleSince time.Time, refreshKey string, width int) (warning, hint string) {
+	message := strings.SplitN(err.Error(), "\n", 2)[0]
+	warning = truncate(fmt.Sprintf("⚠ %s: %s", section, message), width)
+	if staleSince.IsZero() {
+		return warning, truncate(RetryHint(refreshKey), width)
+	}
+	return warning, truncate(fmt.Sprintf("stale since %s (%s)", staleSince.Format("15:04:05"), refreshKey), width)
+}
+
+// StaleBannerText is a one line marker for a view whose last refresh failed
+// while data from an earlier refresh is still shown.
+func StaleBannerText(err error, staleSince time.Time, refreshKey string, width int) string {
+	message := strings.SplitN(err.Error(), "\n", 2)[0]
+	return truncate(fmt.Sprintf("⚠ refresh failed, stale since %s (%s): %s", staleSince.Format("15:04:05"), RetryHint(refreshKey), message), width)
+}
+
+func truncate(s string, max int) string {
+	if max <= 1 || len([]rune(s)) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
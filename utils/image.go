@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+var ecrImagePattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/([^:@]+)(?::([^@]+))?(?:@(sha256:[a-f0-9]+))?$`)
+
+// ECRImage is an image reference pointing to a private ECR repository.
+type ECRImage struct {
+	RegistryID string
+	Region     string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseECRImage parses a private ECR image reference, e.g.
+// 123456789012.dkr.ecr.eu-west-1.amazonaws.com/app:v1. ok is false for
+// images hosted anywhere else.
+func ParseECRImage(image string) (ECRImage, bool) {
+	match := ecrImagePattern.FindStringSubmatch(image)
+	if match == nil {
+		return ECRImage{}, false
+	}
+	return ECRImage{
+		RegistryID: match[1],
+		Region:     match[2],
+		Repository: match[3],
+		Tag:        match[4],
+		Digest:     match[5],
+	}, true
+}
+
+// DigestsByContainer groups the short ids of tasks by the image digest each
+// container resolved to, keyed by container name.
+func DigestsByContainer(tasks []*ecs.Task) map[string]map[string][]string {
+	digests := make(map[string]map[string][]string)
+	for _, task := range tasks {
+		taskID := GetLastItemAfterSplit(aws.StringValue(task.TaskArn), "/")
+		for _, container := range task.Containers {
+			if container.ImageDigest == nil {
+				continue
+			}
+			name := aws.StringValue(container.Name)
+			if _, ok := digests[name]; !ok {
+				digests[name] = make(map[string][]string)
+			}
+			digests[name][*container.ImageDigest] = append(digests[name][*container.ImageDigest], taskID)
+		}
+	}
+	for _, byDigest := range digests {
+		for digest := range byDigest {
+			slices.Sort(byDigest[digest])
+		}
+	}
+	return digests
+}
+
+// DigestMismatches returns the containers whose tasks run more than one
+// digest, which happens when a mutable tag is pushed during a deployment.
+func DigestMismatches(tasks []*ecs.Task) []string {
+	mismatches := []string{}
+	for container, byDigest := range DigestsByContainer(tasks) {
+		if len(byDigest) > 1 {
+			mismatches = append(mismatches, container)
+		}
+	}
+	slices.Sort(mismatches)
+	return mismatches
+}
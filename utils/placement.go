@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"slices"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// ExpectedDistribution splits total tasks over a capacity provider strategy
+// the way ECS does: the base of a provider is satisfied first, the rest is
+// shared by weight. Rounding leftovers go to the heaviest providers.
+func ExpectedDistribution(strategy []*ecs.CapacityProviderStrategyItem, total int) map[string]int {
+	expected := make(map[string]int)
+	remaining := total
+	weights := 0
+	for _, item := range strategy {
+		provider := aws.StringValue(item.CapacityProvider)
+		base := min(int(aws.Int64Value(item.Base)), remaining)
+		expected[provider] = base
+		remaining -= base
+		weights += int(aws.Int64Value(item.Weight))
+	}
+	if weights == 0 || remaining == 0 {
+		return expected
+	}
+
+	byWeight := slices.Clone(strategy)
+	slices.SortStableFunc(byWeight, func(i, j *ecs.CapacityProviderStrategyItem) int {
+		return int(aws.Int64Value(j.Weight) - aws.Int64Value(i.Weight))
+	})
+	assigned, weighted := 0, 0
+	for _, item := range byWeight {
+		if aws.Int64Value(item.Weight) > 0 {
+			weighted++
+		}
+		share := remaining * int(aws.Int64Value(item.Weight)) / weights
+		expected[aws.StringValue(item.CapacityProvider)] += share
+		assigned += share
+	}
+	for i := 0; assigned < remaining; i++ {
+		expected[aws.StringValue(byWeight[i%weighted].CapacityProvider)]++
+		assigned++
+	}
+	return expected
+}
+
+// TaskCapacity is the capacity provider a task was placed on, or its launch
+// type for services that do not use capacity providers.
+func TaskCapacity(task *ecs.Task) string {
+	if task.CapacityProviderName != nil {
+		return *task.CapacityProviderName
+	}
+	return aws.StringValue(task.LaunchType)
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/mtyurt/ecstui/types"
+)
+
+// StaleSections maps the sections of task sets or deployments that failed to
+// load, and show the data of an earlier refresh instead, to the time that
+// data was loaded.
+type StaleSections map[string]time.Time
+
+func staleKey(id, section string) string {
+	return id + "/" + section
+}
+
+// Since returns when the data shown for section of id was loaded, zero if
+// the section is not stale.
+func (s StaleSections) Since(id, section string) time.Time {
+	return s[staleKey(id, section)]
+}
+
+// Warning describes section of id, err being why it failed to load, as
+// SectionErrorText does. Both are empty if the section loaded.
+func (s StaleSections) Warning(err error, id, section, refreshKey string, width int) (warning, hint string) {
+	if err == nil {
+		return "", ""
+	}
+	return SectionErrorText(section, err, s.Since(id, section), refreshKey, width)
+}
+
+// SectionMerge applies a refresh to the data of the previous one: sections
+// that failed to load keep their previous data and are marked stale since it
+// was loaded.
+type SectionMerge struct {
+	errors   types.SectionErrors
+	previous StaleSections
+	loadedAt time.Time
+	// Stale are the sections showing earlier data once merged.
+	Stale StaleSections
+}
+
+// NewSectionMerge merges a refresh that failed to load the sections in errors
+// into the previous data, loaded at loadedAt with the stale sections stale.
+func NewSectionMerge(errors types.SectionErrors, stale StaleSections, loadedAt time.Time) *SectionMerge {
+	return &SectionMerge{errors: errors, previous: stale, loadedAt: loadedAt, Stale: StaleSections{}}
+}
+
+// Failed reports whether section of id failed to load.
+func (m *SectionMerge) Failed(id, section string) bool {
+	return m.errors.Get(id, section) != nil
+}
+
+// Keep marks section of id as showing the previous data. A section that was
+// stale already stays stale since the same time.
+func (m *SectionMerge) Keep(id, section string) {
+	key := staleKey(id, section)
+	if since, ok := m.previous[key]; ok {
+		m.Stale[key] = since
+	} else {
+		m.Stale[key] = m.loadedAt
+	}
+}
+
+// KeepSection copies the previous value of id into next if section of id
+// failed to load and there is one.
+func KeepSection[V any](m *SectionMerge, id, section string, next, previous map[string]V) {
+	if !m.Failed(id, section) {
+		return
+	}
+	if value, ok := previous[id]; ok {
+		next[id] = value
+		m.Keep(id, section)
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mtyurt/ecstui/types"
+)
+
+func TestSectionMerge(t *testing.T) {
+	loadedAt := time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC)
+	earlier := loadedAt.Add(-time.Minute)
+	errs := types.SectionErrors{}
+	throttled := errors.New("ThrottlingException: Rate exceeded")
+	errs.Add("blue", types.SectionImages, throttled)
+	errs.Add("green", types.SectionImages, throttled)
+	errs.Add("new", types.SectionImages, throttled)
+
+	// green failed the refresh before too
+	merge := NewSectionMerge(errs, StaleSections{staleKey("green", types.SectionImages): earlier}, loadedAt)
+	previous := map[string][]string{"blue": {"web:1"}, "green": {"web:2"}, "other": {"web:3"}}
+	next := map[string][]string{"other": {"web:4"}}
+	for _, id := range []string{"blue", "green", "new", "other"} {
+		KeepSection(merge, id, types.SectionImages, next, previous)
+	}
+
+	// failed sections keep their data, the ones without any stay empty
+	want := map[string][]string{"blue": {"web:1"}, "green": {"web:2"}, "other": {"web:4"}}
+	if !reflect.DeepEqual(next, want) {
+		t.Errorf("merged = %v, want %v", next, want)
+	}
+	for id, since := range map[string]time.Time{"blue": loadedAt, "green": earlier, "new": {}, "other": {}} {
+		if got := merge.Stale.Since(id, types.SectionImages); !got.Equal(since) {
+			t.Errorf("%s stale since %v, want %v", id, got, since)
+		}
+	}
+	if warning, hint := merge.Stale.Warning(nil, "other", types.SectionImages, "ctrl+r", 80); warning != "" || hint != "" {
+		t.Errorf("warning of a loaded section = %q %q, want none", warning, hint)
+	}
+	warning, hint := merge.Stale.Warning(throttled, "green", types.SectionImages, "ctrl+r", 80)
+	if warning != "⚠ images: ThrottlingException: Rate exceeded" || hint != "stale since 08:59:00 (ctrl+r)" {
+		t.Errorf("warning of a stale section = %q %q", warning, hint)
+	}
+	warning, hint = merge.Stale.Warning(throttled, "new", types.SectionImages, "f5", 20)
+	if warning != "⚠ images: Throttlin…" || hint != "f5 retry" {
+		t.Errorf("warning of a section without data = %q %q", warning, hint)
+	}
+}
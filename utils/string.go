@@ -1,6 +1,9 @@
 package utils
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 func GetLastItemAfterSplit(str, separator string) string {
 	split := strings.Split(str, separator)
@@ -15,3 +18,12 @@ func JoinImageNames(images []string) string {
 
 	return strings.TrimSuffix(response, "\n")
 }
+
+// SplitTaskDefinitionArn returns the family and revision of a task definition
+// ARN or "family:revision" string. The revision is 0 when it is missing.
+func SplitTaskDefinitionArn(arn string) (string, int64) {
+	familyRevision := GetLastItemAfterSplit(arn, "/")
+	family, revision, _ := strings.Cut(familyRevision, ":")
+	rev, _ := strconv.ParseInt(revision, 10, 64)
+	return family, rev
+}
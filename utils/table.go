@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	reflowtruncate "github.com/muesli/reflow/truncate"
+)
+
+var tableHeaderStyle = lipgloss.NewStyle().Bold(true)
+
+// RenderTable renders a static table with the layout of bubbles' table.
+// bubbles truncates cells by their length in bytes, which cuts styled values
+// such as status labels in the middle of their escape sequences; here values
+// are truncated and padded by their visible width instead.
+func RenderTable(columns []table.Column, rows []table.Row) string {
+	lines := make([]string, 0, len(rows)+1)
+	header := make([]string, 0, len(columns))
+	for _, col := range columns {
+		header = append(header, tableHeaderStyle.Render(tableCell(col.Title, col.Width)))
+	}
+	lines = append(lines, strings.Join(header, ""))
+	for _, row := range rows {
+		cells := make([]string, 0, len(columns))
+		for i, col := range columns {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			cells = append(cells, tableCell(value, col.Width))
+		}
+		lines = append(lines, strings.Join(cells, ""))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableCell fits value into width columns, with a space of padding on both
+// sides.
+func tableCell(value string, width int) string {
+	value = reflowtruncate.StringWithTail(value, uint(width), "…")
+	return " " + value + strings.Repeat(" ", width-lipgloss.Width(value)) + " "
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// NotRegistered is the target health of a task none of the target groups
+// list as a target.
+const NotRegistered = "not registered"
+
+// TaskPrivateIP is the address of the task's ENI, set for awsvpc tasks.
+func TaskPrivateIP(task *ecs.Task) string {
+	for _, attachment := range task.Attachments {
+		for _, detail := range attachment.Details {
+			if aws.StringValue(detail.Name) == "privateIPv4Address" {
+				return aws.StringValue(detail.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// TargetMatcher finds the task behind a load balancer target.
+type TargetMatcher struct {
+	byIP       map[string]*ecs.Task
+	byHostPort map[string]*ecs.Task
+}
+
+// NewTargetMatcher indexes tasks by the addresses they are registered with.
+// instanceIDs maps container instance ARNs to EC2 instance IDs, it is needed
+// to match bridge and host mode tasks and may be nil on Fargate.
+func NewTargetMatcher(tasks []*ecs.Task, instanceIDs map[string]string) TargetMatcher {
+	byIP := make(map[string]*ecs.Task)
+	byHostPort := make(map[string]*ecs.Task)
+	for _, task := range tasks {
+		if ip := TaskPrivateIP(task); ip != "" {
+			byIP[ip] = task
+		}
+		instanceID := instanceIDs[aws.StringValue(task.ContainerInstanceArn)]
+		if instanceID == "" {
+			continue
+		}
+		for _, c := range task.Containers {
+			for _, b := range c.NetworkBindings {
+				byHostPort[hostPortKey(instanceID, aws.Int64Value(b.HostPort))] = task
+			}
+		}
+	}
+	return TargetMatcher{byIP: byIP, byHostPort: byHostPort}
+}
+
+func hostPortKey(instanceID string, port int64) string {
+	return fmt.Sprintf("%s:%d", instanceID, port)
+}
+
+// Task returns the task registered as target, or nil if none of the tasks
+// match it. awsvpc tasks are registered by the private IP of their ENI,
+// bridge and host mode tasks by their instance and host port.
+func (m TargetMatcher) Task(target *elbv2.TargetDescription) *ecs.Task {
+	if task, ok := m.byIP[aws.StringValue(target.Id)]; ok {
+		return task
+	}
+	return m.byHostPort[hostPortKey(aws.StringValue(target.Id), aws.Int64Value(target.Port))]
+}
+
+// targetStateRank orders target states from the most to the least alarming,
+// a task registered to several target groups shows its worst state.
+var targetStateRank = map[string]int{
+	elbv2.TargetHealthStateEnumUnhealthy:   0,
+	elbv2.TargetHealthStateEnumUnavailable: 1,
+	elbv2.TargetHealthStateEnumDraining:    2,
+	elbv2.TargetHealthStateEnumInitial:     3,
+	elbv2.TargetHealthStateEnumUnused:      4,
+	elbv2.TargetHealthStateEnumHealthy:     5,
+}
+
+// TaskTargetHealth returns the target health state of every task by task
+// ARN. Tasks that are not a target of any of the health descriptions are
+// NotRegistered.
+func (m TargetMatcher) TaskTargetHealth(tasks []*ecs.Task, health []*elbv2.TargetHealthDescription) map[string]string {
+	states := make(map[string]string)
+	for _, h := range health {
+		task := m.Task(h.Target)
+		if task == nil {
+			continue
+		}
+		arn, state := aws.StringValue(task.TaskArn), aws.StringValue(h.TargetHealth.State)
+		if current, ok := states[arn]; !ok || targetStateRank[state] < targetStateRank[current] {
+			states[arn] = state
+		}
+	}
+	for _, task := range tasks {
+		if _, ok := states[aws.StringValue(task.TaskArn)]; !ok {
+			states[aws.StringValue(task.TaskArn)] = NotRegistered
+		}
+	}
+	return states
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// awsvpcTask is a Fargate or awsvpc mode task with an ENI at ip.
+func awsvpcTask(arn, ip string) *ecs.Task {
+	return &ecs.Task{
+		TaskArn: aws.String(arn),
+		Attachments: []*ecs.Attachment{{
+			Type: aws.String("ElasticNetworkInterface"),
+			Details: []*ecs.KeyValuePair{
+				{Name: aws.String("subnetId"), Value: aws.String("subnet-1")},
+				{Name: aws.String("privateIPv4Address"), Value: aws.String(ip)},
+			},
+		}},
+	}
+}
+
+// bridgeTask is a bridge or host mode task on a container instance, its
+// containers bound to hostPorts.
+func bridgeTask(arn, instanceArn string, hostPorts ...int64) *ecs.Task {
+	task := &ecs.Task{TaskArn: aws.String(arn), ContainerInstanceArn: aws.String(instanceArn)}
+	for _, port := range hostPorts {
+		task.Containers = append(task.Containers, &ecs.Container{
+			NetworkBindings: []*ecs.NetworkBinding{{ContainerPort: aws.Int64(80), HostPort: aws.Int64(port)}},
+		})
+	}
+	return task
+}
+
+func target(id string, port int64, state string) *elbv2.TargetHealthDescription {
+	return &elbv2.TargetHealthDescription{
+		Target:       &elbv2.TargetDescription{Id: aws.String(id), Port: aws.Int64(port)},
+		TargetHealth: &elbv2.TargetHealth{State: aws.String(state)},
+	}
+}
+
+func TestTargetMatcher(t *testing.T) {
+	instanceIDs := map[string]string{
+		"arn:container-instance/1": "i-0aaa",
+		"arn:container-instance/2": "i-0bbb",
+	}
+	tasks := []*ecs.Task{
+		awsvpcTask("task/fargate", "10.0.1.15"),
+		bridgeTask("task/bridge-1", "arn:container-instance/1", 32768),
+		bridgeTask("task/bridge-2", "arn:container-instance/1", 32769, 32770),
+		bridgeTask("task/host", "arn:container-instance/2", 80),
+		// its instance is unknown, e.g. DescribeContainerInstances failed
+		bridgeTask("task/unknown-instance", "arn:container-instance/3", 32768),
+	}
+	matcher := NewTargetMatcher(tasks, instanceIDs)
+
+	for _, tc := range []struct {
+		name string
+		id   string
+		port int64
+		want string
+	}{
+		{name: "awsvpc by ENI IP", id: "10.0.1.15", port: 8080, want: "task/fargate"},
+		{name: "awsvpc on any port", id: "10.0.1.15", port: 9090, want: "task/fargate"},
+		{name: "bridge by instance and host port", id: "i-0aaa", port: 32768, want: "task/bridge-1"},
+		{name: "bridge second container", id: "i-0aaa", port: 32770, want: "task/bridge-2"},
+		{name: "host mode", id: "i-0bbb", port: 80, want: "task/host"},
+		{name: "instance with another port", id: "i-0aaa", port: 32771},
+		{name: "port on another instance", id: "i-0bbb", port: 32768},
+		{name: "unknown IP", id: "10.0.1.16", port: 8080},
+		{name: "unknown instance", id: "i-0ccc", port: 32768},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ""
+			if task := matcher.Task(&elbv2.TargetDescription{Id: aws.String(tc.id), Port: aws.Int64(tc.port)}); task != nil {
+				got = aws.StringValue(task.TaskArn)
+			}
+			if got != tc.want {
+				t.Errorf("Task(%s:%d) = %q, want %q", tc.id, tc.port, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTaskTargetHealth(t *testing.T) {
+	instanceIDs := map[string]string{"arn:container-instance/1": "i-0aaa"}
+	for _, tc := range []struct {
+		name   string
+		tasks  []*ecs.Task
+		health []*elbv2.TargetHealthDescription
+		want   map[string]string
+	}{
+		{
+			name:   "awsvpc",
+			tasks:  []*ecs.Task{awsvpcTask("task/a", "10.0.1.15"), awsvpcTask("task/b", "10.0.1.16")},
+			health: []*elbv2.TargetHealthDescription{target("10.0.1.15", 80, "healthy"), target("10.0.1.16", 80, "initial")},
+			want:   map[string]string{"task/a": "healthy", "task/b": "initial"},
+		},
+		{
+			name:   "bridge",
+			tasks:  []*ecs.Task{bridgeTask("task/a", "arn:container-instance/1", 32768), bridgeTask("task/b", "arn:container-instance/1", 32769)},
+			health: []*elbv2.TargetHealthDescription{target("i-0aaa", 32768, "healthy"), target("i-0aaa", 32769, "draining")},
+			want:   map[string]string{"task/a": "healthy", "task/b": "draining"},
+		},
+		{
+			name:   "no matching target",
+			tasks:  []*ecs.Task{awsvpcTask("task/a", "10.0.1.15"), bridgeTask("task/b", "arn:container-instance/1", 32768)},
+			health: []*elbv2.TargetHealthDescription{target("10.0.9.9", 80, "healthy"), target("i-0aaa", 32769, "healthy")},
+			want:   map[string]string{"task/a": NotRegistered, "task/b": NotRegistered},
+		},
+		{
+			name:  "no target groups",
+			tasks: []*ecs.Task{awsvpcTask("task/a", "10.0.1.15")},
+			want:  map[string]string{"task/a": NotRegistered},
+		},
+		{
+			name:  "worst state of several target groups",
+			tasks: []*ecs.Task{awsvpcTask("task/a", "10.0.1.15"), awsvpcTask("task/b", "10.0.1.16")},
+			health: []*elbv2.TargetHealthDescription{
+				target("10.0.1.15", 80, "healthy"), target("10.0.1.15", 8080, "unhealthy"),
+				target("10.0.1.16", 80, "draining"), target("10.0.1.16", 8080, "initial"),
+			},
+			want: map[string]string{"task/a": "unhealthy", "task/b": "draining"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewTargetMatcher(tc.tasks, instanceIDs).TaskTargetHealth(tc.tasks, tc.health)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("TaskTargetHealth = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}